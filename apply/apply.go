@@ -5,37 +5,48 @@ import (
 )
 
 type applierCommon interface {
-	MavenRun(args ...string)
+	MavenRun(args ...string) error
 
-	MavenRunWithProfiles(profiles []string, args ...string)
+	MavenRunWithProfiles(profiles []string, args ...string) error
 
-	MavenVersionsSet(newVersion string)
+	ChangelogRelease(path string, revision string) error
+}
 
-	MavenScmCheckinRelease(moduleName string, moduleVersion string)
+type Applier interface {
+	applierCommon
 
-	MavenScmCheckinDevelopment(moduleName string)
+	MavenExec(commands []string) error
 
-	MavenScmTag(moduleTag string)
+	// ModuleVersion returns the current version of the module. For dry-runs, this includes the version changes that
+	// would have been made.
+	ModuleVersion(module project.Module) string
 
-	MavenScmBranch(moduleBranch string)
+	MavenSetVersion(module project.Module, newVersion string) error
 
-	MavenDependencyVersionSet(module project.Module, groupId string, artifactId string, newVersion string)
+	MavenSetDependencyVersion(module project.Module, groupId string, artifactId string, newVersion string) error
 
-	ChangelogRelease(path string, revision string) error
-}
+	MavenSetParent(module project.Module, parentVersion string) error
 
-type Applier interface {
-	applierCommon
+	MavenSetProperty(module project.Module, name string, value string) error
+
+	NpmVersionSet(module project.Module, newVersion string) error
+
+	NpmVersionCommit(module project.Module, newVersion string) error
+
+	GitCommitRelease(module project.Module, version string) error
+
+	GitCommitDevelopment(module project.Module) error
 
-	MavenExec(commands []string)
+	GitTag(module project.Module, tag string) error
 
-	MavenSetParent(module project.Module, parentVersion string)
+	GitBranch(module project.Module, branch string) error
 
-	MavenSetProperty(module project.Module, name string, value string)
+	GitPush(module project.Module, refs []string) error
 
-	NpmVersionSet(module project.Module, newVersion string)
+	// GitCheckout checks out the given branch or tag in the module repository.
+	GitCheckout(module project.Module, ref string) error
 
-	NpmVersionCommit(module project.Module, newVersion string)
+	GitHubRelease(module project.Module, release GitHubRelease) error
 }
 
 // Ensures that the server module gets handled first.
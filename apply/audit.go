@@ -0,0 +1,234 @@
+package apply
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/samber/lo"
+)
+
+const auditLogFileSuffix = ".audit.jsonl"
+
+// The maximum number of output bytes per audit log entry. Longer output gets truncated at the beginning because the
+// end of the output usually contains the relevant error messages.
+const auditOutputLimit = 8192
+
+type AuditAction string
+
+const (
+	AuditMaven         AuditAction = "maven"
+	AuditGit           AuditAction = "git"
+	AuditPom           AuditAction = "pom"
+	AuditPackageJson   AuditAction = "package-json"
+	AuditChangelog     AuditAction = "changelog"
+	AuditPush          AuditAction = "push"
+	AuditGitHubRelease AuditAction = "github-release"
+)
+
+// AuditEntry is a single line in the audit log.
+type AuditEntry struct {
+	// The ID of the run that created the entry. All entries of one command execution have the same run ID.
+	Run         string      `json:"run"`
+	Command     string      `json:"command"`
+	Action      AuditAction `json:"action"`
+	Module      string      `json:"module,omitempty"`
+	Directory   string      `json:"directory"`
+	Description string      `json:"description"`
+	Args        []string    `json:"args,omitempty"`
+	// The files that have been modified by the action.
+	Files      []string  `json:"files,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// The exit status of executed commands. For other actions, 0 means success and 1 means failure.
+	ExitStatus      int    `json:"exit_status"`
+	Error           string `json:"error,omitempty"`
+	Output          string `json:"output,omitempty"`
+	OutputTruncated bool   `json:"output_truncated,omitempty"`
+}
+
+func (entry AuditEntry) Failed() bool {
+	return entry.ExitStatus != 0
+}
+
+// AuditLog appends JSON lines entries for every executed release action to a file. All methods can be called on a
+// nil AuditLog, in which case nothing gets recorded.
+type AuditLog struct {
+	mu       sync.Mutex
+	filename string
+	run      string
+	command  string
+	file     *os.File
+	summary  AuditSummary
+}
+
+type AuditSummary struct {
+	Entries int
+	Failed  int
+	Actions map[AuditAction]int
+}
+
+// AuditLogFilename returns the audit log file name for the given manifest. The audit log is stored next to the
+// manifest.
+func AuditLogFilename(manifestFile string) string {
+	return strings.TrimSuffix(manifestFile, filepath.Ext(manifestFile)) + auditLogFileSuffix
+}
+
+// OpenAuditLog opens the given audit log file for appending. The command is recorded in every entry.
+func OpenAuditLog(filename string, command string) (*AuditLog, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open audit log %s: %w", filename, err)
+	}
+
+	return &AuditLog{
+		filename: filename,
+		run:      time.Now().UTC().Format("20060102T150405.000Z"),
+		command:  command,
+		file:     file,
+		summary:  AuditSummary{Actions: make(map[AuditAction]int)},
+	}, nil
+}
+
+func (l *AuditLog) Filename() string {
+	if l == nil {
+		return ""
+	}
+	return l.filename
+}
+
+func (l *AuditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Record writes the given entry to the audit log. The output of the entry gets truncated if needed.
+func (l *AuditLog) Record(entry AuditEntry) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry.Run = l.run
+	entry.Command = l.command
+	if len(entry.Output) > auditOutputLimit {
+		entry.Output, entry.OutputTruncated = utils.TailString(entry.Output, auditOutputLimit), true
+	}
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("couldn't serialize audit log entry: %w", err)
+	}
+	if _, err := l.file.Write(append(buf, '\n')); err != nil {
+		return fmt.Errorf("couldn't write audit log %s: %w", l.filename, err)
+	}
+
+	l.summary.Entries++
+	l.summary.Actions[entry.Action]++
+	if entry.Failed() {
+		l.summary.Failed++
+	}
+
+	return nil
+}
+
+// Run executes the given action and records it with its timestamps and result. The action can set the output and
+// modified files on the entry.
+func (l *AuditLog) Run(entry AuditEntry, action func(entry *AuditEntry) error) error {
+	entry.StartedAt = time.Now()
+	err := action(&entry)
+	entry.FinishedAt = time.Now()
+
+	if err != nil {
+		entry.Error = err.Error()
+		entry.ExitStatus = exitStatus(err)
+	}
+
+	return errors.Join(err, l.Record(entry))
+}
+
+// Summary returns the number of recorded entries of this run.
+func (l *AuditLog) Summary() AuditSummary {
+	if l == nil {
+		return AuditSummary{Actions: make(map[AuditAction]int)}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	summary := l.summary
+	summary.Actions = make(map[AuditAction]int)
+	for action, count := range l.summary.Actions {
+		summary.Actions[action] = count
+	}
+	return summary
+}
+
+func (s AuditSummary) String() string {
+	actions := lo.Keys(s.Actions)
+	slices.Sort(actions)
+
+	counts := lo.Map(actions, func(action AuditAction, _ int) string {
+		return fmt.Sprintf("%s=%d", action, s.Actions[action])
+	})
+
+	return fmt.Sprintf("%d actions (%s), %d failed", s.Entries, strings.Join(counts, ", "), s.Failed)
+}
+
+func exitStatus(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// A writer that only keeps the last bytes up to the given limit. Safe for concurrent writes.
+type tailBuffer struct {
+	mu        sync.Mutex
+	limit     int
+	buf       []byte
+	truncated bool
+}
+
+func newTailBuffer(limit int) *tailBuffer {
+	return &tailBuffer{limit: limit}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if overflow := len(b.buf) - b.limit; overflow > 0 {
+		b.buf = slices.Clone(b.buf[overflow:])
+		b.truncated = true
+	}
+
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// The buffer might start in the middle of a rune after truncation
+	return utils.TailString(string(b.buf), b.limit)
+}
+
+func (b *tailBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.truncated
+}
@@ -0,0 +1,153 @@
+package apply
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAuditLog(t *testing.T, filename string) []AuditEntry {
+	t.Helper()
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+
+	return entries
+}
+
+func TestAuditLogFilename(t *testing.T) {
+	assert.Equal(t, "manifests/release-6.2.0.audit.jsonl", AuditLogFilename("manifests/release-6.2.0.json"))
+}
+
+func TestAuditLogRun(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "release.audit.jsonl")
+	auditLog, err := OpenAuditLog(filename, "apply-manifest")
+	require.NoError(t, err)
+
+	err = auditLog.Run(AuditEntry{Action: AuditMaven, Directory: "/tmp", Args: []string{"mvn", "package"}}, func(entry *AuditEntry) error {
+		entry.Output = strings.Repeat("x", auditOutputLimit) + "BUILD SUCCESS"
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = auditLog.Run(AuditEntry{Action: AuditGit, Module: "graylog-server", Directory: "/tmp"}, func(entry *AuditEntry) error {
+		return git.Exec("-C", t.TempDir(), "rev-parse", "--verify", "does-not-exist")
+	})
+	require.Error(t, err)
+
+	require.NoError(t, auditLog.Close())
+
+	entries := readAuditLog(t, filename)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "apply-manifest", entries[0].Command)
+	assert.NotEmpty(t, entries[0].Run)
+	assert.Equal(t, entries[0].Run, entries[1].Run)
+	assert.Equal(t, 0, entries[0].ExitStatus)
+	assert.True(t, entries[0].OutputTruncated)
+	assert.Len(t, entries[0].Output, auditOutputLimit)
+	assert.True(t, strings.HasSuffix(entries[0].Output, "BUILD SUCCESS"))
+	assert.False(t, entries[0].StartedAt.IsZero())
+	assert.False(t, entries[0].FinishedAt.Before(entries[0].StartedAt))
+
+	assert.Equal(t, 128, entries[1].ExitStatus)
+	assert.Contains(t, entries[1].Error, "rev-parse")
+
+	summary := auditLog.Summary()
+	assert.Equal(t, 2, summary.Entries)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, "2 actions (git=1, maven=1), 1 failed", summary.String())
+}
+
+func TestAuditLogNil(t *testing.T) {
+	var auditLog *AuditLog
+
+	called := false
+	err := auditLog.Run(AuditEntry{Action: AuditPom}, func(entry *AuditEntry) error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Equal(t, 0, auditLog.Summary().Entries)
+	assert.NoError(t, auditLog.Close())
+}
+
+func TestExecuteApplierAuditLog(t *testing.T) {
+	path, _ := newTestRepo(t, "graylog2-server")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pom.xml"), []byte(`<project>
+    <modelVersion>4.0.0</modelVersion>
+    <groupId>org.graylog</groupId>
+    <artifactId>graylog-server</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+</project>
+`), 0644))
+	runGit(t, path, "add", "pom.xml")
+	runGit(t, path, "commit", "--quiet", "--message", "initial")
+
+	filename := filepath.Join(t.TempDir(), "release.audit.jsonl")
+	auditLog, err := OpenAuditLog(filename, "apply-manifest")
+	require.NoError(t, err)
+
+	module := project.Module{Name: "graylog-server", Path: path, Revision: "6.2.0"}
+	applier := NewExecuteApplier(nil, WithAuditLog(auditLog))
+
+	require.NoError(t, applier.MavenSetVersion(module, "6.2.0"))
+	require.NoError(t, applier.GitCommitRelease(module, "6.2.0"))
+	require.NoError(t, applier.GitTag(module, "6.2.0"))
+	require.NoError(t, auditLog.Close())
+
+	entries := readAuditLog(t, filename)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, AuditPom, entries[0].Action)
+	assert.Equal(t, "set version 6.2.0", entries[0].Description)
+	assert.Equal(t, []string{filepath.Join(path, "pom.xml")}, entries[0].Files)
+	assert.Equal(t, path, entries[0].Directory)
+
+	assert.Equal(t, []string{"git", "add", "--update", "--", pomPathspec}, entries[1].Args)
+	assert.Equal(t, []string{"git", "commit", "--message", "[graylog-server] prepare release 6.2.0"}, entries[2].Args)
+	assert.Equal(t, "git tag", entries[3].Description)
+	for _, entry := range entries {
+		assert.Equal(t, "graylog-server", entry.Module)
+		assert.Equal(t, 0, entry.ExitStatus)
+	}
+}
+
+func TestExecuteApplierAuditLogFailure(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "release.audit.jsonl")
+	auditLog, err := OpenAuditLog(filename, "apply-manifest")
+	require.NoError(t, err)
+
+	module := project.Module{Name: "graylog-server", Path: t.TempDir(), Revision: "6.2.0"}
+	applier := NewExecuteApplier(nil, WithAuditLog(auditLog))
+
+	// Failures are returned instead of exiting so the caller can still print the audit summary
+	assert.ErrorContains(t, applier.MavenSetVersion(module, "6.2.0"), "couldn't set version in "+module.Path)
+	assert.ErrorContains(t, applier.MavenExec([]string{"exit 3"}), "command failed")
+	require.NoError(t, auditLog.Close())
+
+	assert.Equal(t, 2, auditLog.Summary().Failed)
+	entries := readAuditLog(t, filename)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 1, entries[0].ExitStatus)
+	assert.Equal(t, 3, entries[1].ExitStatus)
+}
@@ -0,0 +1,50 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// BranchCut contains the versions of a module when a new stable branch gets created from its development branch.
+type BranchCut struct {
+	// The stable branch. (e.g., "6.2")
+	Branch string `json:"branch"`
+	// The development version of the source branch. The new branch keeps this version. (e.g., "6.2.0-SNAPSHOT")
+	Current string `json:"current"`
+	// The next minor development version of the source branch. (e.g., "6.3.0-SNAPSHOT")
+	Next string `json:"next"`
+	// The changelog folder that receives the unreleased changelogs of the source branch. (e.g., "6.2.0")
+	ChangelogVersion string `json:"changelog_version"`
+}
+
+// NewBranchCut computes the branch name and versions from the given current "-SNAPSHOT" version.
+func NewBranchCut(current string) (BranchCut, error) {
+	if !strings.HasSuffix(current, SnapshotSuffix) {
+		return BranchCut{}, fmt.Errorf("current version %q is not a %s version", current, SnapshotSuffix)
+	}
+
+	currentVersion, err := version.NewSemver(strings.TrimSuffix(current, SnapshotSuffix))
+	if err != nil {
+		return BranchCut{}, fmt.Errorf("invalid current version %q: %w", current, err)
+	}
+
+	segments := currentVersion.Segments()
+	major, minor, patch := segments[0], segments[1], segments[2]
+
+	return BranchCut{
+		Branch:           fmt.Sprintf("%d.%d", major, minor),
+		Current:          current,
+		Next:             fmt.Sprintf("%d.%d.0%s", major, minor+1, SnapshotSuffix),
+		ChangelogVersion: fmt.Sprintf("%d.%d.%d", major, minor, patch),
+	}, nil
+}
+
+// BranchCutRefs returns the refs that a branch cut updates. Both branches get pushed together.
+func BranchCutRefs(sourceBranch string, newBranch string) []string {
+	return []string{
+		"refs/heads/" + sourceBranch,
+		"refs/heads/" + newBranch,
+	}
+}
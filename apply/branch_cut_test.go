@@ -0,0 +1,27 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBranchCut(t *testing.T) {
+	cut, err := NewBranchCut("6.2.0-SNAPSHOT")
+	require.NoError(t, err)
+	assert.Equal(t, BranchCut{Branch: "6.2", Current: "6.2.0-SNAPSHOT", Next: "6.3.0-SNAPSHOT", ChangelogVersion: "6.2.0"}, cut)
+
+	cut, err = NewBranchCut("7.0.0-beta.1-SNAPSHOT")
+	require.NoError(t, err)
+	assert.Equal(t, BranchCut{Branch: "7.0", Current: "7.0.0-beta.1-SNAPSHOT", Next: "7.1.0-SNAPSHOT", ChangelogVersion: "7.0.0"}, cut)
+
+	_, err = NewBranchCut("6.2.0")
+	assert.ErrorContains(t, err, "is not a -SNAPSHOT version")
+	_, err = NewBranchCut("six-SNAPSHOT")
+	assert.ErrorContains(t, err, "invalid current version")
+}
+
+func TestBranchCutRefs(t *testing.T) {
+	assert.Equal(t, []string{"refs/heads/main", "refs/heads/6.2"}, BranchCutRefs("main", "6.2"))
+}
@@ -0,0 +1,120 @@
+package apply
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/samber/lo"
+)
+
+type BumpStrategy string
+
+const (
+	BumpPatch      BumpStrategy = "patch"
+	BumpMinor      BumpStrategy = "minor"
+	BumpMajor      BumpStrategy = "major"
+	BumpPrerelease BumpStrategy = "prerelease"
+)
+
+var BumpStrategies = []BumpStrategy{BumpPatch, BumpMinor, BumpMajor, BumpPrerelease}
+
+const (
+	SnapshotSuffix      = "-SNAPSHOT"
+	DefaultPrereleaseID = "rc"
+)
+
+var prereleaseIDPattern = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+func ParseBumpStrategy(value string) (BumpStrategy, error) {
+	strategy := BumpStrategy(value)
+	if !lo.Contains(BumpStrategies, strategy) {
+		return "", fmt.Errorf("invalid bump strategy %q: expected one of %v", value, BumpStrategies)
+	}
+	return strategy, nil
+}
+
+type BumpOptions struct {
+	// The pre-release identifier for the "prerelease" strategy. (e.g., "rc" or "beta")
+	PrereleaseID string
+	// The existing tags of the module. Used to compute the next pre-release number.
+	Tags []string
+}
+
+type BumpedVersion struct {
+	Current string `json:"current"`
+	Release string `json:"release"`
+	Next    string `json:"next"`
+}
+
+// BumpVersion computes the release and next development version from the given current "-SNAPSHOT" version.
+//
+// The current development version already points to the next release, so "patch" releases it as is. "minor" and
+// "major" only increase the version if it isn't already a minor or major version. (6.1.0-SNAPSHOT releases
+// 6.1.0 for "minor" but 7.0.0 for "major") The next development version is the next patch version.
+//
+// "prerelease" releases the next "<version>-<id>.<n>" pre-release that doesn't exist in the given tags yet. The next
+// development version stays at the current version. Current versions with a pre-release (e.g., 6.2.0-rc.1-SNAPSHOT) aren't
+// supported.
+func BumpVersion(current string, strategy BumpStrategy, opts BumpOptions) (BumpedVersion, error) {
+	if !strings.HasSuffix(current, SnapshotSuffix) {
+		return BumpedVersion{}, fmt.Errorf("current version %q is not a %s version", current, SnapshotSuffix)
+	}
+
+	currentVersion, err := version.NewSemver(strings.TrimSuffix(current, SnapshotSuffix))
+	if err != nil {
+		return BumpedVersion{}, fmt.Errorf("invalid current version %q: %w", current, err)
+	}
+	// The strategies only work with the version core, so a pre-release would get dropped silently
+	if currentVersion.Prerelease() != "" || currentVersion.Metadata() != "" {
+		return BumpedVersion{}, fmt.Errorf("invalid current version %q: pre-release and build metadata versions aren't supported", current)
+	}
+
+	segments := currentVersion.Segments()
+	major, minor, patch := segments[0], segments[1], segments[2]
+
+	switch strategy {
+	case BumpPatch:
+	case BumpMinor:
+		if patch != 0 {
+			minor, patch = minor+1, 0
+		}
+	case BumpMajor:
+		if minor != 0 || patch != 0 {
+			major, minor, patch = major+1, 0, 0
+		}
+	case BumpPrerelease:
+		return bumpPrerelease(current, fmt.Sprintf("%d.%d.%d", major, minor, patch), opts)
+	default:
+		return BumpedVersion{}, fmt.Errorf("invalid bump strategy %q: expected one of %v", strategy, BumpStrategies)
+	}
+
+	return BumpedVersion{
+		Current: current,
+		Release: fmt.Sprintf("%d.%d.%d", major, minor, patch),
+		Next:    fmt.Sprintf("%d.%d.%d%s", major, minor, patch+1, SnapshotSuffix),
+	}, nil
+}
+
+func bumpPrerelease(current string, core string, opts BumpOptions) (BumpedVersion, error) {
+	id, _ := lo.Coalesce(opts.PrereleaseID, DefaultPrereleaseID)
+	if !prereleaseIDPattern.MatchString(id) {
+		return BumpedVersion{}, fmt.Errorf("invalid pre-release identifier %q", id)
+	}
+
+	prefix := core + "-" + id + "."
+	number := 0
+	for _, tag := range opts.Tags {
+		if n, err := strconv.Atoi(strings.TrimPrefix(tag, prefix)); strings.HasPrefix(tag, prefix) && err == nil {
+			number = max(number, n)
+		}
+	}
+
+	return BumpedVersion{
+		Current: current,
+		Release: fmt.Sprintf("%s%d", prefix, number+1),
+		Next:    core + SnapshotSuffix,
+	}, nil
+}
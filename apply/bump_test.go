@@ -0,0 +1,69 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		current  string
+		strategy BumpStrategy
+		release  string
+		next     string
+	}{
+		{"6.1.3-SNAPSHOT", BumpPatch, "6.1.3", "6.1.4-SNAPSHOT"},
+		{"6.1.0-SNAPSHOT", BumpPatch, "6.1.0", "6.1.1-SNAPSHOT"},
+		{"6.1.0-SNAPSHOT", BumpMinor, "6.1.0", "6.1.1-SNAPSHOT"},
+		{"6.1.3-SNAPSHOT", BumpMinor, "6.2.0", "6.2.1-SNAPSHOT"},
+		{"7.0.0-SNAPSHOT", BumpMajor, "7.0.0", "7.0.1-SNAPSHOT"},
+		{"6.1.0-SNAPSHOT", BumpMajor, "7.0.0", "7.0.1-SNAPSHOT"},
+		{"6.2.0-SNAPSHOT", BumpPrerelease, "6.2.0-rc.1", "6.2.0-SNAPSHOT"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.current+"/"+string(test.strategy), func(t *testing.T) {
+			bumped, err := BumpVersion(test.current, test.strategy, BumpOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, BumpedVersion{Current: test.current, Release: test.release, Next: test.next}, bumped)
+			assert.NoError(t, checkVersionOrder(bumped.Current, bumped.Release, bumped.Next))
+		})
+	}
+}
+
+func TestBumpVersionPrerelease(t *testing.T) {
+	tags := []string{"6.2.0-rc.1", "6.2.0-rc.2", "6.2.0-beta.4", "6.1.0-rc.7", "6.2.0-rc.x"}
+
+	bumped, err := BumpVersion("6.2.0-SNAPSHOT", BumpPrerelease, BumpOptions{Tags: tags})
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0-rc.3", bumped.Release)
+	assert.Equal(t, "6.2.0-SNAPSHOT", bumped.Next)
+
+	bumped, err = BumpVersion("6.2.0-SNAPSHOT", BumpPrerelease, BumpOptions{PrereleaseID: "beta", Tags: tags})
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0-beta.5", bumped.Release)
+
+	_, err = BumpVersion("6.2.0-SNAPSHOT", BumpPrerelease, BumpOptions{PrereleaseID: "rc.1"})
+	assert.ErrorContains(t, err, `invalid pre-release identifier "rc.1"`)
+}
+
+func TestBumpVersionErrors(t *testing.T) {
+	_, err := BumpVersion("6.2.0", BumpPatch, BumpOptions{})
+	assert.ErrorContains(t, err, `current version "6.2.0" is not a -SNAPSHOT version`)
+
+	_, err = BumpVersion("foo-SNAPSHOT", BumpPatch, BumpOptions{})
+	assert.ErrorContains(t, err, `invalid current version "foo-SNAPSHOT"`)
+
+	for _, strategy := range BumpStrategies {
+		_, err = BumpVersion("6.2.0-rc.1-SNAPSHOT", strategy, BumpOptions{})
+		assert.ErrorContains(t, err, `invalid current version "6.2.0-rc.1-SNAPSHOT": pre-release and build metadata versions aren't supported`)
+	}
+
+	_, err = BumpVersion("6.2.0-SNAPSHOT", "huge", BumpOptions{})
+	assert.ErrorContains(t, err, `invalid bump strategy "huge"`)
+
+	_, err = ParseBumpStrategy("huge")
+	assert.ErrorContains(t, err, `invalid bump strategy "huge"`)
+}
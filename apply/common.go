@@ -1,7 +1,6 @@
 package apply
 
 import (
-	"github.com/Graylog2/graylog-project-cli/project"
 	"github.com/Graylog2/graylog-project-cli/utils"
 	"os"
 	"strings"
@@ -12,7 +11,7 @@ type CommonMaven struct {
 	Profiles []string
 }
 
-func (common CommonMaven) MavenRunWithProfiles(profiles []string, args ...string) {
+func (common CommonMaven) MavenRunWithProfiles(profiles []string, args ...string) error {
 	commands := []string{utils.MavenBin() + " --show-version --batch-mode --fail-fast"}
 
 	// Force maven color output when executed on Jenkins to prettify the logs
@@ -27,33 +26,9 @@ func (common CommonMaven) MavenRunWithProfiles(profiles []string, args ...string
 
 	commands = append(commands, strings.Join(args, " "))
 
-	common.Applier.MavenExec(commands)
+	return common.Applier.MavenExec(commands)
 }
 
-func (common CommonMaven) MavenRun(args ...string) {
-	common.MavenRunWithProfiles([]string{}, args...)
-}
-
-func (common CommonMaven) MavenVersionsSet(newVersion string) {
-	common.MavenRunWithProfiles(common.Profiles, "-DnewVersion="+newVersion, "versions:set", "versions:commit")
-}
-
-func (common CommonMaven) MavenScmCheckinRelease(moduleName string, moduleVersion string) {
-	common.MavenRunWithProfiles(common.Profiles, "-Dmessage=\"["+moduleName+"] prepare release "+moduleVersion+"\"", "-Dincludes=\"**/pom.xml\"", "-Dexcludes=\"**/target/**/pom.xml\"", "scm:checkin")
-}
-
-func (common CommonMaven) MavenScmCheckinDevelopment(moduleName string) {
-	common.MavenRunWithProfiles(common.Profiles, "-Dmessage=\"["+moduleName+"] prepare for next development iteration\"", "-Dincludes=\"**/pom.xml\"", "-Dexcludes=\"**/target/**/pom.xml\"", "scm:checkin")
-}
-
-func (common CommonMaven) MavenScmTag(moduleTag string) {
-	common.MavenRunWithProfiles(common.Profiles, "-Dtag="+moduleTag, "scm:tag")
-}
-
-func (common CommonMaven) MavenScmBranch(moduleBranch string) {
-	common.MavenRunWithProfiles(common.Profiles, "-Dbranch="+moduleBranch, "scm:branch")
-}
-
-func (common CommonMaven) MavenDependencyVersionSet(module project.Module, groupId string, artifactId string, newVersion string) {
-	common.MavenRunWithProfiles(common.Profiles, "versions:use-dep-version", "-DdepVersion="+newVersion, "-DforceVersion", "-Dincludes="+groupId+":"+artifactId)
+func (common CommonMaven) MavenRun(args ...string) error {
+	return common.MavenRunWithProfiles([]string{}, args...)
 }
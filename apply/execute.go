@@ -2,7 +2,15 @@ package apply
 
 import (
 	"fmt"
+	"io"
+	"os"
+	e "os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
 	"github.com/Graylog2/graylog-project-cli/changelog"
+	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/git"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/pom"
@@ -10,21 +18,21 @@ import (
 	"github.com/Graylog2/graylog-project-cli/project"
 	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/fatih/color"
-	"os"
-	e "os/exec"
-	"path/filepath"
-	"regexp"
-	"strings"
 )
 
 // Used with pom.SetParentIfMatches() to decide if the parent should be updated
 var parentMatchFunc = func(module project.Module, pom pomparse.MavenPom) bool {
 	// The parent should only be updated if it is a graylog plugin parent
-	return pom.ParentGroupId == "org.graylog.plugins" && (pom.ParentArtifactId == "graylog-plugin-parent" || pom.ParentArtifactId == "graylog-plugin-web-parent")
+	return isGraylogPluginParent(pom.ParentGroupId, pom.ParentArtifactId)
+}
+
+func isGraylogPluginParent(groupId string, artifactId string) bool {
+	return groupId == "org.graylog.plugins" && (artifactId == "graylog-plugin-parent" || artifactId == "graylog-plugin-web-parent")
 }
 
-func NewExecuteApplier(profiles []string) Applier {
-	applier := executeApplier{}
+func NewExecuteApplier(profiles []string, options ...applierOption) Applier {
+	o := newApplierOptions(options)
+	applier := executeApplier{git: o.git, github: o.github, audit: o.audit}
 	applier.CommonMaven = CommonMaven{Profiles: profiles, Applier: applier}
 
 	return applier
@@ -36,102 +44,227 @@ var _ Applier = (*executeApplier)(nil)
 // An apply.Applier implementation that actually executes the commands.
 type executeApplier struct {
 	CommonMaven
+	git    GitConfig
+	github *gh.Client
+	audit  *AuditLog
+}
+
+func (execute executeApplier) ModuleVersion(module project.Module) string {
+	return module.Version()
+}
+
+func (execute executeApplier) MavenSetVersion(module project.Module, newVersion string) error {
+	fmt.Println("set version: " + newVersion)
+	err := execute.audit.Run(moduleAuditEntry(AuditPom, module, "set version "+newVersion), func(entry *AuditEntry) error {
+		var err error
+		entry.Files, err = pom.SetVersion(module.Path, newVersion)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set version in %s: %w", module.Path, err)
+	}
+	return nil
 }
 
-func (execute executeApplier) MavenSetParent(module project.Module, parentVersion string) {
-	if module.HasParent() {
-		fmt.Println("set parent version: " + parentVersion)
-		pom.SetParentIfMatches(module, module.ParentGroupId(), module.ParentArtifactId(), parentVersion, module.ParentRelativePath(), parentMatchFunc)
+func (execute executeApplier) MavenSetDependencyVersion(module project.Module, groupId string, artifactId string, newVersion string) error {
+	fmt.Println("set dependency version: " + groupId + ":" + artifactId + ":" + newVersion)
+	description := "set dependency version " + groupId + ":" + artifactId + ":" + newVersion
+	err := execute.audit.Run(moduleAuditEntry(AuditPom, module, description), func(entry *AuditEntry) error {
+		var err error
+		entry.Files, err = pom.SetDependencyVersion(module.Path, groupId, artifactId, newVersion)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set dependency version in %s: %w", module.Path, err)
 	}
+	return nil
 }
 
-func (execute executeApplier) MavenSetProperty(module project.Module, name string, value string) {
+func (execute executeApplier) MavenSetParent(module project.Module, parentVersion string) error {
+	if !module.HasParent() {
+		return nil
+	}
+
+	fmt.Println("set parent version: " + parentVersion)
+	pomFile := filepath.Join(module.Path, "pom.xml")
+	pomContent, err := pomparse.ParsePomE(pomFile)
+	if err != nil {
+		return err
+	}
+	if !parentMatchFunc(module, *pomContent) {
+		logger.Debug("Skip setting parent in %s because it isn't a graylog plugin parent", pomFile)
+		return nil
+	}
+	err = execute.audit.Run(moduleAuditEntry(AuditPom, module, "set parent version "+parentVersion), func(entry *AuditEntry) error {
+		entry.Files = []string{pomFile}
+		return pom.SetParentIfMatchesE(module, module.ParentGroupId(), module.ParentArtifactId(), parentVersion, module.ParentRelativePath(), parentMatchFunc)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set parent version in %s: %w", module.Path, err)
+	}
+	return nil
+}
+
+func (execute executeApplier) MavenSetProperty(module project.Module, name string, value string) error {
 	fmt.Println("set property: <" + name + ">" + value + "</" + name + ">")
-	pom.SetProperty(module, name, value)
+	err := execute.audit.Run(moduleAuditEntry(AuditPom, module, "set property "+name+"="+value), func(entry *AuditEntry) error {
+		entry.Files = []string{filepath.Join(module.Path, "pom.xml")}
+		return pom.SetPropertyE(module, name, value)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set property in %s: %w", module.Path, err)
+	}
+	return nil
 }
 
-func (execute executeApplier) MavenExec(commands []string) {
+func (execute executeApplier) MavenExec(commands []string) error {
 	logger.ColorPrintln(color.FgMagenta, "[command output: %v]", strings.Join(commands, " "))
 
-	command := e.Command("sh", "-c", strings.Join(commands, " "))
+	entry := AuditEntry{
+		Action:      AuditMaven,
+		Directory:   utils.GetCwd(),
+		Description: "run maven",
+		Args:        commands,
+	}
+	err := execute.audit.Run(entry, func(entry *AuditEntry) error {
+		output := newTailBuffer(auditOutputLimit)
+		command := e.Command("sh", "-c", strings.Join(commands, " "))
 
-	command.Stdout = os.Stdout
-	command.Stderr = os.Stderr
+		command.Stdout = io.MultiWriter(os.Stdout, output)
+		command.Stderr = io.MultiWriter(os.Stderr, output)
 
-	if err := command.Run(); err != nil {
-		logger.Fatal("Command failed: %v", err)
+		err := command.Run()
+		entry.Output, entry.OutputTruncated = output.String(), output.Truncated()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("command failed: %w", err)
 	}
+	return nil
 }
 
-func (execute executeApplier) NpmVersionSet(module project.Module, version string) {
+func (execute executeApplier) NpmVersionSet(module project.Module, version string) error {
 	versionRe := regexp.MustCompile(`^\d+\.\d+\.\d+-?.*?$`)
-	filename := "package.json"
 
 	if version == "" {
-		logger.Fatal("Couldn't set version for %s because the given version is empty", module.Path)
+		return fmt.Errorf("couldn't set version for %s because the given version is empty", module.Path)
 	}
 	if !versionRe.MatchString(version) {
-		logger.Fatal("Couldn't set version for %s because the given version is invalid: \"%s\"", module.Path, version)
+		return fmt.Errorf("couldn't set version for %s because the given version is invalid: \"%s\"", module.Path, version)
+	}
+	if err := ValidatePackageJsonFiles(module); err != nil {
+		return fmt.Errorf("couldn't set version for %s: %w", module.Path, err)
 	}
 
-	if module.Server {
-		// The server module needs special treatment
-		// TODO: Move list of package.json files to the manifest and default to "package.json"
-		files := []string{
-			filepath.Join("graylog2-web-interface", filename),
-			filepath.Join("graylog2-web-interface/manifests", filename),
-			filepath.Join("graylog2-web-interface/packages/graylog-web-plugin", filename),
-		}
-		for _, file := range files {
-			fmt.Println("set version in " + filepath.Join(module.Path, file) + ": " + version)
+	for _, file := range npmVersionFiles(module) {
+		fmt.Println("set version in " + filepath.Join(module.Path, file) + ": " + version)
 
-			err := utils.SetPackageJsonVersion(file, version)
-			if err != nil {
-				logger.Fatal("Couldn't set version in file %s: %s", filepath.Join(module.Path, file), err)
-			}
+		err := execute.audit.Run(moduleAuditEntry(AuditPackageJson, module, "set version "+version), func(entry *AuditEntry) error {
+			entry.Files = []string{filepath.Join(module.Path, file)}
+			return utils.SetPackageJsonVersion(file, version)
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't set version in file %s: %w", filepath.Join(module.Path, file), err)
 		}
-		return
 	}
+	return nil
+}
+
+func (execute executeApplier) NpmVersionCommit(module project.Module, version string) error {
+	files := npmVersionFiles(module)
+	if len(files) == 0 {
+		return nil
+	}
+
+	if err := execute.gitExec(AuditGit, module, append([]string{"commit", "-m", npmCommitMessage(version)}, files...)...); err != nil {
+		return fmt.Errorf("couldn't commit package.json versions in %s: %w", module.Path, err)
+	}
+	return nil
+}
+
+func (execute executeApplier) ChangelogRelease(path string, revision string) error {
+	entry := AuditEntry{
+		Action:      AuditChangelog,
+		Directory:   path,
+		Description: "release changelog/unreleased as changelog/" + revision,
+	}
+	return execute.audit.Run(entry, func(entry *AuditEntry) error {
+		// Allow pre-release pattern here to handle the case where we need to rename the changelogs in the "main" branch
+		// to a pre-release version during an RC build where we create a new stable branch.
+		return changelog.ReleaseInPath(path, revision, changelog.SemverVersionPatternWithPreRelease)
+	})
+}
 
-	if !module.IsNpmModule() {
-		return
+func (execute executeApplier) GitCommitRelease(module project.Module, version string) error {
+	return execute.gitCommitPoms(module, releaseCommitMessage(module.Name, version))
+}
+
+func (execute executeApplier) GitCommitDevelopment(module project.Module) error {
+	return execute.gitCommitPoms(module, developmentCommitMessage(module.Name))
+}
+
+func (execute executeApplier) gitCommitPoms(module project.Module, message string) error {
+	if err := execute.gitExec(AuditGit, module, "add", "--update", "--", pomPathspec); err != nil {
+		return err
 	}
 
-	absFilename := filepath.Join(module.Path, filename)
+	// The command exits with 1 if there are staged changes
+	err := utils.InDirectoryE(module.Path, func() error {
+		_, err := git.GitValueE("diff", "--cached", "--quiet")
+		return err
+	})
+	if err == nil {
+		logger.Info("No pom.xml changes to commit in %s", module.Path)
+		return nil
+	}
 
-	fmt.Println("set version in " + absFilename + ": " + version)
+	return execute.gitExec(AuditGit, module, "commit", "--message", message)
+}
 
-	err := utils.SetPackageJsonVersion(filename, version)
+func (execute executeApplier) GitTag(module project.Module, tag string) error {
+	args, err := tagArgs(execute.git, module, tag)
 	if err != nil {
-		logger.Fatal("Couldn't set version in file %s: %s", absFilename, err)
+		return err
 	}
+	return execute.gitExec(AuditGit, module, args...)
 }
 
-func (execute executeApplier) NpmVersionCommit(module project.Module, version string) {
-	commitMsg := fmt.Sprintf("Bump package.json version to %s", version)
+func (execute executeApplier) GitBranch(module project.Module, branch string) error {
+	return execute.gitExec(AuditGit, module, "branch", branch)
+}
 
-	utils.InDirectory(module.Path, func() {
-		if module.Server {
-			// The server module needs special treatment
-			// TODO: Move list of package.json files to the manifest and default to "package.json"
-			file1 := "graylog2-web-interface/package.json"
-			file2 := "graylog2-web-interface/manifests/package.json"
-			file3 := "graylog2-web-interface/packages/graylog-web-plugin/package.json"
+func (execute executeApplier) GitPush(module project.Module, refs []string) error {
+	return execute.gitExec(AuditPush, module, pushArgs(execute.git, refs)...)
+}
 
-			git.Git("commit", "-m", commitMsg, file1, file2, file3)
-			return
-		}
+func (execute executeApplier) GitCheckout(module project.Module, ref string) error {
+	return execute.gitExec(AuditGit, module, "checkout", "--quiet", ref)
+}
 
-		if !module.IsNpmModule() {
-			return
-		}
+func (execute executeApplier) GitHubRelease(module project.Module, release GitHubRelease) error {
+	entry := moduleAuditEntry(AuditGitHubRelease, module, "create GitHub release "+release.Tag+" in "+release.Repository)
+	return execute.audit.Run(entry, func(entry *AuditEntry) error {
+		return createGitHubRelease(execute.github, release)
+	})
+}
+
+// Runs the given git command in the module path and records it in the audit log.
+func (execute executeApplier) gitExec(action AuditAction, module project.Module, args ...string) error {
+	entry := moduleAuditEntry(action, module, "git "+args[0])
+	entry.Args = append([]string{"git"}, args...)
 
-		git.Git("commit", "-m", commitMsg, "package.json")
+	return execute.audit.Run(entry, func(entry *AuditEntry) error {
+		var err error
+		entry.Output, err = git.ExecInPathOutput(module.Path, args...)
+		return err
 	})
 }
 
-func (execute executeApplier) ChangelogRelease(path string, revision string) error {
-	// Allow pre-release pattern here to handle the case where we need to rename the changelogs in the "main" branch
-	// to a pre-release version during an RC build where we create a new stable branch.
-	return changelog.ReleaseInPath(path, revision, changelog.SemverVersionPatternWithPreRelease)
+func moduleAuditEntry(action AuditAction, module project.Module, description string) AuditEntry {
+	return AuditEntry{
+		Action:      action,
+		Module:      module.Name,
+		Directory:   module.Path,
+		Description: description,
+	}
 }
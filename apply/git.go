@@ -0,0 +1,144 @@
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/Graylog2/graylog-project-cli/gh"
+	"github.com/Graylog2/graylog-project-cli/project"
+)
+
+// DefaultRemote is the remote that receives the release commits, tags and branches if nothing else is configured.
+const DefaultRemote = "origin"
+
+const DefaultTagMessage = "[{{.Module}}] Release {{.Version}}"
+
+// Only the pom.xml files of the module get committed. (files in "target" directories are untracked)
+const pomPathspec = ":(glob)**/pom.xml"
+
+type GitConfig struct {
+	// The remote for pushing. Defaults to DefaultRemote.
+	Remote string
+	// Go template for the annotated tag message. (fields: Module, Version, Tag) Defaults to DefaultTagMessage.
+	TagMessage string
+	// Create lightweight tags instead of annotated tags.
+	LightweightTags bool
+}
+
+type applierOptions struct {
+	git    GitConfig
+	plan   *Plan
+	github *gh.Client
+	audit  *AuditLog
+}
+
+type applierOption func(*applierOptions)
+
+func WithGitConfig(config GitConfig) applierOption {
+	return func(o *applierOptions) {
+		o.git = config
+	}
+}
+
+// WithPlan records all changes in the given plan. Only supported by the noop applier.
+func WithPlan(plan *Plan) applierOption {
+	return func(o *applierOptions) {
+		o.plan = plan
+	}
+}
+
+// WithGitHubClient sets the client that is used to create GitHub releases.
+func WithGitHubClient(client *gh.Client) applierOption {
+	return func(o *applierOptions) {
+		o.github = client
+	}
+}
+
+// WithAuditLog records all executed actions in the given audit log. Only supported by the execute applier.
+func WithAuditLog(log *AuditLog) applierOption {
+	return func(o *applierOptions) {
+		o.audit = log
+	}
+}
+
+func newApplierOptions(options []applierOption) applierOptions {
+	o := applierOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	if o.git.Remote == "" {
+		o.git.Remote = DefaultRemote
+	}
+	if o.git.TagMessage == "" {
+		o.git.TagMessage = DefaultTagMessage
+	}
+	return o
+}
+
+func remoteOrDefault(remote string) string {
+	if remote == "" {
+		return DefaultRemote
+	}
+	return remote
+}
+
+// The commit messages are also used to detect release commits during a rollback.
+func releaseCommitMessage(moduleName string, version string) string {
+	return fmt.Sprintf("[%s] prepare release %s", moduleName, version)
+}
+
+func developmentCommitMessage(moduleName string) string {
+	return fmt.Sprintf("[%s] prepare for next development iteration", moduleName)
+}
+
+func tagMessage(config GitConfig, module project.Module, tag string) (string, error) {
+	tmpl, err := template.New("tag-message").Option("missingkey=error").Parse(config.TagMessage)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse tag message template %q: %w", config.TagMessage, err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]string{
+		"Module":  module.Name,
+		"Version": module.Revision,
+		"Tag":     tag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't render tag message template %q: %w", config.TagMessage, err)
+	}
+
+	return buf.String(), nil
+}
+
+// Returns the git arguments to create the given tag.
+func tagArgs(config GitConfig, module project.Module, tag string) ([]string, error) {
+	if config.LightweightTags {
+		return []string{"tag", tag}, nil
+	}
+
+	message, err := tagMessage(config, module, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"tag", "--annotate", "--message", message, tag}, nil
+}
+
+// Returns the git arguments to push the given refs atomically.
+func pushArgs(config GitConfig, refs []string) []string {
+	return append([]string{"push", "--atomic", config.Remote}, refs...)
+}
+
+// ReleaseRefs returns the refs that the release creates or updates for the given module. All of them get pushed
+// together at the end of the release.
+func ReleaseRefs(module project.Module) []string {
+	refs := []string{
+		"refs/heads/" + module.ApplyFromRevision(),
+		"refs/tags/" + module.Revision,
+	}
+	if module.ApplyNewBranch() != "" {
+		refs = append(refs, "refs/heads/"+module.ApplyNewBranch())
+	}
+	return refs
+}
@@ -0,0 +1,102 @@
+package apply
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Creates a repository with a "main" branch and an empty "origin" bare repository as remote. The Git identity for
+// commits is set for the test. Returns the paths of the repository and the remote.
+func newTestRepo(t *testing.T, name string) (string, string) {
+	t.Helper()
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	path := filepath.Join(t.TempDir(), name)
+	remote := filepath.Join(t.TempDir(), name+".git")
+	runGit(t, ".", "init", "--quiet", "--bare", "--initial-branch", "main", remote)
+	runGit(t, ".", "init", "--quiet", "--initial-branch", "main", path)
+	runGit(t, path, "remote", "add", "origin", remote)
+
+	return path, remote
+}
+
+// Runs the Git command in the given directory and returns the output. The test fails if the command fails.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return string(output)
+}
+
+func TestTagArgs(t *testing.T) {
+	module := project.Module{Name: "graylog-server", Revision: "6.2.0"}
+	config := newApplierOptions(nil).git
+
+	args, err := tagArgs(config, module, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tag", "--annotate", "--message", "[graylog-server] Release 6.2.0", "6.2.0"}, args)
+
+	config.TagMessage = `Graylog {{.Version}} "{{.Tag}}"`
+	args, err = tagArgs(config, module, "v6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tag", "--annotate", "--message", `Graylog 6.2.0 "v6.2.0"`, "v6.2.0"}, args)
+
+	config.LightweightTags = true
+	args, err = tagArgs(config, module, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tag", "6.2.0"}, args)
+}
+
+func TestTagArgsInvalidTemplate(t *testing.T) {
+	module := project.Module{Name: "graylog-server", Revision: "6.2.0"}
+
+	_, err := tagArgs(GitConfig{TagMessage: "{{.Version"}, module, "6.2.0")
+	assert.ErrorContains(t, err, "couldn't parse tag message template")
+
+	_, err = tagArgs(GitConfig{TagMessage: "{{.Nope}}"}, module, "6.2.0")
+	assert.ErrorContains(t, err, "couldn't render tag message template")
+}
+
+func TestPushArgs(t *testing.T) {
+	config := newApplierOptions([]applierOption{WithGitConfig(GitConfig{Remote: "upstream"})}).git
+
+	assert.Equal(t, []string{"push", "--atomic", "upstream", "refs/heads/6.2", "refs/tags/6.2.0"},
+		pushArgs(config, []string{"refs/heads/6.2", "refs/tags/6.2.0"}))
+	assert.Equal(t, DefaultTagMessage, config.TagMessage)
+}
+
+func TestExecuteApplierCommitAndTag(t *testing.T) {
+	path, remote := newTestRepo(t, "graylog2-server")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pom.xml"), []byte("<project/>\n"), 0644))
+	runGit(t, path, "add", "pom.xml")
+	runGit(t, path, "commit", "--quiet", "--message", "initial")
+
+	module := project.Module{
+		Name:     "graylog-server",
+		Path:     path,
+		Revision: "6.2.0",
+	}
+	applier := NewExecuteApplier(nil)
+
+	// Nothing to commit yet
+	require.NoError(t, applier.GitCommitRelease(module, module.Revision))
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pom.xml"), []byte("<project><version>6.2.0</version></project>\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "untracked.txt"), []byte("nope\n"), 0644))
+	require.NoError(t, applier.GitCommitRelease(module, module.Revision))
+	require.NoError(t, applier.GitTag(module, module.Revision))
+	require.NoError(t, applier.GitPush(module, []string{"refs/heads/main", "refs/tags/6.2.0"}))
+
+	assert.Equal(t, "[graylog-server] prepare release 6.2.0\ninitial\n", runGit(t, remote, "log", "--format=%s", "main"))
+	assert.Equal(t, "tag [graylog-server] Release 6.2.0\n", runGit(t, remote, "tag", "--list", "--format=%(objecttype) %(contents:subject)"))
+	assert.Equal(t, "?? untracked.txt\n", runGit(t, path, "status", "--porcelain"))
+}
@@ -0,0 +1,148 @@
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/changelog"
+	"github.com/Graylog2/graylog-project-cli/gh"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/hashicorp/go-version"
+)
+
+// DraftMode controls which GitHub releases are created as drafts.
+type DraftMode string
+
+const (
+	DraftNone       DraftMode = "none"
+	DraftPreRelease DraftMode = "prerelease"
+	DraftAll        DraftMode = "all"
+)
+
+var DraftModes = []DraftMode{DraftNone, DraftPreRelease, DraftAll}
+
+type GitHubRelease struct {
+	// The GitHub repository in "owner/repo" format.
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type GitHubReleaseOptions struct {
+	Draft DraftMode
+}
+
+// NewGitHubRelease returns the GitHub release for the revision of the given module. The release body is rendered
+// from the versioned changelog folder of the module. Versions with a pre-release part (e.g., "6.2.0-rc.1") are
+// marked as pre-release.
+func NewGitHubRelease(module project.Module, options GitHubReleaseOptions) (GitHubRelease, error) {
+	v, err := version.NewSemver(module.Revision)
+	if err != nil {
+		return GitHubRelease{}, fmt.Errorf("couldn't parse version %q of module %s: %w", module.Revision, module.Name, err)
+	}
+
+	githubURL, err := utils.ParseGitHubURL(module.Repository)
+	if err != nil {
+		return GitHubRelease{}, fmt.Errorf("module %s isn't a GitHub repository: %w", module.Name, err)
+	}
+	owner, repo, err := gh.SplitRepoString(githubURL.Repository())
+	if err != nil {
+		return GitHubRelease{}, err
+	}
+
+	prerelease := v.Prerelease() != ""
+
+	var draft bool
+	switch options.Draft {
+	case DraftNone, "":
+		draft = false
+	case DraftPreRelease:
+		draft = prerelease
+	case DraftAll:
+		draft = true
+	default:
+		return GitHubRelease{}, fmt.Errorf("invalid draft mode %q (valid: %v)", options.Draft, DraftModes)
+	}
+
+	body, err := renderGitHubReleaseBody(module, githubURL)
+	if err != nil {
+		return GitHubRelease{}, err
+	}
+
+	return GitHubRelease{
+		Repository: owner + "/" + repo,
+		Tag:        module.Revision,
+		Name:       module.Revision,
+		Body:       body,
+		Draft:      draft,
+		Prerelease: prerelease,
+	}, nil
+}
+
+func renderGitHubReleaseBody(module project.Module, githubURL utils.GitHubURL) (string, error) {
+	snippetsPath := filepath.Join(module.Path, "changelog", module.Revision)
+	if !utils.FileExists(snippetsPath) {
+		// Changelogs of pre-releases are only rotated when a new branch gets created
+		logger.Info("Couldn't find changelog folder %s, using unreleased changelogs", snippetsPath)
+		snippetsPath = filepath.Join(module.Path, "changelog", "unreleased")
+	}
+	if !utils.FileExists(snippetsPath) {
+		return "", fmt.Errorf("couldn't find changelog folder for version %s in module %s", module.Revision, module.Name)
+	}
+
+	var buf bytes.Buffer
+	err := changelog.Render(changelog.Config{
+		RenderFormat:            changelog.FormatMD,
+		RenderGitHubLinks:       true,
+		SnippetsPaths:           []string{snippetsPath},
+		ReleaseDate:             time.Now().Format("2006-01-02"),
+		ReleaseVersion:          module.Revision,
+		SkipHeader:              true,
+		RenderNoChanges:         true,
+		MarkdownHeaderBaseLevel: 2,
+		GitHubRepoURL:           githubURL.BrowserURL(),
+		Details:                 changelog.DetailsAggregated,
+	}, &buf)
+	if err != nil {
+		return "", fmt.Errorf("couldn't render changelog for module %s: %w", module.Name, err)
+	}
+
+	return buf.String(), nil
+}
+
+func createGitHubRelease(client *gh.Client, release GitHubRelease) error {
+	if client == nil {
+		return fmt.Errorf("missing GitHub client to create release for %s", release.Repository)
+	}
+
+	owner, repo, err := gh.SplitRepoString(release.Repository)
+	if err != nil {
+		return err
+	}
+
+	result, err := client.CreateOrUpdateRelease(owner, repo, gh.ReleaseOptions{
+		Tag:        release.Tag,
+		Name:       release.Name,
+		Body:       release.Body,
+		Draft:      release.Draft,
+		Prerelease: release.Prerelease,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Created {
+		logger.Info("Created GitHub release %s in %s: %s", result.Tag, release.Repository, result.URL)
+	} else {
+		logger.Info("Updated GitHub release %s in %s: %s", result.Tag, release.Repository, result.URL)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestSnippet(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestNewGitHubRelease(t *testing.T) {
+	path := t.TempDir()
+	writeTestSnippet(t, filepath.Join(path, "changelog", "6.2.0"), "pr-123.toml", `type = "fixed"
+message = "Fix the thing."
+pulls = ["123"]
+`)
+
+	module := project.Module{
+		Name:       "graylog-server",
+		Path:       path,
+		Repository: "github://Graylog2/graylog2-server.git",
+		Revision:   "6.2.0",
+	}
+
+	release, err := NewGitHubRelease(module, GitHubReleaseOptions{Draft: DraftPreRelease})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Graylog2/graylog2-server", release.Repository)
+	assert.Equal(t, "6.2.0", release.Tag)
+	assert.False(t, release.Prerelease)
+	assert.False(t, release.Draft)
+	assert.Contains(t, release.Body, "Fix the thing.")
+	assert.Contains(t, release.Body, "[graylog2-server#123](https://github.com/Graylog2/graylog2-server/issues/123)")
+}
+
+func TestNewGitHubReleasePreRelease(t *testing.T) {
+	path := t.TempDir()
+	// Pre-release changelogs fall back to the unreleased folder
+	writeTestSnippet(t, filepath.Join(path, "changelog", "unreleased"), ".gitkeep", "")
+
+	module := project.Module{
+		Name:       "graylog-server",
+		Path:       path,
+		Repository: "https://github.com/Graylog2/graylog2-server.git",
+		Revision:   "6.2.0-rc.1",
+	}
+
+	release, err := NewGitHubRelease(module, GitHubReleaseOptions{Draft: DraftPreRelease})
+	require.NoError(t, err)
+	assert.True(t, release.Prerelease)
+	assert.True(t, release.Draft)
+
+	release, err = NewGitHubRelease(module, GitHubReleaseOptions{})
+	require.NoError(t, err)
+	assert.True(t, release.Prerelease)
+	assert.False(t, release.Draft)
+
+	_, err = NewGitHubRelease(module, GitHubReleaseOptions{Draft: "nope"})
+	assert.ErrorContains(t, err, `invalid draft mode "nope"`)
+}
+
+func TestNewGitHubReleaseErrors(t *testing.T) {
+	module := project.Module{
+		Name:       "graylog-server",
+		Path:       t.TempDir(),
+		Repository: "/tmp/remotes/graylog2-server.git",
+		Revision:   "6.2.0",
+	}
+
+	_, err := NewGitHubRelease(module, GitHubReleaseOptions{})
+	assert.ErrorContains(t, err, "module graylog-server isn't a GitHub repository")
+
+	module.Repository = "github://Graylog2/graylog2-server.git"
+	_, err = NewGitHubRelease(module, GitHubReleaseOptions{})
+	assert.ErrorContains(t, err, "couldn't find changelog folder for version 6.2.0")
+}
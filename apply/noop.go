@@ -2,12 +2,24 @@ package apply
 
 import (
 	"fmt"
-	"github.com/Graylog2/graylog-project-cli/project"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/changelog"
+	"github.com/Graylog2/graylog-project-cli/pom"
+	"github.com/Graylog2/graylog-project-cli/pomparse"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
 )
 
-func NewNoopApplier(profiles []string) Applier {
-	applier := noopApplier{}
+func NewNoopApplier(profiles []string, options ...applierOption) Applier {
+	o := newApplierOptions(options)
+	applier := noopApplier{
+		git:        o.git,
+		plan:       o.plan,
+		simulation: newSimulation(),
+	}
 	applier.CommonMaven = CommonMaven{Profiles: profiles, Applier: applier}
 
 	return applier
@@ -16,32 +28,276 @@ func NewNoopApplier(profiles []string) Applier {
 // Check that noopApplier implements the Applier interface
 var _ Applier = (*noopApplier)(nil)
 
-// A no-op implementation of the apply.Applier interface which just prints the commands.
+// A no-op implementation of the apply.Applier interface which just prints the commands. If a plan is configured,
+// all changes are recorded in the plan.
 type noopApplier struct {
 	CommonMaven
+	git        GitConfig
+	plan       *Plan
+	simulation *simulation
+}
+
+// The noop applier doesn't modify any files. To still compute the correct follow-up changes, the changed values
+// are kept in memory.
+type simulation struct {
+	// Module path -> version change
+	versions map[string]VersionChange
+	// Module path + value key -> value
+	values map[string]string
+}
+
+func newSimulation() *simulation {
+	return &simulation{
+		versions: make(map[string]VersionChange),
+		values:   make(map[string]string),
+	}
+}
+
+// Returns the simulated value for the given key or the fallback if the value hasn't been changed.
+func (s *simulation) value(module project.Module, key string, fallback string) string {
+	if value, ok := s.values[module.Path+"\x00"+key]; ok {
+		return value
+	}
+	return fallback
+}
+
+func (s *simulation) setValue(module project.Module, key string, value string) {
+	s.values[module.Path+"\x00"+key] = value
+}
+
+func (noop noopApplier) ModuleVersion(module project.Module) string {
+	current := module.Version()
+	for path, change := range noop.simulation.versions {
+		// Submodules in the same reactor change their version together with the module
+		if (path == module.Path || strings.HasPrefix(module.Path, path+string(filepath.Separator))) && change.From == current {
+			return change.To
+		}
+	}
+	return current
+}
+
+func (noop noopApplier) MavenSetVersion(module project.Module, newVersion string) error {
+	fmt.Println("set version: " + newVersion)
+
+	change := VersionChange{Kind: VersionChangeProject, From: noop.ModuleVersion(module), To: newVersion}
+	noop.simulation.versions[module.Path] = VersionChange{From: module.Version(), To: newVersion}
+
+	if noop.plan != nil {
+		files, err := pom.SetVersionFiles(module.Path, newVersion)
+		if err != nil {
+			return fmt.Errorf("couldn't compute version change in %s: %w", module.Path, err)
+		}
+		noop.plan.addVersionChange(module, change, files...)
+	}
+	return nil
+}
+
+func (noop noopApplier) MavenSetDependencyVersion(module project.Module, groupId string, artifactId string, newVersion string) error {
+	coordinates := groupId + ":" + artifactId
+	if noop.simulation.value(module, "dependency:"+coordinates, "") == newVersion {
+		// Already changed in the simulation
+		return nil
+	}
+
+	fmt.Println("set dependency version: " + coordinates + ":" + newVersion)
+	noop.simulation.setValue(module, "dependency:"+coordinates, newVersion)
+
+	if noop.plan != nil {
+		files, err := pom.SetDependencyVersionFiles(module.Path, groupId, artifactId, newVersion)
+		if err != nil {
+			return fmt.Errorf("couldn't compute dependency version change in %s: %w", module.Path, err)
+		}
+		noop.plan.addVersionChange(module, VersionChange{Kind: VersionChangeDependency, Target: coordinates, To: newVersion}, files...)
+	}
+	return nil
 }
 
-func (noop noopApplier) MavenSetParent(module project.Module, parentVersion string) {
+func (noop noopApplier) MavenSetParent(module project.Module, parentVersion string) error {
+	if !module.HasParent() {
+		return nil
+	}
+	pomContent, err := pomparse.ParsePomE(filepath.Join(module.Path, "pom.xml"))
+	if err != nil {
+		return err
+	}
+	if !parentMatchFunc(module, *pomContent) {
+		return nil
+	}
+
+	current := noop.simulation.value(module, "parent", module.ParentVersion())
+	if current == parentVersion {
+		return nil
+	}
+
 	fmt.Println("set parent version: " + parentVersion)
+	noop.simulation.setValue(module, "parent", parentVersion)
+
+	if noop.plan != nil {
+		noop.plan.addVersionChange(module, VersionChange{
+			Kind:   VersionChangeParent,
+			Target: module.ParentGroupId() + ":" + module.ParentArtifactId(),
+			From:   current,
+			To:     parentVersion,
+		}, "pom.xml")
+	}
+	return nil
 }
 
-func (noop noopApplier) MavenSetProperty(module project.Module, name string, value string) {
+func (noop noopApplier) MavenSetProperty(module project.Module, name string, value string) error {
+	pomFile := filepath.Join(module.Path, "pom.xml")
+	if !utils.FileExists(pomFile) {
+		// Nothing to simulate for modules without a pom file
+		return nil
+	}
+
+	doc, err := pom.LoadDocument(pomFile)
+	if err != nil {
+		return fmt.Errorf("unable to load pom file: %w", err)
+	}
+
+	// Same conditions as in pom.SetProperty
+	prevValue, hasName := doc.Properties()[name]
+	prevValue = noop.simulation.value(module, "property:"+name, prevValue)
+	if !hasName || prevValue == value || strings.HasPrefix(prevValue, "${") {
+		return nil
+	}
+
 	fmt.Println("set property: <" + name + ">" + value + "</" + name + ">")
+	noop.simulation.setValue(module, "property:"+name, value)
+
+	if noop.plan != nil {
+		noop.plan.addVersionChange(module, VersionChange{Kind: VersionChangeProperty, Target: name, From: prevValue, To: value}, "pom.xml")
+	}
+	return nil
 }
 
-func (noop noopApplier) MavenExec(commands []string) {
+// The plan records the profiles and arguments of the Maven run instead of the complete command, because the command
+// contains the Maven binary and environment specific options.
+func (noop noopApplier) MavenRunWithProfiles(profiles []string, args ...string) error {
+	if noop.plan != nil {
+		noop.plan.addMavenInvocation(profiles, args)
+	}
+	return noop.CommonMaven.MavenRunWithProfiles(profiles, args...)
+}
+
+func (noop noopApplier) MavenRun(args ...string) error {
+	return noop.MavenRunWithProfiles([]string{}, args...)
+}
+
+func (noop noopApplier) MavenExec(commands []string) error {
 	fmt.Println(strings.Join(commands, " "))
+	return nil
 }
 
-func (noop noopApplier) NpmVersionSet(module project.Module, newVersion string) {
+func (noop noopApplier) NpmVersionSet(module project.Module, newVersion string) error {
 	fmt.Println("set web module version: " + newVersion)
+
+	if noop.plan != nil {
+		for _, file := range npmVersionFiles(module) {
+			noop.plan.addVersionChange(module, VersionChange{Kind: VersionChangeNpm, Target: file, To: newVersion}, file)
+		}
+	}
+	return nil
 }
 
-func (noop noopApplier) NpmVersionCommit(module project.Module, newVersion string) {
+func (noop noopApplier) NpmVersionCommit(module project.Module, newVersion string) error {
 	fmt.Println("commit web module version: " + newVersion)
+
+	if files := npmVersionFiles(module); noop.plan != nil && len(files) > 0 {
+		noop.plan.addCommit(module, npmCommitMessage(newVersion), files...)
+	}
+	return nil
 }
 
 func (noop noopApplier) ChangelogRelease(path string, revision string) error {
 	fmt.Println("rotating changelog: ", path, revision)
+
+	if noop.plan == nil {
+		return nil
+	}
+
+	// Same conditions as in changelog.ReleaseInPath
+	unreleasedPath := filepath.Join("changelog", "unreleased")
+	versionPath := filepath.Join("changelog", revision)
+	if !utils.FileExists(filepath.Join(path, unreleasedPath)) || utils.FileExists(filepath.Join(path, versionPath)) {
+		return nil
+	}
+
+	noop.plan.addChangelogMove(path, ChangelogMove{
+		From:    unreleasedPath,
+		To:      versionPath,
+		Message: changelog.ReleaseCommitMessage(revision),
+	})
+
+	return nil
+}
+
+func (noop noopApplier) GitCommitRelease(module project.Module, version string) error {
+	message := releaseCommitMessage(module.Name, version)
+	if noop.plan != nil {
+		noop.plan.addCommit(module, message, pomPathspec)
+	}
+	return noop.printGit("commit", "--message", message, "--", pomPathspec)
+}
+
+func (noop noopApplier) GitCommitDevelopment(module project.Module) error {
+	message := developmentCommitMessage(module.Name)
+	if noop.plan != nil {
+		noop.plan.addCommit(module, message, pomPathspec)
+	}
+	return noop.printGit("commit", "--message", message, "--", pomPathspec)
+}
+
+func (noop noopApplier) GitTag(module project.Module, tag string) error {
+	args, err := tagArgs(noop.git, module, tag)
+	if err != nil {
+		return err
+	}
+	if noop.plan != nil {
+		planTag := PlanTag{Name: tag}
+		if !noop.git.LightweightTags {
+			// Validated by tagArgs
+			planTag.Message, _ = tagMessage(noop.git, module, tag)
+		}
+		noop.plan.addTag(module, planTag)
+	}
+	return noop.printGit(args...)
+}
+
+func (noop noopApplier) GitBranch(module project.Module, branch string) error {
+	if noop.plan != nil {
+		noop.plan.addBranch(module, branch)
+	}
+	return noop.printGit("branch", branch)
+}
+
+func (noop noopApplier) GitPush(module project.Module, refs []string) error {
+	if noop.plan != nil {
+		noop.plan.addPush(module, PlanPush{Remote: noop.git.Remote, Refs: refs})
+	}
+	return noop.printGit(pushArgs(noop.git, refs)...)
+}
+
+func (noop noopApplier) GitCheckout(module project.Module, ref string) error {
+	return noop.printGit("checkout", "--quiet", ref)
+}
+
+func (noop noopApplier) GitHubRelease(module project.Module, release GitHubRelease) error {
+	if noop.plan != nil {
+		noop.plan.addGitHubRelease(module, release)
+	}
+	fmt.Printf("create GitHub release: %s %s (draft=%t, prerelease=%t)\n", release.Repository, release.Tag, release.Draft, release.Prerelease)
+	return nil
+}
+
+func (noop noopApplier) printGit(args ...string) error {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.ContainsAny(arg, " \"'[]*") {
+			arg = strconv.Quote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	fmt.Println("git " + strings.Join(quoted, " "))
 	return nil
 }
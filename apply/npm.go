@@ -0,0 +1,35 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+)
+
+// Returns the package.json files of the module that contain the module version, relative to the module path.
+func npmVersionFiles(module project.Module) []string {
+	return module.VersionPackageJsonFiles()
+}
+
+// ValidatePackageJsonFiles checks that all package.json files of the module exist and contain a version field.
+func ValidatePackageJsonFiles(module project.Module) error {
+	var errs []error
+	for _, file := range npmVersionFiles(module) {
+		filename := filepath.Join(module.Path, file)
+		if !utils.FileExists(filename) {
+			errs = append(errs, fmt.Errorf("package.json file %s of module %s doesn't exist", filename, module.Name))
+			continue
+		}
+		if _, err := utils.PackageJsonVersion(filename); err != nil {
+			errs = append(errs, fmt.Errorf("invalid package.json file of module %s: %w", module.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func npmCommitMessage(version string) string {
+	return fmt.Sprintf("Bump package.json version to %s", version)
+}
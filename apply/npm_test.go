@@ -0,0 +1,41 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePackageJsonFiles(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "web"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "web", "package.json"), []byte("{\n  \"version\": \"6.2.0-SNAPSHOT\",\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "package.json"), []byte("{\n  \"name\": \"root\"\n}\n"), 0644))
+
+	module := project.Module{Name: "graylog-server", Path: path, PackageJsonFiles: []string{"web/package.json"}}
+	assert.NoError(t, ValidatePackageJsonFiles(module))
+	assert.Equal(t, []string{"web/package.json"}, npmVersionFiles(module))
+
+	module.PackageJsonFiles = []string{"web/package.json", "package.json", "missing/package.json"}
+	err := ValidatePackageJsonFiles(module)
+	assert.ErrorContains(t, err, "couldn't find top-level version field in "+filepath.Join(path, "package.json"))
+	assert.ErrorContains(t, err, "package.json file "+filepath.Join(path, "missing", "package.json")+" of module graylog-server doesn't exist")
+}
+
+func TestNpmVersionFilesDefault(t *testing.T) {
+	module := project.Module{Name: "graylog-plugin-enterprise", Path: t.TempDir()}
+	// Modules without package.json don't have version files
+	assert.Empty(t, npmVersionFiles(module))
+	assert.NoError(t, ValidatePackageJsonFiles(module))
+
+	require.NoError(t, os.WriteFile(filepath.Join(module.Path, "package.json"), []byte("{\n  \"version\": \"6.2.0\",\n}\n"), 0644))
+	assert.Equal(t, []string{"package.json"}, npmVersionFiles(module))
+
+	module.Server = true
+	module.PackageJsonFiles = nil
+	assert.Contains(t, npmVersionFiles(module), "graylog2-web-interface/package.json")
+}
@@ -0,0 +1,404 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/google/renameio/v2"
+	"github.com/samber/lo"
+)
+
+// PlanFormatVersion is increased whenever the plan format changes in an incompatible way.
+const PlanFormatVersion = 1
+
+// Plan is the structured list of changes that a release makes. It gets recorded by the noop applier during a
+// dry-run so the release can be reviewed before it gets executed.
+type Plan struct {
+	// Module paths are stored relative to the root so plans can be compared between checkouts.
+	root string
+	// The currently running step.
+	step          string
+	FormatVersion int               `json:"format_version"`
+	ManifestFiles []string          `json:"manifest_files"`
+	Modules       []*ModulePlan     `json:"modules"`
+	Maven         []MavenInvocation `json:"maven"`
+}
+
+type ModulePlan struct {
+	Name           string          `json:"name"`
+	Path           string          `json:"path"`
+	VersionChanges []VersionChange `json:"version_changes"`
+	// Files that get modified, relative to the module path.
+	Files          []string        `json:"files"`
+	Commits        []PlanCommit    `json:"commits"`
+	Tags           []PlanTag       `json:"tags"`
+	Branches       []string        `json:"branches"`
+	ChangelogMoves []ChangelogMove `json:"changelog_moves"`
+	Pushes         []PlanPush      `json:"pushes"`
+	GitHubReleases []GitHubRelease `json:"github_releases"`
+}
+
+type VersionChangeKind string
+
+const (
+	VersionChangeProject    VersionChangeKind = "project"
+	VersionChangeDependency VersionChangeKind = "dependency"
+	VersionChangeParent     VersionChangeKind = "parent"
+	VersionChangeProperty   VersionChangeKind = "property"
+	VersionChangeNpm        VersionChangeKind = "npm"
+)
+
+type VersionChange struct {
+	Step string            `json:"step"`
+	Kind VersionChangeKind `json:"kind"`
+	// The dependency coordinates, property name or package.json file. Empty for project versions.
+	Target string `json:"target,omitempty"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+}
+
+type PlanCommit struct {
+	Step    string   `json:"step"`
+	Message string   `json:"message"`
+	Paths   []string `json:"paths"`
+}
+
+type PlanTag struct {
+	Name string `json:"name"`
+	// Empty for lightweight tags.
+	Message string `json:"message,omitempty"`
+}
+
+type ChangelogMove struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+type PlanPush struct {
+	Remote string   `json:"remote"`
+	Refs   []string `json:"refs"`
+}
+
+// MavenInvocation is a Maven run without the Maven binary and the environment specific options. (see CommonMaven)
+type MavenInvocation struct {
+	Step      string   `json:"step"`
+	Directory string   `json:"directory"`
+	Profiles  []string `json:"profiles"`
+	// Absolute paths in property values are relative to the plan root. (e.g., "-Dlocal.repo.path=target/repo")
+	Args []string `json:"args"`
+}
+
+// NewPlan returns an empty plan for all modules of the given project.
+func NewPlan(p project.Project, manifestFiles []string) *Plan {
+	plan := &Plan{
+		root:          utils.GetCwd(),
+		FormatVersion: PlanFormatVersion,
+		ManifestFiles: manifestFiles,
+		Modules:       make([]*ModulePlan, 0),
+		Maven:         make([]MavenInvocation, 0),
+	}
+
+	ForEachModule(p, true, func(module project.Module) {
+		plan.modulePlan(module.Name, module.Path)
+	})
+
+	return plan
+}
+
+// ReadPlan reads a plan that has been written in JSON format.
+func ReadPlan(filename string) (*Plan, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read release plan %s: %w", filename, err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(buf, &plan); err != nil {
+		return nil, fmt.Errorf("couldn't parse release plan %s: %w", filename, err)
+	}
+	if plan.FormatVersion != PlanFormatVersion {
+		return nil, fmt.Errorf("unsupported release plan format version %d in %s (expected %d)", plan.FormatVersion, filename, PlanFormatVersion)
+	}
+
+	return &plan, nil
+}
+
+// SetStep sets the step that is used for all following plan entries.
+func (p *Plan) SetStep(name string) {
+	p.step = name
+}
+
+func (p *Plan) relativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	if rel, err := filepath.Rel(p.root, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+func (p *Plan) modulePlan(name string, path string) *ModulePlan {
+	relPath := p.relativePath(path)
+	for _, module := range p.Modules {
+		if module.Path == relPath {
+			return module
+		}
+	}
+
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	module := &ModulePlan{
+		Name:           name,
+		Path:           relPath,
+		VersionChanges: make([]VersionChange, 0),
+		Files:          make([]string, 0),
+		Commits:        make([]PlanCommit, 0),
+		Tags:           make([]PlanTag, 0),
+		Branches:       make([]string, 0),
+		ChangelogMoves: make([]ChangelogMove, 0),
+		Pushes:         make([]PlanPush, 0),
+		GitHubReleases: make([]GitHubRelease, 0),
+	}
+	p.Modules = append(p.Modules, module)
+
+	return module
+}
+
+func (p *Plan) module(module project.Module) *ModulePlan {
+	return p.modulePlan(module.Name, module.Path)
+}
+
+func (p *Plan) addVersionChange(module project.Module, change VersionChange, files ...string) {
+	mp := p.module(module)
+	change.Step = p.step
+	mp.VersionChanges = append(mp.VersionChanges, change)
+	p.addFiles(mp, module.Path, files...)
+}
+
+// Adds the given files relative to the module path. The file list is sorted and doesn't contain duplicates.
+func (p *Plan) addFiles(mp *ModulePlan, modulePath string, files ...string) {
+	for _, file := range files {
+		if filepath.IsAbs(file) {
+			if rel, err := filepath.Rel(modulePath, file); err == nil {
+				file = rel
+			}
+		}
+		if !slices.Contains(mp.Files, file) {
+			mp.Files = append(mp.Files, file)
+		}
+	}
+	slices.Sort(mp.Files)
+}
+
+func (p *Plan) addCommit(module project.Module, message string, paths ...string) {
+	mp := p.module(module)
+	mp.Commits = append(mp.Commits, PlanCommit{Step: p.step, Message: message, Paths: paths})
+}
+
+func (p *Plan) addTag(module project.Module, tag PlanTag) {
+	mp := p.module(module)
+	mp.Tags = append(mp.Tags, tag)
+}
+
+func (p *Plan) addBranch(module project.Module, branch string) {
+	mp := p.module(module)
+	mp.Branches = append(mp.Branches, branch)
+}
+
+func (p *Plan) addPush(module project.Module, push PlanPush) {
+	mp := p.module(module)
+	mp.Pushes = append(mp.Pushes, push)
+}
+
+func (p *Plan) addGitHubRelease(module project.Module, release GitHubRelease) {
+	mp := p.module(module)
+	mp.GitHubReleases = append(mp.GitHubReleases, release)
+}
+
+func (p *Plan) addChangelogMove(path string, move ChangelogMove) {
+	mp := p.modulePlan("", path)
+	// The changelog rotation is a no-op if the target already exists, so the second rotation of the same version
+	// doesn't move anything.
+	for _, existing := range mp.ChangelogMoves {
+		if existing.To == move.To {
+			return
+		}
+	}
+	mp.ChangelogMoves = append(mp.ChangelogMoves, move)
+	p.addFiles(mp, path, move.From, move.To)
+}
+
+func (p *Plan) addMavenInvocation(profiles []string, args []string) {
+	p.Maven = append(p.Maven, MavenInvocation{
+		Step:      p.step,
+		Directory: p.relativePath(utils.GetCwd()),
+		Profiles:  append([]string{}, profiles...),
+		Args: lo.Map(args, func(arg string, _ int) string {
+			if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "-D") && filepath.IsAbs(value) {
+				return name + "=" + filepath.ToSlash(p.relativePath(value))
+			}
+			return arg
+		}),
+	})
+}
+
+// Diff returns a description of every difference between the plans. An empty result means that both plans are equal.
+func (p *Plan) Diff(other *Plan) []string {
+	diffs := make([]string, 0)
+
+	diffValue := func(name string, a any, b any) {
+		aJson, _ := json.Marshal(a)
+		bJson, _ := json.Marshal(b)
+		if string(aJson) != string(bJson) {
+			diffs = append(diffs, fmt.Sprintf("%s differ:\n  - %s\n  + %s", name, aJson, bJson))
+		}
+	}
+
+	diffValue("manifest files", p.ManifestFiles, other.ManifestFiles)
+
+	modules := make(map[string]*ModulePlan)
+	for _, module := range other.Modules {
+		modules[module.Name] = module
+	}
+	for _, module := range p.Modules {
+		otherModule, ok := modules[module.Name]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("module %s is missing", module.Name))
+			continue
+		}
+		delete(modules, module.Name)
+
+		diffValue("module "+module.Name+" path", module.Path, otherModule.Path)
+		diffValue("module "+module.Name+" version changes", module.VersionChanges, otherModule.VersionChanges)
+		diffValue("module "+module.Name+" files", module.Files, otherModule.Files)
+		diffValue("module "+module.Name+" commits", module.Commits, otherModule.Commits)
+		diffValue("module "+module.Name+" tags", module.Tags, otherModule.Tags)
+		diffValue("module "+module.Name+" branches", module.Branches, otherModule.Branches)
+		diffValue("module "+module.Name+" changelog moves", module.ChangelogMoves, otherModule.ChangelogMoves)
+		diffValue("module "+module.Name+" pushes", module.Pushes, otherModule.Pushes)
+		diffValue("module "+module.Name+" GitHub releases", module.GitHubReleases, otherModule.GitHubReleases)
+	}
+	for _, module := range other.Modules {
+		if _, ok := modules[module.Name]; ok {
+			diffs = append(diffs, fmt.Sprintf("module %s is unexpected", module.Name))
+		}
+	}
+
+	diffValue("maven invocations", p.Maven, other.Maven)
+
+	return diffs
+}
+
+// WriteFile writes the plan to the given file. Files with a ".md" extension are written in Markdown format,
+// all other files in JSON format.
+func (p *Plan) WriteFile(filename string) error {
+	var buf strings.Builder
+
+	if strings.EqualFold(filepath.Ext(filename), ".md") {
+		p.WriteMarkdown(&buf)
+	} else {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return fmt.Errorf("couldn't serialize release plan: %w", err)
+		}
+		buf.Write(append(data, '\n'))
+	}
+
+	if err := renameio.WriteFile(filename, []byte(buf.String()), 0644); err != nil {
+		return fmt.Errorf("couldn't write release plan %s: %w", filename, err)
+	}
+
+	return nil
+}
+
+func (p *Plan) WriteMarkdown(w io.Writer) {
+	code := func(value string) string {
+		return "`" + value + "`"
+	}
+	codeList := func(values []string, separator string) string {
+		return strings.Join(lo.Map(values, func(value string, _ int) string { return code(value) }), separator)
+	}
+
+	fmt.Fprintf(w, "# Release Plan\n\n")
+	fmt.Fprintf(w, "Manifests: %s\n", codeList(p.ManifestFiles, ", "))
+
+	for _, module := range p.Modules {
+		fmt.Fprintf(w, "\n## %s\n\nPath: %s\n", module.Name, code(module.Path))
+
+		if len(module.VersionChanges) > 0 {
+			fmt.Fprintf(w, "\n### Version Changes\n\n")
+			fmt.Fprintf(w, "| Step | Kind | Target | From | To |\n")
+			fmt.Fprintf(w, "|------|------|--------|------|----|\n")
+			for _, change := range module.VersionChanges {
+				fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n", change.Step, change.Kind, change.Target, change.From, change.To)
+			}
+		}
+		if len(module.Files) > 0 {
+			fmt.Fprintf(w, "\n### Modified Files\n\n")
+			for _, file := range module.Files {
+				fmt.Fprintf(w, "- %s\n", code(file))
+			}
+		}
+		if len(module.Commits) > 0 {
+			fmt.Fprintf(w, "\n### Commits\n\n")
+			for _, commit := range module.Commits {
+				fmt.Fprintf(w, "- %s (step: %s, paths: %s)\n", code(commit.Message), commit.Step, codeList(commit.Paths, " "))
+			}
+		}
+		if len(module.Tags) > 0 {
+			fmt.Fprintf(w, "\n### Tags\n\n")
+			for _, tag := range module.Tags {
+				if tag.Message == "" {
+					fmt.Fprintf(w, "- %s (lightweight)\n", code(tag.Name))
+				} else {
+					fmt.Fprintf(w, "- %s (message: %s)\n", code(tag.Name), code(tag.Message))
+				}
+			}
+		}
+		if len(module.Branches) > 0 {
+			fmt.Fprintf(w, "\n### Branches\n\n")
+			for _, branch := range module.Branches {
+				fmt.Fprintf(w, "- %s\n", code(branch))
+			}
+		}
+		if len(module.ChangelogMoves) > 0 {
+			fmt.Fprintf(w, "\n### Changelog Moves\n\n")
+			for _, move := range module.ChangelogMoves {
+				fmt.Fprintf(w, "- %s → %s (commit: %s)\n", code(move.From), code(move.To), code(move.Message))
+			}
+		}
+		if len(module.Pushes) > 0 {
+			fmt.Fprintf(w, "\n### Pushes\n\n")
+			for _, push := range module.Pushes {
+				fmt.Fprintf(w, "- %s: %s\n", code(push.Remote), codeList(push.Refs, " "))
+			}
+		}
+		if len(module.GitHubReleases) > 0 {
+			fmt.Fprintf(w, "\n### GitHub Releases\n")
+			for _, release := range module.GitHubReleases {
+				fmt.Fprintf(w, "\n- %s in %s (draft: %t, prerelease: %t)\n", code(release.Tag), code(release.Repository), release.Draft, release.Prerelease)
+				fmt.Fprintf(w, "\n<details><summary>Release notes</summary>\n\n%s\n</details>\n", strings.TrimSpace(release.Body))
+			}
+		}
+	}
+
+	if len(p.Maven) > 0 {
+		fmt.Fprintf(w, "\n## Maven Invocations\n\n")
+		fmt.Fprintf(w, "| Step | Directory | Profiles | Arguments |\n")
+		fmt.Fprintf(w, "|------|-----------|----------|-----------|\n")
+		for _, invocation := range p.Maven {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n", invocation.Step, code(invocation.Directory),
+				code(strings.Join(invocation.Profiles, ",")), code(strings.Join(invocation.Args, " ")))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoopApplierRecordsPlan(t *testing.T) {
+	path := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(path, "changelog", "unreleased"), 0755))
+
+	module := project.Module{Name: "graylog-server", Path: path, Revision: "6.2.0"}
+	plan := NewPlan(project.Project{Modules: []project.Module{module}}, []string{"release.json"})
+	applier := NewNoopApplier(nil, WithGitConfig(GitConfig{Remote: "upstream"}), WithPlan(plan))
+
+	plan.SetStep("rotate-changelogs")
+	require.NoError(t, applier.ChangelogRelease(path, "6.2.0"))
+	// The second rotation is a no-op
+	require.NoError(t, applier.ChangelogRelease(path, "6.2.0"))
+
+	plan.SetStep("commit-and-tag-release")
+	require.NoError(t, applier.GitCommitRelease(module, "6.2.0"))
+	require.NoError(t, applier.GitTag(module, "6.2.0"))
+
+	plan.SetStep("push")
+	require.NoError(t, applier.GitPush(module, []string{"refs/heads/main", "refs/tags/6.2.0"}))
+
+	plan.SetStep("deploy")
+	require.NoError(t, applier.MavenRun("clean", "deploy"))
+
+	require.Len(t, plan.Modules, 1)
+	mp := plan.Modules[0]
+	assert.Equal(t, "graylog-server", mp.Name)
+	assert.Equal(t, []ChangelogMove{{
+		From:    filepath.Join("changelog", "unreleased"),
+		To:      filepath.Join("changelog", "6.2.0"),
+		Message: "Release changelog for version 6.2.0",
+	}}, mp.ChangelogMoves)
+	assert.Equal(t, []string{filepath.Join("changelog", "6.2.0"), filepath.Join("changelog", "unreleased")}, mp.Files)
+	assert.Equal(t, []PlanCommit{{
+		Step:    "commit-and-tag-release",
+		Message: "[graylog-server] prepare release 6.2.0",
+		Paths:   []string{pomPathspec},
+	}}, mp.Commits)
+	assert.Equal(t, []PlanTag{{Name: "6.2.0", Message: "[graylog-server] Release 6.2.0"}}, mp.Tags)
+	assert.Equal(t, []PlanPush{{Remote: "upstream", Refs: []string{"refs/heads/main", "refs/tags/6.2.0"}}}, mp.Pushes)
+
+	require.Len(t, plan.Maven, 1)
+	assert.Equal(t, "deploy", plan.Maven[0].Step)
+	assert.Empty(t, plan.Maven[0].Profiles)
+	assert.Equal(t, []string{"clean", "deploy"}, plan.Maven[0].Args)
+}
+
+func TestNoopApplierSetPropertyWithoutPom(t *testing.T) {
+	module := project.Module{Name: "graylog-plugin", Path: filepath.Join(t.TempDir(), "missing")}
+	plan := NewPlan(project.Project{Modules: []project.Module{module}}, []string{"release.json"})
+	applier := NewNoopApplier(nil, WithPlan(plan))
+
+	require.NoError(t, applier.MavenSetProperty(module, "graylog.version", "6.2.0"))
+	require.Len(t, plan.Modules, 1)
+	assert.Empty(t, plan.Modules[0].VersionChanges)
+	assert.NoDirExists(t, module.Path)
+}
+
+func TestPlanWriteReadAndDiff(t *testing.T) {
+	dir := t.TempDir()
+
+	module := project.Module{Name: "graylog-server", Path: filepath.Join(dir, "graylog2-server"), Revision: "6.2.0"}
+	plan := NewPlan(project.Project{Modules: []project.Module{module}}, []string{"release.json"})
+	plan.addTag(module, PlanTag{Name: "6.2.0"})
+	plan.addBranch(module, "6.2")
+
+	require.NoError(t, plan.WriteFile(filepath.Join(dir, "plan.json")))
+	require.NoError(t, plan.WriteFile(filepath.Join(dir, "plan.md")))
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "plan.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "## graylog-server")
+	assert.Contains(t, string(markdown), "- `6.2.0` (lightweight)")
+
+	reviewed, err := ReadPlan(filepath.Join(dir, "plan.json"))
+	require.NoError(t, err)
+	assert.Empty(t, reviewed.Diff(plan))
+
+	plan.addBranch(module, "6.3")
+	diffs := reviewed.Diff(plan)
+	require.Len(t, diffs, 1)
+	assert.Contains(t, diffs[0], "module graylog-server branches differ")
+
+	other := NewPlan(project.Project{}, []string{"release.json"})
+	assert.Equal(t, []string{"module graylog-server is missing"}, reviewed.Diff(other))
+	assert.Equal(t, []string{"module graylog-server is unexpected"}, other.Diff(reviewed))
+}
+
+func TestReadPlanUnsupportedFormat(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plan.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{"format_version": 99}`), 0644))
+
+	_, err := ReadPlan(filename)
+	assert.ErrorContains(t, err, "unsupported release plan format version 99")
+}
@@ -0,0 +1,356 @@
+package apply
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/changelog"
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/pomparse"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/hashicorp/go-version"
+)
+
+type CheckStatus string
+
+const (
+	CheckOK      CheckStatus = "ok"
+	CheckWarning CheckStatus = "warning"
+	CheckFailed  CheckStatus = "failed"
+)
+
+const (
+	CheckUncommittedChanges = "uncommitted-changes"
+	CheckReleaseTag         = "release-tag"
+	CheckFromRevision       = "from-revision"
+	CheckNewBranch          = "new-branch"
+	CheckVersions           = "versions"
+	CheckPushAccess         = "push-access"
+	CheckChangelogLint      = "changelog-lint"
+	CheckPackageJson        = "package-json"
+	CheckJDK                = "jdk"
+
+	// Reported for modules that don't have a local repository yet. Not a check that can be skipped.
+	CheckRepository = "repository"
+)
+
+// CheckResult is the outcome of a single pre-flight check. Module is empty for project-wide checks.
+type CheckResult struct {
+	Check   string      `json:"check"`
+	Module  string      `json:"module,omitempty"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+func (r CheckResult) Failed() bool {
+	return r.Status == CheckFailed
+}
+
+type PreflightOptions struct {
+	// The remote of the release. Defaults to DefaultRemote.
+	Remote string
+	// Names of the checks that should be skipped.
+	Skip []string
+}
+
+type preflightCheck struct {
+	name string
+	// Called for every module. Returns the status and a message.
+	module func(module project.Module, remote string) (CheckStatus, string)
+	// Called once for the whole project.
+	project func(p project.Project) (CheckStatus, string)
+}
+
+var preflightChecks = []preflightCheck{
+	{name: CheckUncommittedChanges, module: checkUncommittedChanges},
+	{name: CheckFromRevision, module: checkFromRevision},
+	{name: CheckReleaseTag, module: checkReleaseTag},
+	{name: CheckNewBranch, module: checkNewBranch},
+	{name: CheckVersions, module: checkVersions},
+	{name: CheckPushAccess, module: checkPushAccess},
+	{name: CheckChangelogLint, module: checkChangelogLint},
+	{name: CheckPackageJson, module: checkPackageJson},
+	{name: CheckJDK, project: checkJDK},
+}
+
+// PreflightCheckNames returns the names of all pre-flight checks in execution order.
+func PreflightCheckNames() []string {
+	names := make([]string, 0, len(preflightChecks))
+	for _, check := range preflightChecks {
+		names = append(names, check.name)
+	}
+	return names
+}
+
+// RunPreflight verifies that the given apply manifest project can be released. The checks don't modify the
+// repositories except for fetching the remote branches.
+func RunPreflight(p project.Project, options PreflightOptions) ([]CheckResult, error) {
+	for _, name := range options.Skip {
+		if !slices.Contains(PreflightCheckNames(), name) {
+			return nil, fmt.Errorf("unknown pre-flight check %q (available: %s)", name, strings.Join(PreflightCheckNames(), ", "))
+		}
+	}
+
+	gitRemote := remoteOrDefault(options.Remote)
+	results := make([]CheckResult, 0)
+
+	// The module checks need a local repository. Modules that haven't been cloned yet are skipped.
+	missing := make(map[string]bool)
+	ForEachModule(p, false, func(module project.Module) {
+		if !utils.FileExists(module.Path) {
+			missing[module.Path] = true
+			results = append(results, CheckResult{Check: CheckRepository, Module: module.Name, Status: CheckWarning,
+				Message: fmt.Sprintf("repository %s doesn't exist yet, skipping module checks", module.Path)})
+		}
+	})
+
+	// All checks work with the current state of the remote branches
+	ForEachModule(p, false, func(module project.Module) {
+		if missing[module.Path] {
+			return
+		}
+		if err := git.ExecInPath(module.Path, "fetch", "--quiet", "--no-tags", gitRemote); err != nil {
+			results = append(results, CheckResult{Check: "fetch", Module: module.Name, Status: CheckFailed, Message: err.Error()})
+		}
+	})
+
+	for _, check := range preflightChecks {
+		if slices.Contains(options.Skip, check.name) {
+			continue
+		}
+		if check.project != nil {
+			status, message := check.project(p)
+			results = append(results, CheckResult{Check: check.name, Status: status, Message: message})
+		}
+		if check.module != nil {
+			ForEachModule(p, false, func(module project.Module) {
+				if missing[module.Path] {
+					return
+				}
+				status, message := check.module(module, gitRemote)
+				results = append(results, CheckResult{Check: check.name, Module: module.Name, Status: status, Message: message})
+			})
+		}
+	}
+
+	return results, nil
+}
+
+func checkUncommittedChanges(module project.Module, gitRemote string) (CheckStatus, string) {
+	changes, err := git.HasUncommittedChanges(module.Path)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if changes {
+		return CheckFailed, "repository has uncommitted changes"
+	}
+	return CheckOK, "no uncommitted changes"
+}
+
+func checkFromRevision(module project.Module, gitRemote string) (CheckStatus, string) {
+	commit, err := git.RemoteRef(module.Path, gitRemote, "refs/heads/"+module.ApplyFromRevision())
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if commit == "" {
+		return CheckFailed, fmt.Sprintf("branch %s doesn't exist on remote %s", module.ApplyFromRevision(), gitRemote)
+	}
+	return CheckOK, fmt.Sprintf("branch %s exists (%s)", module.ApplyFromRevision(), shortCommit(commit))
+}
+
+func checkReleaseTag(module project.Module, gitRemote string) (CheckStatus, string) {
+	return checkRefMissing(module, gitRemote, "tag", "refs/tags/"+module.Revision)
+}
+
+func checkNewBranch(module project.Module, gitRemote string) (CheckStatus, string) {
+	if module.ApplyNewBranch() == "" {
+		return CheckOK, "no new branch requested"
+	}
+	return checkRefMissing(module, gitRemote, "branch", "refs/heads/"+module.ApplyNewBranch())
+}
+
+// Checks that the given ref doesn't exist in the local repository or on the remote.
+func checkRefMissing(module project.Module, gitRemote string, kind string, ref string) (CheckStatus, string) {
+	name := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/tags/"), "refs/heads/")
+
+	local, _, _ := git.ResolveRef(module.Path, ref)
+	if local != "" {
+		return CheckFailed, fmt.Sprintf("%s %s already exists locally (%s)", kind, name, shortCommit(local))
+	}
+
+	remote, err := git.RemoteRef(module.Path, gitRemote, ref)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if remote != "" {
+		return CheckFailed, fmt.Sprintf("%s %s already exists on remote %s (%s)", kind, name, gitRemote, shortCommit(remote))
+	}
+
+	return CheckOK, fmt.Sprintf("%s %s doesn't exist yet", kind, name)
+}
+
+func checkPackageJson(module project.Module, gitRemote string) (CheckStatus, string) {
+	files := 0
+	for _, m := range append([]project.Module{module}, module.Submodules...) {
+		if err := ValidatePackageJsonFiles(m); err != nil {
+			return CheckFailed, err.Error()
+		}
+		files += len(npmVersionFiles(m))
+	}
+	if files == 0 {
+		return CheckOK, "no package.json files"
+	}
+	return CheckOK, fmt.Sprintf("%d package.json files with version field", files)
+}
+
+func checkVersions(module project.Module, gitRemote string) (CheckStatus, string) {
+	var content string
+	err := utils.InDirectoryE(module.Path, func() error {
+		var err error
+		content, err = git.GitValueE("show", fmt.Sprintf("%s/%s:pom.xml", gitRemote, module.ApplyFromRevision()))
+		return err
+	})
+	if err != nil {
+		// Modules without pom.xml only need valid versions
+		if err := checkVersionOrder("", module.Revision, module.ApplyNewVersion()); err != nil {
+			return CheckFailed, err.Error()
+		}
+		return CheckWarning, fmt.Sprintf("couldn't read pom.xml from %s/%s, only checked release and next version", gitRemote, module.ApplyFromRevision())
+	}
+
+	pom, err := pomparse.ParsePomContentE([]byte(content))
+	if err != nil {
+		return CheckFailed, fmt.Sprintf("couldn't parse pom.xml: %s", err)
+	}
+	current, _ := utils.FirstNonEmpty(pom.Version, pom.ParentVersion)
+
+	if err := checkVersionOrder(current, module.Revision, module.ApplyNewVersion()); err != nil {
+		return CheckFailed, err.Error()
+	}
+
+	return CheckOK, fmt.Sprintf("%s -> %s -> %s", current, module.Revision, module.ApplyNewVersion())
+}
+
+var releaseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+
+// Checks that the given versions are valid and that the current version < release version < next version.
+// For pre-releases, the next version can go back to the development version of the same release.
+// (e.g., 6.2.0-rc.1 -> 6.2.0-SNAPSHOT) The current version is optional.
+func checkVersionOrder(current string, release string, next string) error {
+	if !releaseVersionPattern.MatchString(release) {
+		return fmt.Errorf("invalid release version %q: expected <major>.<minor>.<patch>[-<pre-release>]", release)
+	}
+	if !releaseVersionPattern.MatchString(next) {
+		return fmt.Errorf("invalid next version %q: expected <major>.<minor>.<patch>[-<pre-release>]", next)
+	}
+
+	releaseVersion, err := version.NewSemver(release)
+	if err != nil {
+		return fmt.Errorf("invalid release version %q: %w", release, err)
+	}
+	nextVersion, err := version.NewSemver(next)
+	if err != nil {
+		return fmt.Errorf("invalid next version %q: %w", next, err)
+	}
+
+	if releaseVersion.Prerelease() != "" {
+		if nextVersion.Core().LessThan(releaseVersion.Core()) {
+			return fmt.Errorf("next version %s must not be lower than release version %s", next, release)
+		}
+	} else if !releaseVersion.LessThan(nextVersion) {
+		return fmt.Errorf("next version %s must be greater than release version %s", next, release)
+	}
+
+	if current == "" {
+		return nil
+	}
+
+	currentVersion, err := version.NewSemver(current)
+	if err != nil {
+		return fmt.Errorf("invalid current pom version %q: %w", current, err)
+	}
+	if !currentVersion.LessThan(releaseVersion) {
+		return fmt.Errorf("release version %s must be greater than current pom version %s", release, current)
+	}
+
+	return nil
+}
+
+// Checks the write access to the remote with a dry-run push of HEAD to a new branch. The branch name must not exist
+// on the remote, otherwise git skips the push as up-to-date or rejects it as non-fast-forward without checking the
+// access. Nothing gets created on the remote because of the dry-run.
+func checkPushAccess(module project.Module, gitRemote string) (CheckStatus, string) {
+	branch := fmt.Sprintf("graylog-project-preflight-%d", time.Now().UnixNano())
+
+	err := git.ExecInPath(module.Path, "push", "--dry-run", "--no-verify", gitRemote, "HEAD:refs/heads/"+branch)
+	if err != nil {
+		return CheckFailed, fmt.Sprintf("dry-run push to %s failed: %s", gitRemote, err)
+	}
+
+	return CheckOK, fmt.Sprintf("dry-run push to %s succeeded", gitRemote)
+}
+
+func checkChangelogLint(module project.Module, gitRemote string) (CheckStatus, string) {
+	path := filepath.Join(module.Path, "changelog", "unreleased")
+	if !utils.FileExists(path) {
+		return CheckOK, "no unreleased changelog entries"
+	}
+
+	if err := changelog.LintPaths([]string{path}, changelog.LintOptions{}); err != nil {
+		return CheckFailed, err.Error()
+	}
+
+	return CheckOK, "changelog entries are valid"
+}
+
+func checkJDK(p project.Project) (CheckStatus, string) {
+	if p.JVMVersion == 0 {
+		return CheckWarning, "manifest doesn't specify a JVM version"
+	}
+
+	javaBin := "java"
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+		javaBin = filepath.Join(javaHome, "bin", "java")
+	}
+
+	output, err := exec.Command(javaBin, "-version").CombinedOutput()
+	if err != nil {
+		return CheckFailed, fmt.Sprintf("couldn't run %s -version: %s", javaBin, err)
+	}
+
+	major, err := parseJavaVersion(string(output))
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if major != p.JVMVersion {
+		return CheckFailed, fmt.Sprintf("JDK %d found but the manifest requires JDK %d", major, p.JVMVersion)
+	}
+
+	return CheckOK, fmt.Sprintf("JDK %d", major)
+}
+
+var javaVersionPattern = regexp.MustCompile(`version "(\d+)(?:\.(\d+))?`)
+
+// Returns the major version from the given "java -version" output.
+func parseJavaVersion(output string) (int, error) {
+	match := javaVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("couldn't parse java version from output: %q", strings.TrimSpace(output))
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	// Java 8 and earlier use the "1.x" versioning scheme
+	if major == 1 && match[2] != "" {
+		major, _ = strconv.Atoi(match[2])
+	}
+
+	return major, nil
+}
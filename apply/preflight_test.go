@@ -0,0 +1,70 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	for output, expected := range map[string]int{
+		`openjdk version "17.0.12" 2024-07-16`:                            17,
+		`openjdk version "21" 2023-09-19`:                                 21,
+		`java version "1.8.0_412"`:                                        8,
+		"Picked up JAVA_TOOL_OPTIONS: -Xmx1g\nopenjdk version \"11.0.2\"": 11,
+	} {
+		major, err := parseJavaVersion(output)
+		require.NoError(t, err, output)
+		assert.Equal(t, expected, major, output)
+	}
+
+	_, err := parseJavaVersion("command not found")
+	assert.Error(t, err)
+}
+
+func TestCheckVersionOrder(t *testing.T) {
+	assert.NoError(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.2.0", "6.2.1-SNAPSHOT"))
+	assert.NoError(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.2.0-rc.1", "6.2.0-SNAPSHOT"))
+	assert.NoError(t, checkVersionOrder("", "6.2.0", "6.3.0-SNAPSHOT"))
+
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.1.3", "6.1.4-SNAPSHOT"), "must be greater than current pom version")
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.2.0", "6.2.0-SNAPSHOT"), "must be greater than release version")
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-rc.1", "6.2.0-rc.2", "6.1.0-SNAPSHOT"), "must not be lower than release version")
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.2", "6.3.0-SNAPSHOT"), "invalid release version")
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-SNAPSHOT", "6.2.0", "6.3"), "invalid next version")
+	assert.ErrorContains(t, checkVersionOrder("6.2.0-SNAPSHOT", "six", "6.3.0-SNAPSHOT"), "invalid release version")
+	assert.ErrorContains(t, checkVersionOrder("${revision}", "6.2.0", "6.3.0-SNAPSHOT"), "invalid current pom version")
+}
+
+func TestCheckPushAccess(t *testing.T) {
+	path, remote := newTestRepo(t, "graylog2-server")
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pom.xml"), []byte("<project/>\n"), 0644))
+	runGit(t, path, "add", "pom.xml")
+	runGit(t, path, "commit", "--quiet", "--message", "initial")
+	runGit(t, path, "push", "--quiet", "origin", "main")
+
+	module := project.Module{Name: "graylog-server", Path: path}
+
+	status, message := checkPushAccess(module, "origin")
+	assert.Equal(t, CheckOK, status, message)
+	assert.Equal(t, "", runGit(t, remote, "branch", "--list", "graylog-project-preflight-*"), "dry-run must not create a branch")
+
+	runGit(t, path, "remote", "add", "broken", filepath.Join(t.TempDir(), "missing.git"))
+	status, message = checkPushAccess(module, "broken")
+	assert.Equal(t, CheckFailed, status)
+	assert.Contains(t, message, "dry-run push to broken failed")
+}
+
+func TestRunPreflightMissingRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graylog-server")
+	proj := project.Project{Modules: []project.Module{{Name: "graylog-server", Path: path}}}
+
+	results, err := RunPreflight(proj, PreflightOptions{Skip: []string{CheckJDK}})
+	require.NoError(t, err)
+	assert.Equal(t, []CheckResult{{Check: CheckRepository, Module: "graylog-server", Status: CheckWarning,
+		Message: "repository " + path + " doesn't exist yet, skipping module checks"}}, results)
+}
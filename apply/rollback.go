@@ -0,0 +1,318 @@
+package apply
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+)
+
+// The maximum number of commits we inspect when looking for release commits without a recorded origin.
+const rollbackMaxCommits = 100
+
+type RollbackKind string
+
+const (
+	RollbackDeleteRemoteTag    RollbackKind = "delete-remote-tag"
+	RollbackDeleteRemoteBranch RollbackKind = "delete-remote-branch"
+	RollbackResetBranch        RollbackKind = "reset-branch"
+	RollbackRevertBranch       RollbackKind = "revert-branch"
+	RollbackResetLocalBranch   RollbackKind = "reset-local-branch"
+	RollbackDeleteLocalTag     RollbackKind = "delete-local-tag"
+	RollbackDeleteLocalBranch  RollbackKind = "delete-local-branch"
+)
+
+// RollbackAction is a single change that undoes something the release created.
+type RollbackAction struct {
+	Module string       `json:"module"`
+	Path   string       `json:"path"`
+	Remote string       `json:"remote"`
+	Kind   RollbackKind `json:"kind"`
+	Ref    string       `json:"ref"`
+	// The commit the ref currently points to.
+	Commit string `json:"commit,omitempty"`
+	// The commit a branch gets reset to or the branch to switch to before deleting a local branch.
+	Target string `json:"target,omitempty"`
+}
+
+func (a RollbackAction) String() string {
+	switch a.Kind {
+	case RollbackResetBranch:
+		return fmt.Sprintf("reset branch %s from %s to %s", a.Ref, shortCommit(a.Commit), shortCommit(a.Target))
+	case RollbackResetLocalBranch:
+		return fmt.Sprintf("reset local branch %s from %s to %s (discards local changes)", a.Ref, shortCommit(a.Commit), shortCommit(a.Target))
+	case RollbackRevertBranch:
+		return fmt.Sprintf("revert commits %s..%s on branch %s", shortCommit(a.Target), shortCommit(a.Commit), a.Ref)
+	default:
+		return fmt.Sprintf("%s %s (%s)", strings.ReplaceAll(string(a.Kind), "-", " "), a.Ref, shortCommit(a.Commit))
+	}
+}
+
+// RollbackResult is the audit record of an executed rollback action.
+type RollbackResult struct {
+	Action RollbackAction `json:"action"`
+	Error  error          `json:"-"`
+}
+
+func (r RollbackResult) Failed() bool {
+	return r.Error != nil
+}
+
+type RollbackOptions struct {
+	// The remote of the release. Defaults to DefaultRemote.
+	Remote string
+	// Revert the release commits with new commits instead of force-pushing the branch to the pre-release commit.
+	Revert bool
+}
+
+// PlanRollback returns the actions that are needed to undo the release of the given project. The pre-release
+// commit of each module is taken from the apply state if available. (state can be nil) Otherwise, the release
+// commits are detected by their commit messages.
+func PlanRollback(p project.Project, state *State, options RollbackOptions) ([]RollbackAction, error) {
+	actions := make([]RollbackAction, 0)
+	var planErr error
+
+	ForEachModule(p, false, func(module project.Module) {
+		if planErr != nil {
+			return
+		}
+		moduleActions, err := planModuleRollback(module, state, options)
+		if err != nil {
+			planErr = fmt.Errorf("module %s: %w", module.Name, err)
+			return
+		}
+		actions = append(actions, moduleActions...)
+	})
+
+	return actions, planErr
+}
+
+func planModuleRollback(module project.Module, state *State, options RollbackOptions) ([]RollbackAction, error) {
+	gitRemote := remoteOrDefault(options.Remote)
+	actions := make([]RollbackAction, 0)
+	action := func(kind RollbackKind, ref string, commit string, target string) {
+		actions = append(actions, RollbackAction{
+			Module: module.Name,
+			Path:   module.Path,
+			Remote: gitRemote,
+			Kind:   kind,
+			Ref:    ref,
+			Commit: commit,
+			Target: target,
+		})
+	}
+
+	tag := "refs/tags/" + module.Revision
+	remoteTag, err := git.RemoteRef(module.Path, gitRemote, tag)
+	if err != nil {
+		return nil, err
+	}
+	if remoteTag != "" {
+		action(RollbackDeleteRemoteTag, module.Revision, remoteTag, "")
+	}
+
+	newBranch := module.ApplyNewBranch()
+	if newBranch != "" {
+		remoteBranch, err := git.RemoteRef(module.Path, gitRemote, "refs/heads/"+newBranch)
+		if err != nil {
+			return nil, err
+		}
+		if remoteBranch != "" {
+			action(RollbackDeleteRemoteBranch, newBranch, remoteBranch, "")
+		}
+	}
+
+	sourceBranch := module.ApplyFromRevision()
+	remoteSource, err := git.RemoteRef(module.Path, gitRemote, "refs/heads/"+sourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	if remoteSource != "" {
+		if err := git.ExecInPath(module.Path, "fetch", "--quiet", gitRemote, sourceBranch); err != nil {
+			return nil, err
+		}
+
+		origin, err := rollbackOrigin(module, state, remoteSource)
+		if err != nil {
+			return nil, err
+		}
+		if origin != "" && origin != remoteSource {
+			if !isAncestor(module.Path, origin, remoteSource) {
+				return nil, fmt.Errorf("pre-release commit %s is not an ancestor of %s/%s (%s), refusing to roll back", shortCommit(origin), gitRemote, sourceBranch, shortCommit(remoteSource))
+			}
+
+			if options.Revert {
+				action(RollbackRevertBranch, sourceBranch, remoteSource, origin)
+			} else {
+				action(RollbackResetBranch, sourceBranch, remoteSource, origin)
+			}
+		} else {
+			// The release commits might only exist locally. This relies on the step order of the apply-manifest
+			// command (see applyManifestSteps in the cmd package) which pushes the branches and tags in the "push"
+			// step after all release commits have been created. A release that failed before that step hasn't
+			// pushed anything.
+			localSource, _, _ := git.ResolveRef(module.Path, "refs/heads/"+sourceBranch)
+			if localSource != "" && localSource != remoteSource && isAncestor(module.Path, remoteSource, localSource) {
+				action(RollbackResetLocalBranch, sourceBranch, localSource, remoteSource)
+			}
+		}
+	} else {
+		logger.Info("Module %s: branch %s doesn't exist on remote %s, skipping branch rollback", module.Name, sourceBranch, gitRemote)
+	}
+
+	err = utils.InDirectoryE(module.Path, func() error {
+		if localTag, err := git.GitValueE("rev-parse", "--verify", "--quiet", tag); err == nil && localTag != "" {
+			action(RollbackDeleteLocalTag, module.Revision, localTag, "")
+		}
+		if newBranch != "" && newBranch != sourceBranch {
+			if localBranch, err := git.GitValueE("rev-parse", "--verify", "--quiet", "refs/heads/"+newBranch); err == nil && localBranch != "" {
+				action(RollbackDeleteLocalBranch, newBranch, localBranch, sourceBranch)
+			}
+		}
+		return nil
+	})
+
+	return actions, err
+}
+
+func isAncestor(path string, ancestor string, commit string) bool {
+	return utils.InDirectoryE(path, func() error {
+		_, err := git.GitValueE("merge-base", "--is-ancestor", ancestor, commit)
+		return err
+	}) == nil
+}
+
+// Returns the pre-release commit of the given module. An empty string is returned if the module doesn't have any
+// release commits.
+func rollbackOrigin(module project.Module, state *State, head string) (string, error) {
+	if state != nil {
+		if origin, ok := state.Origins[module.Path]; ok {
+			return origin.Commit, nil
+		}
+	}
+
+	var output string
+	err := utils.InDirectoryE(module.Path, func() error {
+		var err error
+		output, err = git.GitValueE("log", "--first-parent", fmt.Sprintf("--max-count=%d", rollbackMaxCommits), "--format=%H %s", head)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't read commit log: %w", err)
+	}
+
+	commits := make([]rollbackCommit, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if id, subject, ok := strings.Cut(line, " "); ok {
+			commits = append(commits, rollbackCommit{ID: id, Subject: subject})
+		}
+	}
+
+	return releaseCommitBoundary(commits, module.Name, module.Revision, module.ApplyNewVersion()), nil
+}
+
+type rollbackCommit struct {
+	ID      string
+	Subject string
+}
+
+// Returns the first commit in the given list (newest first) that hasn't been created by the release of the given
+// version. An empty string is returned if the list doesn't start with release commits.
+//
+// The release creates the commits in two phases. The release phase commits the package.json and pom.xml release
+// versions. The development phase commits the next versions. Changelog rotations can happen in both phases.
+// Development phase commits must not follow release phase commits, otherwise we would walk into the previous release.
+func releaseCommitBoundary(commits []rollbackCommit, name string, revision string, newVersion string) string {
+	q := regexp.QuoteMeta
+	changelogCommit := regexp.MustCompile(fmt.Sprintf(`^Release changelog for version %s$`, q(revision)))
+	releasePhase := regexp.MustCompile(fmt.Sprintf(
+		`^(Bump package\.json version to %s|%s)$`,
+		q(revision), q(releaseCommitMessage(name, revision)),
+	))
+	developmentPhase := regexp.MustCompile(fmt.Sprintf(
+		`^(Bump package\.json version to %s|%s)$`,
+		q(newVersion), q(developmentCommitMessage(name)),
+	))
+
+	inReleasePhase := false
+	for _, commit := range commits {
+		switch {
+		case changelogCommit.MatchString(commit.Subject):
+		case releasePhase.MatchString(commit.Subject):
+			inReleasePhase = true
+		case !inReleasePhase && developmentPhase.MatchString(commit.Subject):
+		default:
+			if !inReleasePhase {
+				return ""
+			}
+			return commit.ID
+		}
+	}
+
+	return ""
+}
+
+// ExecuteRollback runs the given actions in order and returns the audit records. Failed actions don't stop the
+// rollback so that as much as possible gets cleaned up.
+func ExecuteRollback(actions []RollbackAction) []RollbackResult {
+	results := make([]RollbackResult, 0, len(actions))
+
+	for _, action := range actions {
+		logger.Info("[%s] %s", action.Module, action)
+
+		commandList, err := rollbackGitCommands(action)
+		for _, commands := range commandList {
+			if err = git.ExecInPath(action.Path, commands...); err != nil {
+				break
+			}
+		}
+		results = append(results, RollbackResult{Action: action, Error: err})
+	}
+
+	return results
+}
+
+func rollbackGitCommands(action RollbackAction) ([][]string, error) {
+	switch action.Kind {
+	case RollbackDeleteRemoteTag:
+		return [][]string{{"push", action.Remote, "--delete", "refs/tags/" + action.Ref}}, nil
+	case RollbackDeleteRemoteBranch:
+		return [][]string{{"push", action.Remote, "--delete", "refs/heads/" + action.Ref}}, nil
+	case RollbackResetBranch:
+		return [][]string{
+			{"push", "--force-with-lease=refs/heads/" + action.Ref + ":" + action.Commit, action.Remote, action.Target + ":refs/heads/" + action.Ref},
+			{"checkout", "--quiet", "-B", action.Ref, action.Target},
+		}, nil
+	case RollbackResetLocalBranch:
+		return [][]string{{"checkout", "--quiet", "--force", "-B", action.Ref, action.Target}}, nil
+	case RollbackRevertBranch:
+		return [][]string{
+			{"checkout", "--quiet", "-B", action.Ref, action.Commit},
+			// Revert all release commits with a single commit
+			{"revert", "--no-commit", action.Target + ".." + action.Commit},
+			{"commit", "--allow-empty", "--message", fmt.Sprintf("Revert release commits %s..%s", shortCommit(action.Target), shortCommit(action.Commit))},
+			{"push", action.Remote, action.Ref},
+		}, nil
+	case RollbackDeleteLocalTag:
+		return [][]string{{"tag", "--delete", action.Ref}}, nil
+	case RollbackDeleteLocalBranch:
+		// Make sure the branch isn't checked out anymore
+		return [][]string{
+			{"checkout", "--quiet", action.Target},
+			{"branch", "--delete", "--force", action.Ref},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rollback action: %s", action.Kind)
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 10 {
+		return commit[:10]
+	}
+	return commit
+}
@@ -0,0 +1,47 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseCommitBoundary(t *testing.T) {
+	commits := []rollbackCommit{
+		{ID: "8", Subject: "Release changelog for version 6.2.0"},
+		{ID: "7", Subject: "[graylog2-server] prepare for next development iteration"},
+		{ID: "6", Subject: "Bump package.json version to 6.3.0-SNAPSHOT"},
+		{ID: "5", Subject: "[graylog2-server] prepare release 6.2.0"},
+		{ID: "4", Subject: "Bump package.json version to 6.2.0"},
+		{ID: "3", Subject: "Release changelog for version 6.2.0"},
+		{ID: "2", Subject: "Add feature"},
+		{ID: "1", Subject: "[graylog2-server] prepare for next development iteration"},
+	}
+
+	assert.Equal(t, "2", releaseCommitBoundary(commits, "graylog2-server", "6.2.0", "6.3.0-SNAPSHOT"))
+
+	// The release commits are done but nothing has been pushed after the release commit
+	assert.Equal(t, "2", releaseCommitBoundary(commits[3:], "graylog2-server", "6.2.0", "6.3.0-SNAPSHOT"))
+
+	// The development iteration commit of the previous release must not be included
+	previous := []rollbackCommit{
+		{ID: "3", Subject: "[graylog2-server] prepare release 6.2.0"},
+		{ID: "2", Subject: "[graylog2-server] prepare for next development iteration"},
+		{ID: "1", Subject: "[graylog2-server] prepare release 6.1.0"},
+	}
+	assert.Equal(t, "2", releaseCommitBoundary(previous, "graylog2-server", "6.2.0", "6.3.0-SNAPSHOT"))
+
+	// No release commits
+	assert.Empty(t, releaseCommitBoundary(commits[6:], "graylog2-server", "6.2.0", "6.3.0-SNAPSHOT"))
+	// Other module
+	assert.Empty(t, releaseCommitBoundary(commits, "graylog-plugin-enterprise", "6.2.0", "6.3.0-SNAPSHOT"))
+}
+
+func TestExecuteRollbackUnknownAction(t *testing.T) {
+	results := ExecuteRollback([]RollbackAction{{Module: "a", Path: t.TempDir(), Kind: "foo", Ref: "main"}})
+
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Failed())
+	assert.ErrorContains(t, results[0].Error, "unknown rollback action: foo")
+}
@@ -0,0 +1,312 @@
+package apply
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/pom"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+)
+
+const sandboxDeployRepositoryID = "sandbox"
+
+// A Sandbox contains local bare repositories that act as the remotes for a release rehearsal. The bare repositories
+// are created from the workspace repositories, so the release runs against the current state of the workspace
+// without pushing anything to the real remotes.
+type Sandbox struct {
+	Dir     string
+	Remotes []*SandboxRemote
+}
+
+// SandboxRemote is the bare repository of a single module.
+type SandboxRemote struct {
+	Module string `json:"module"`
+	Path   string `json:"path"`
+	// The module path relative to the repository root. (for modules with a "path" in the manifest)
+	modulePath string
+	// The package.json files of the module and its submodules, relative to the repository root.
+	packageJsonFiles []string
+	// The refs of the bare repository before the release.
+	refs map[string]string
+}
+
+type SandboxReport struct {
+	Modules []SandboxModuleReport `json:"modules"`
+}
+
+type SandboxModuleReport struct {
+	Module   string       `json:"module"`
+	Remote   string       `json:"remote"`
+	Tags     []SandboxRef `json:"tags"`
+	Branches []SandboxRef `json:"branches"`
+}
+
+// SandboxRef is a tag or branch that has been created or updated in a sandbox remote.
+type SandboxRef struct {
+	Name                string            `json:"name"`
+	Commit              string            `json:"commit"`
+	New                 bool              `json:"new"`
+	PomVersion          string            `json:"pom_version,omitempty"`
+	PackageJsonVersions map[string]string `json:"package_json_versions,omitempty"`
+}
+
+// NewSandbox creates the sandbox directories in the given directory.
+func NewSandbox(dir string) (*Sandbox, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get absolute sandbox path for %s: %w", dir, err)
+	}
+
+	sandbox := &Sandbox{Dir: absDir}
+	for _, path := range []string{sandbox.RemotesDir(), sandbox.RepositoriesDir(), sandbox.MavenRepositoryDir()} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, fmt.Errorf("couldn't create sandbox directory %s: %w", path, err)
+		}
+	}
+
+	return sandbox, nil
+}
+
+// RemotesDir returns the directory with the bare repositories.
+func (s *Sandbox) RemotesDir() string {
+	return filepath.Join(s.Dir, "remotes")
+}
+
+// RepositoriesDir returns the repository root for the module checkouts of the release.
+func (s *Sandbox) RepositoriesDir() string {
+	return filepath.Join(s.Dir, "repos")
+}
+
+// ProjectDir returns the directory for the graylog-project checkout of the release.
+func (s *Sandbox) ProjectDir() string {
+	return filepath.Join(s.Dir, "project")
+}
+
+// MavenRepositoryDir returns the local file repository that receives the deployed Maven artifacts.
+func (s *Sandbox) MavenRepositoryDir() string {
+	return filepath.Join(s.Dir, "maven-repo")
+}
+
+// DeployRepository returns the Maven deployment repository in "id::url" format.
+// (see the "altDeploymentRepository" option of the maven-deploy-plugin)
+func (s *Sandbox) DeployRepository() string {
+	return sandboxDeployRepositoryID + "::file://" + filepath.ToSlash(s.MavenRepositoryDir())
+}
+
+// AddModule creates a bare repository from the workspace repository of the given module.
+// The branches of the workspace "origin" remote become the branches of the bare repository. Local branches take
+// precedence, so unpushed commits are part of the rehearsal.
+func (s *Sandbox) AddModule(module project.Module) (*SandboxRemote, error) {
+	toplevel, err := repositoryToplevel(module.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := &SandboxRemote{
+		Module: module.Name,
+		Path:   filepath.Join(s.RemotesDir(), filepath.Base(toplevel)+".git"),
+	}
+	if remote.modulePath, err = repositoryRelativePath(toplevel, module.Path); err != nil {
+		return nil, fmt.Errorf("couldn't get module path of %s: %w", module.Name, err)
+	}
+	for _, m := range append([]project.Module{module}, module.Submodules...) {
+		rel, err := repositoryRelativePath(toplevel, m.Path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get module path of %s: %w", m.Name, err)
+		}
+		for _, file := range m.VersionPackageJsonFiles() {
+			remote.packageJsonFiles = append(remote.packageJsonFiles, filepath.ToSlash(filepath.Join(rel, file)))
+		}
+	}
+
+	if utils.FileExists(remote.Path) {
+		return nil, fmt.Errorf("sandbox remote %s already exists", remote.Path)
+	}
+	if err := git.Exec("init", "--quiet", "--bare", remote.Path); err != nil {
+		return nil, err
+	}
+
+	refspecs, err := sandboxRefspecs(toplevel)
+	if err != nil {
+		return nil, err
+	}
+	if err := git.ExecInPath(toplevel, append([]string{"push", "--quiet", "--no-verify", remote.Path}, refspecs...)...); err != nil {
+		return nil, err
+	}
+
+	// The default branch of the bare repository is the checked out branch of the workspace
+	if branch, err := git.CurrentBranch(toplevel); err == nil {
+		if err := git.ExecInPath(remote.Path, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+			return nil, err
+		}
+	}
+
+	if remote.refs, err = listRefs(remote.Path); err != nil {
+		return nil, err
+	}
+
+	s.Remotes = append(s.Remotes, remote)
+
+	return remote, nil
+}
+
+// Report returns the tags and branches that have been created or updated in the sandbox remotes, together with
+// the pom.xml and package.json versions of these refs.
+func (s *Sandbox) Report() (SandboxReport, error) {
+	report := SandboxReport{Modules: make([]SandboxModuleReport, 0, len(s.Remotes))}
+
+	for _, remote := range s.Remotes {
+		refs, err := listRefs(remote.Path)
+		if err != nil {
+			return report, err
+		}
+
+		moduleReport := SandboxModuleReport{
+			Module:   remote.Module,
+			Remote:   remote.Path,
+			Tags:     make([]SandboxRef, 0),
+			Branches: make([]SandboxRef, 0),
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(refs)) {
+			commit := refs[name]
+			previous, existed := remote.refs[name]
+			if existed && previous == commit {
+				continue
+			}
+
+			ref := SandboxRef{Commit: commit, New: !existed}
+			if ref.PomVersion, err = remote.pomVersion(name); err != nil {
+				return report, err
+			}
+			ref.PackageJsonVersions = remote.packageJsonVersions(name)
+
+			if tag, ok := strings.CutPrefix(name, "refs/tags/"); ok {
+				ref.Name = tag
+				moduleReport.Tags = append(moduleReport.Tags, ref)
+			} else if branch, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+				ref.Name = branch
+				moduleReport.Branches = append(moduleReport.Branches, ref)
+			}
+		}
+
+		report.Modules = append(report.Modules, moduleReport)
+	}
+
+	return report, nil
+}
+
+func (remote *SandboxRemote) pomVersion(ref string) (string, error) {
+	file := filepath.ToSlash(filepath.Join(remote.modulePath, "pom.xml"))
+	content, err := git.ShowFile(remote.Path, ref, file)
+	if err != nil {
+		// Not every branch needs to be a Maven module
+		return "", nil
+	}
+
+	doc, err := pom.ParseDocument(content)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse %s in %s of %s: %w", file, ref, remote.Path, err)
+	}
+	return doc.Version(), nil
+}
+
+func (remote *SandboxRemote) packageJsonVersions(ref string) map[string]string {
+	versions := make(map[string]string)
+	for _, file := range remote.packageJsonFiles {
+		content, err := git.ShowFile(remote.Path, ref, file)
+		if err != nil {
+			continue
+		}
+		if version, ok := utils.ParsePackageJsonVersion(content); ok {
+			versions[file] = version
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions
+}
+
+func repositoryToplevel(path string) (string, error) {
+	var toplevel string
+	err := utils.InDirectoryE(path, func() error {
+		var err error
+		toplevel, err = git.ToplevelPath()
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("couldn't find git repository of %s: %w", path, err)
+	}
+	return toplevel, nil
+}
+
+// Returns the given path relative to the given repository root. Symlinks are resolved because git returns the
+// resolved repository root.
+func repositoryRelativePath(toplevel string, path string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return filepath.Rel(toplevel, path)
+}
+
+// Returns the refspecs to push the workspace branches and tags into a sandbox remote.
+func sandboxRefspecs(path string) ([]string, error) {
+	var output string
+	err := utils.InDirectoryE(path, func() error {
+		var err error
+		output, err = git.GitValueE("for-each-ref", "--format=%(refname)", "refs/remotes/"+DefaultRemote, "refs/heads")
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list refs in %s: %w", path, err)
+	}
+
+	sources := make(map[string]string)
+	for _, ref := range strings.Fields(output) {
+		if branch, ok := strings.CutPrefix(ref, "refs/remotes/"+DefaultRemote+"/"); ok && branch != "HEAD" {
+			if _, exists := sources[branch]; !exists {
+				sources[branch] = ref
+			}
+		}
+	}
+	for _, ref := range strings.Fields(output) {
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			sources[branch] = ref
+		}
+	}
+
+	refspecs := []string{"+refs/tags/*:refs/tags/*"}
+	for _, branch := range slices.Sorted(maps.Keys(sources)) {
+		refspecs = append(refspecs, "+"+sources[branch]+":refs/heads/"+branch)
+	}
+
+	return refspecs, nil
+}
+
+// Returns the commit IDs of all branches and tags in the given repository. Annotated tags are peeled to their commit.
+func listRefs(path string) (map[string]string, error) {
+	var output string
+	err := utils.InDirectoryE(path, func() error {
+		var err error
+		output, err = git.GitValueE("for-each-ref", "--format=%(refname) %(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)", "refs/heads", "refs/tags")
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list refs in %s: %w", path, err)
+	}
+
+	refs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if name, commit, ok := strings.Cut(line, " "); ok {
+			refs[name] = commit
+		}
+	}
+	return refs, nil
+}
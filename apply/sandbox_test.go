@@ -0,0 +1,110 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sandboxTestPom = `<project>
+    <modelVersion>4.0.0</modelVersion>
+    <groupId>org.graylog</groupId>
+    <artifactId>graylog-server</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+</project>
+`
+
+const sandboxTestPackageJson = `{
+  "name": "graylog-web-interface",
+  "version": "6.2.0-SNAPSHOT",
+  "private": true
+}
+`
+
+// Creates a sandbox remote, pushes a release to it and checks the report.
+func TestSandboxRelease(t *testing.T) {
+	// The workspace repository has an "origin" remote and an unpushed local commit in the "main" branch
+	workspace, upstream := newTestRepo(t, "graylog2-server")
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "pom.xml"), []byte(sandboxTestPom), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(sandboxTestPackageJson), 0644))
+	runGit(t, workspace, "add", "pom.xml", "package.json")
+	runGit(t, workspace, "commit", "--quiet", "--message", "initial")
+	runGit(t, workspace, "tag", "6.1.0")
+	runGit(t, workspace, "push", "--quiet", "origin", "main", "6.1.0")
+	runGit(t, workspace, "branch", "6.1", "main")
+	runGit(t, workspace, "push", "--quiet", "origin", "6.1")
+	runGit(t, workspace, "branch", "--delete", "6.1")
+	runGit(t, workspace, "commit", "--quiet", "--allow-empty", "--message", "unpushed")
+
+	sandbox, err := NewSandbox(t.TempDir())
+	require.NoError(t, err)
+
+	remote, err := sandbox.AddModule(project.Module{Name: "graylog-server", Path: workspace})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(sandbox.RemotesDir(), "graylog2-server.git"), remote.Path)
+	assert.Equal(t, "sandbox::file://"+filepath.ToSlash(filepath.Join(sandbox.Dir, "maven-repo")), sandbox.DeployRepository())
+
+	_, err = sandbox.AddModule(project.Module{Name: "graylog-server", Path: workspace})
+	assert.ErrorContains(t, err, "already exists")
+
+	// Branches from the origin remote and local branches are in the sandbox remote
+	assert.Equal(t, "unpushed\ninitial\n", runGit(t, remote.Path, "log", "--format=%s", "main"))
+	assert.Equal(t, "refs/heads/6.1\nrefs/heads/main\nrefs/tags/6.1.0\n", runGit(t, remote.Path, "for-each-ref", "--format=%(refname)"))
+
+	// Nothing changed yet
+	report, err := sandbox.Report()
+	require.NoError(t, err)
+	require.Len(t, report.Modules, 1)
+	assert.Empty(t, report.Modules[0].Tags)
+	assert.Empty(t, report.Modules[0].Branches)
+
+	// Push a release from a checkout of the sandbox remote. The complete release steps are tested in the cmd package.
+	checkout := filepath.Join(sandbox.RepositoriesDir(), "graylog2-server")
+	runGit(t, ".", "clone", "--quiet", remote.Path, checkout)
+	writeVersions := func(version string) {
+		require.NoError(t, os.WriteFile(filepath.Join(checkout, "pom.xml"), []byte(strings.Replace(sandboxTestPom, "6.2.0-SNAPSHOT", version, 1)), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(checkout, "package.json"), []byte(strings.Replace(sandboxTestPackageJson, "6.2.0-SNAPSHOT", version, 1)), 0644))
+		runGit(t, checkout, "commit", "--quiet", "--all", "--message", "version "+version)
+	}
+	writeVersions("6.2.0")
+	runGit(t, checkout, "tag", "6.2.0")
+	writeVersions("6.2.1-SNAPSHOT")
+	runGit(t, checkout, "branch", "6.2")
+	runGit(t, checkout, "push", "--quiet", "origin", "main", "6.2", "6.2.0")
+
+	report, err = sandbox.Report()
+	require.NoError(t, err)
+	require.Len(t, report.Modules, 1)
+
+	moduleReport := report.Modules[0]
+	assert.Equal(t, "graylog-server", moduleReport.Module)
+	assert.Equal(t, remote.Path, moduleReport.Remote)
+
+	require.Len(t, moduleReport.Tags, 1)
+	assert.Equal(t, "6.2.0", moduleReport.Tags[0].Name)
+	assert.True(t, moduleReport.Tags[0].New)
+	assert.Equal(t, "6.2.0", moduleReport.Tags[0].PomVersion)
+	assert.Equal(t, map[string]string{"package.json": "6.2.0"}, moduleReport.Tags[0].PackageJsonVersions)
+	assert.Equal(t, strings.TrimSpace(runGit(t, remote.Path, "rev-parse", "6.2.0^{commit}")), moduleReport.Tags[0].Commit)
+
+	require.Len(t, moduleReport.Branches, 2)
+	assert.Equal(t, "6.2", moduleReport.Branches[0].Name)
+	assert.True(t, moduleReport.Branches[0].New)
+	assert.Equal(t, "6.2.1-SNAPSHOT", moduleReport.Branches[0].PomVersion)
+	assert.Equal(t, "main", moduleReport.Branches[1].Name)
+	assert.False(t, moduleReport.Branches[1].New)
+	assert.Equal(t, "6.2.1-SNAPSHOT", moduleReport.Branches[1].PomVersion)
+	assert.Equal(t, map[string]string{"package.json": "6.2.1-SNAPSHOT"}, moduleReport.Branches[1].PackageJsonVersions)
+
+	// The workspace and its origin remote are untouched
+	assert.Equal(t, "refs/heads/6.1\nrefs/heads/main\nrefs/tags/6.1.0\n", runGit(t, upstream, "for-each-ref", "--format=%(refname)"))
+	version, err := utils.PackageJsonVersion(filepath.Join(workspace, "package.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0-SNAPSHOT", version)
+}
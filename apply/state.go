@@ -0,0 +1,247 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/google/renameio/v2"
+)
+
+const stateFileSuffix = ".apply-state.json"
+
+// State is the persisted progress of an apply-manifest run.
+type State struct {
+	filename      string
+	ManifestFiles []string              `json:"manifest_files"`
+	StartedAt     time.Time             `json:"started_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	Steps         map[string]*StepState `json:"steps"`
+	// The repository state of each module before the release changed anything.
+	Origins map[string]ModuleState `json:"origins,omitempty"`
+}
+
+type StepState struct {
+	Completed   bool                   `json:"completed"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	Modules     map[string]ModuleState `json:"modules,omitempty"`
+}
+
+// ModuleState records the repository state of a module after a step has been completed for it.
+type ModuleState struct {
+	Name        string    `json:"name"`
+	Branch      string    `json:"branch"`
+	Commit      string    `json:"commit"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// StateFilename returns the state file name for the given apply manifest. The state file is stored next to
+// the manifest.
+func StateFilename(manifestFile string) string {
+	return strings.TrimSuffix(manifestFile, filepath.Ext(manifestFile)) + stateFileSuffix
+}
+
+func NewState(filename string, manifestFiles []string) *State {
+	now := time.Now()
+	return &State{
+		filename:      filename,
+		ManifestFiles: manifestFiles,
+		StartedAt:     now,
+		UpdatedAt:     now,
+		Steps:         make(map[string]*StepState),
+		Origins:       make(map[string]ModuleState),
+	}
+}
+
+func ReadState(filename string) (*State, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read apply state %s: %w", filename, err)
+	}
+
+	var state State
+	if err := json.Unmarshal(buf, &state); err != nil {
+		return nil, fmt.Errorf("couldn't parse apply state %s: %w", filename, err)
+	}
+	state.filename = filename
+	if state.Steps == nil {
+		state.Steps = make(map[string]*StepState)
+	}
+	if state.Origins == nil {
+		state.Origins = make(map[string]ModuleState)
+	}
+
+	return &state, nil
+}
+
+func (s *State) Filename() string {
+	return s.filename
+}
+
+// Detach disables persisting the state. Used for dry-runs that should show what would happen on resume.
+func (s *State) Detach() {
+	s.filename = ""
+}
+
+func (s *State) Save() error {
+	if s.filename == "" {
+		return nil
+	}
+
+	s.UpdatedAt = time.Now()
+
+	buf, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't serialize apply state: %w", err)
+	}
+	if err := renameio.WriteFile(s.filename, append(buf, '\n'), 0644); err != nil {
+		return fmt.Errorf("couldn't write apply state %s: %w", s.filename, err)
+	}
+
+	return nil
+}
+
+func (s *State) step(name string) *StepState {
+	if _, ok := s.Steps[name]; !ok {
+		s.Steps[name] = &StepState{Modules: make(map[string]ModuleState)}
+	}
+	if s.Steps[name].Modules == nil {
+		s.Steps[name].Modules = make(map[string]ModuleState)
+	}
+	return s.Steps[name]
+}
+
+func (s *State) IsStepCompleted(name string) bool {
+	step, ok := s.Steps[name]
+	return ok && step.Completed
+}
+
+func (s *State) IsModuleCompleted(step string, path string) bool {
+	stepState, ok := s.Steps[step]
+	if !ok {
+		return false
+	}
+	_, ok = stepState.Modules[path]
+	return ok
+}
+
+func (s *State) CompleteStep(name string) error {
+	now := time.Now()
+	step := s.step(name)
+	step.Completed = true
+	step.CompletedAt = &now
+	return s.Save()
+}
+
+func (s *State) ResetStep(name string) error {
+	delete(s.Steps, name)
+	return s.Save()
+}
+
+// CompleteModule records the current repository state of the module at the given path for the given step.
+func (s *State) CompleteModule(step string, name string, path string) error {
+	branch, commit, err := repositoryState(path)
+	if err != nil {
+		return err
+	}
+
+	s.step(step).Modules[path] = ModuleState{
+		Name:        name,
+		Branch:      branch,
+		Commit:      commit,
+		CompletedAt: time.Now(),
+	}
+
+	return s.Save()
+}
+
+// RecordOrigin records the current repository state of the module at the given path as the state before the
+// release. An existing origin is never overwritten, so resumed runs keep the state of the first run.
+func (s *State) RecordOrigin(name string, path string) error {
+	if _, ok := s.Origins[path]; ok {
+		return nil
+	}
+
+	branch, commit, err := repositoryState(path)
+	if err != nil {
+		return err
+	}
+
+	s.Origins[path] = ModuleState{
+		Name:        name,
+		Branch:      branch,
+		Commit:      commit,
+		CompletedAt: time.Now(),
+	}
+
+	return s.Save()
+}
+
+// LatestModuleStates returns the most recently recorded state for each module path.
+func (s *State) LatestModuleStates() map[string]ModuleState {
+	latest := make(map[string]ModuleState)
+	for _, step := range s.Steps {
+		for path, moduleState := range step.Modules {
+			if current, ok := latest[path]; !ok || moduleState.CompletedAt.After(current.CompletedAt) {
+				latest[path] = moduleState
+			}
+		}
+	}
+	return latest
+}
+
+// Verify checks that the repositories are still on the branch and commit that got recorded last.
+func (s *State) Verify() []error {
+	errors := make([]error, 0)
+	latest := s.LatestModuleStates()
+
+	paths := make([]string, 0, len(latest))
+	for path := range latest {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		expected := latest[path]
+		if !utils.FileExists(path) {
+			errors = append(errors, fmt.Errorf("module %s: path %s doesn't exist anymore", expected.Name, path))
+			continue
+		}
+		branch, commit, err := repositoryState(path)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("module %s: %w", expected.Name, err))
+			continue
+		}
+		if branch != expected.Branch {
+			errors = append(errors, fmt.Errorf("module %s: expected branch %q but found %q", expected.Name, expected.Branch, branch))
+		}
+		if commit != expected.Commit {
+			errors = append(errors, fmt.Errorf("module %s: expected commit %s but found %s", expected.Name, expected.Commit, commit))
+		}
+	}
+
+	return errors
+}
+
+func repositoryState(path string) (string, string, error) {
+	var branch, commit string
+
+	err := utils.InDirectoryE(path, func() error {
+		var err error
+		if branch, err = git.GitValueE("rev-parse", "--abbrev-ref", "HEAD"); err != nil {
+			return fmt.Errorf("couldn't get current branch in %s: %w", path, err)
+		}
+		if commit, err = git.GitValueE("rev-parse", "HEAD"); err != nil {
+			return fmt.Errorf("couldn't get current commit in %s: %w", path, err)
+		}
+		return nil
+	})
+
+	return branch, commit, err
+}
@@ -0,0 +1,161 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+)
+
+// Step is a named part of the release process. Steps are executed in order and their completion gets recorded in
+// the apply state so a failed run can be resumed.
+type Step struct {
+	Name        string
+	Description string
+	Run         func(run *StepRun) error
+}
+
+// StepRun gives a running step access to the per-module completion state.
+type StepRun struct {
+	step  Step
+	state *State
+}
+
+func (run *StepRun) Name() string {
+	return run.step.Name
+}
+
+// ForEachModule works like the package level ForEachModule function but skips modules that already completed the
+// step in a previous run and records the completion for every module.
+func (run *StepRun) ForEachModule(p project.Project, includeSubmodules bool, callback func(project.Module) error) error {
+	var err error
+
+	ForEachModule(p, includeSubmodules, func(module project.Module) {
+		if err != nil {
+			return
+		}
+		if run.state != nil && run.state.IsModuleCompleted(run.step.Name, module.Path) {
+			logger.Info("Module %s already completed step %q, skipping", module.Name, run.step.Name)
+			return
+		}
+		if err = callback(module); err != nil {
+			err = fmt.Errorf("module %s: %w", module.Name, err)
+			return
+		}
+		if run.state != nil && run.state.Filename() != "" {
+			err = run.state.CompleteModule(run.step.Name, module.Name, module.Path)
+		}
+	})
+
+	return err
+}
+
+// RecordOrigins records the repository state of all modules before the release changes anything. The origins are
+// used to roll back a failed release.
+func (run *StepRun) RecordOrigins(p project.Project) error {
+	if run.state == nil || run.state.Filename() == "" {
+		return nil
+	}
+
+	var err error
+	ForEachModule(p, false, func(module project.Module) {
+		if err == nil {
+			err = run.state.RecordOrigin(module.Name, module.Path)
+		}
+	})
+
+	return err
+}
+
+type RunOptions struct {
+	// Skip steps that have been completed in a previous run.
+	Resume bool
+	// Start with the given step. All previous steps are skipped.
+	FromStep string
+	// Only run the given step.
+	OnlyStep string
+}
+
+type StepRunner struct {
+	Steps []Step
+	// The state might be nil in which case nothing gets recorded.
+	State *State
+	// Called before a step gets executed. Might be nil.
+	BeforeStep func(step Step)
+}
+
+func (r *StepRunner) StepNames() []string {
+	return lo.Map(r.Steps, func(step Step, _ int) string {
+		return step.Name
+	})
+}
+
+func (r *StepRunner) stepIndex(name string) (int, error) {
+	index := lo.IndexOf(r.StepNames(), name)
+	if index < 0 {
+		return index, fmt.Errorf("unknown step %q (available: %s)", name, strings.Join(r.StepNames(), ", "))
+	}
+	return index, nil
+}
+
+func (r *StepRunner) Run(options RunOptions) error {
+	fromIndex := 0
+	if options.FromStep != "" {
+		index, err := r.stepIndex(options.FromStep)
+		if err != nil {
+			return err
+		}
+		fromIndex = index
+	}
+	if options.OnlyStep != "" {
+		if _, err := r.stepIndex(options.OnlyStep); err != nil {
+			return err
+		}
+	}
+
+	for index, step := range r.Steps {
+		if options.OnlyStep != "" && step.Name != options.OnlyStep {
+			continue
+		}
+		if index < fromIndex {
+			logger.ColorInfo(color.FgYellow, "===> Skipping step %q (starting from step %q)", step.Name, options.FromStep)
+			continue
+		}
+
+		targeted := step.Name == options.OnlyStep || (options.FromStep != "" && index >= fromIndex)
+
+		if r.State != nil {
+			if options.Resume && !targeted && r.State.IsStepCompleted(step.Name) {
+				logger.ColorInfo(color.FgYellow, "===> Skipping completed step %q", step.Name)
+				continue
+			}
+			// Explicitly requested steps are executed for all modules again.
+			if targeted {
+				if err := r.State.ResetStep(step.Name); err != nil {
+					return err
+				}
+			}
+		}
+
+		logger.ColorInfo(color.FgCyan, "=====> Step %d/%d: %s (%s)", index+1, len(r.Steps), step.Name, step.Description)
+
+		if r.BeforeStep != nil {
+			r.BeforeStep(step)
+		}
+
+		if err := step.Run(&StepRun{step: step, state: r.State}); err != nil {
+			return fmt.Errorf("step %q failed: %w", step.Name, err)
+		}
+
+		if r.State != nil {
+			if err := r.State.CompleteStep(step.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
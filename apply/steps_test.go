@@ -0,0 +1,95 @@
+package apply
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordingSteps(executed *[]string, names ...string) []Step {
+	steps := make([]Step, 0, len(names))
+	for _, name := range names {
+		steps = append(steps, Step{
+			Name:        name,
+			Description: "Test step " + name,
+			Run: func(run *StepRun) error {
+				*executed = append(*executed, run.Name())
+				return nil
+			},
+		})
+	}
+	return steps
+}
+
+func TestStateFilename(t *testing.T) {
+	assert.Equal(t, "manifests/release-6.2.0.apply-state.json", StateFilename("manifests/release-6.2.0.json"))
+	assert.Equal(t, "manifest.apply-state.json", StateFilename("manifest"))
+}
+
+func TestStepRunnerRunsAllSteps(t *testing.T) {
+	var executed []string
+	runner := StepRunner{Steps: recordingSteps(&executed, "one", "two", "three")}
+
+	require.NoError(t, runner.Run(RunOptions{}))
+	assert.Equal(t, []string{"one", "two", "three"}, executed)
+}
+
+func TestStepRunnerFromAndOnlyStep(t *testing.T) {
+	var executed []string
+	runner := StepRunner{Steps: recordingSteps(&executed, "one", "two", "three")}
+
+	require.NoError(t, runner.Run(RunOptions{FromStep: "two"}))
+	assert.Equal(t, []string{"two", "three"}, executed)
+
+	executed = nil
+	require.NoError(t, runner.Run(RunOptions{OnlyStep: "two"}))
+	assert.Equal(t, []string{"two"}, executed)
+
+	assert.ErrorContains(t, runner.Run(RunOptions{FromStep: "nope"}), `unknown step "nope"`)
+	assert.ErrorContains(t, runner.Run(RunOptions{OnlyStep: "nope"}), `unknown step "nope"`)
+}
+
+func TestStepRunnerResume(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "release.apply-state.json")
+
+	var executed []string
+	steps := recordingSteps(&executed, "one", "two", "three")
+	steps[1].Run = func(run *StepRun) error {
+		executed = append(executed, run.Name())
+		return assert.AnError
+	}
+
+	runner := StepRunner{Steps: steps, State: NewState(filename, []string{"release.json"})}
+	assert.ErrorIs(t, runner.Run(RunOptions{}), assert.AnError)
+	assert.Equal(t, []string{"one", "two"}, executed)
+
+	state, err := ReadState(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"release.json"}, state.ManifestFiles)
+	assert.True(t, state.IsStepCompleted("one"))
+	assert.False(t, state.IsStepCompleted("two"))
+
+	executed = nil
+	steps = recordingSteps(&executed, "one", "two", "three")
+	runner = StepRunner{Steps: steps, State: state}
+	require.NoError(t, runner.Run(RunOptions{Resume: true}))
+	assert.Equal(t, []string{"two", "three"}, executed)
+
+	// Explicitly requested steps run again even if they have been completed
+	executed = nil
+	require.NoError(t, runner.Run(RunOptions{Resume: true, OnlyStep: "one"}))
+	assert.Equal(t, []string{"one"}, executed)
+}
+
+func TestStateDetach(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "release.apply-state.json")
+
+	state := NewState(filename, []string{"release.json"})
+	state.Detach()
+	require.NoError(t, state.CompleteStep("one"))
+
+	assert.True(t, state.IsStepCompleted("one"))
+	assert.NoFileExists(t, filename)
+}
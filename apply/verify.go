@@ -0,0 +1,301 @@
+package apply
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/pom"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	VerifyTag                = "tag"
+	VerifyTagVersion         = "tag-version"
+	VerifyDevelopmentVersion = "development-version"
+	VerifyServerVersion      = "server-version"
+	VerifyPackageJson        = "package-json"
+	VerifyChangelog          = "changelog"
+)
+
+// The properties that must contain the server version in non-server modules.
+var serverVersionProperties = []string{"graylog.version", "graylog2.version"}
+
+type VerifyOptions struct {
+	// The remote of the release. Defaults to DefaultRemote.
+	Remote string
+	// Names of the checks that should be skipped.
+	Skip []string
+}
+
+type verifyCheck struct {
+	name string
+	run  func(ctx verifyContext) (CheckStatus, string)
+}
+
+// The state of a single module for the post-release verification.
+type verifyContext struct {
+	module project.Module
+	server project.Module
+	remote string
+	// The repository root of the module. (the module path can be a sub-directory for modules with a manifest "path")
+	repository string
+	tag        string
+	// The modules with pom.xml files in the repository. (module and submodules)
+	modules []project.Module
+}
+
+var verifyChecks = []verifyCheck{
+	{name: VerifyTag, run: verifyTag},
+	{name: VerifyTagVersion, run: verifyTagVersion},
+	{name: VerifyDevelopmentVersion, run: verifyDevelopmentVersion},
+	{name: VerifyServerVersion, run: verifyServerVersion},
+	{name: VerifyPackageJson, run: verifyPackageJson},
+	{name: VerifyChangelog, run: verifyChangelog},
+}
+
+// VerifyCheckNames returns the names of all post-release checks in execution order.
+func VerifyCheckNames() []string {
+	names := make([]string, 0, len(verifyChecks))
+	for _, check := range verifyChecks {
+		names = append(names, check.name)
+	}
+	return names
+}
+
+// RunVerify checks that the release of the given apply manifest project landed consistently in the local
+// repositories and on the remote. The checks don't modify the repositories except for fetching the remote branches.
+func RunVerify(p project.Project, options VerifyOptions) ([]CheckResult, error) {
+	for _, name := range options.Skip {
+		if !slices.Contains(VerifyCheckNames(), name) {
+			return nil, fmt.Errorf("unknown verify check %q (available: %s)", name, strings.Join(VerifyCheckNames(), ", "))
+		}
+	}
+
+	gitRemote := remoteOrDefault(options.Remote)
+	results := make([]CheckResult, 0)
+	contexts := make([]verifyContext, 0)
+
+	ForEachModule(p, false, func(module project.Module) {
+		if err := git.ExecInPath(module.Path, "fetch", "--quiet", "--no-tags", gitRemote); err != nil {
+			results = append(results, CheckResult{Check: "fetch", Module: module.Name, Status: CheckFailed, Message: err.Error()})
+			return
+		}
+		repository, err := repositoryToplevel(module.Path)
+		if err != nil {
+			results = append(results, CheckResult{Check: "fetch", Module: module.Name, Status: CheckFailed, Message: err.Error()})
+			return
+		}
+		contexts = append(contexts, verifyContext{
+			module:     module,
+			server:     p.Server,
+			remote:     gitRemote,
+			repository: repository,
+			tag:        "refs/tags/" + module.Revision,
+			modules:    append([]project.Module{module}, module.Submodules...),
+		})
+	})
+
+	for _, check := range verifyChecks {
+		if slices.Contains(options.Skip, check.name) {
+			continue
+		}
+		for _, ctx := range contexts {
+			status, message := check.run(ctx)
+			results = append(results, CheckResult{Check: check.name, Module: ctx.module.Name, Status: status, Message: message})
+		}
+	}
+
+	return results, nil
+}
+
+// Returns the path of the given file in the module relative to the repository root.
+func (ctx verifyContext) file(module project.Module, file string) string {
+	rel, err := repositoryRelativePath(ctx.repository, module.Path)
+	if err != nil {
+		return file
+	}
+	return filepath.ToSlash(filepath.Join(rel, file))
+}
+
+func (ctx verifyContext) pomAt(ref string, module project.Module) (*pom.Document, error) {
+	content, err := git.ShowFile(ctx.repository, ref, ctx.file(module, "pom.xml"))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := pom.ParseDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s at %s: %w", ctx.file(module, "pom.xml"), ref, err)
+	}
+	return doc, nil
+}
+
+// Returns the branches that must contain the next development version.
+func (ctx verifyContext) developmentBranches() []string {
+	branches := []string{ctx.module.ApplyFromRevision()}
+	if ctx.module.ApplyNewBranch() != "" {
+		branches = append(branches, ctx.module.ApplyNewBranch())
+	}
+	return branches
+}
+
+func verifyTag(ctx verifyContext) (CheckStatus, string) {
+	// Compares the tag objects, so annotated tags must be identical as well
+	var local string
+	err := utils.InDirectoryE(ctx.repository, func() error {
+		var err error
+		local, err = git.GitValueE("rev-parse", "--verify", "--quiet", ctx.tag)
+		return err
+	})
+	if err != nil || local == "" {
+		return CheckFailed, fmt.Sprintf("tag %s doesn't exist locally", ctx.module.Revision)
+	}
+
+	remote, err := git.RemoteRef(ctx.repository, ctx.remote, ctx.tag)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if remote == "" {
+		return CheckFailed, fmt.Sprintf("tag %s doesn't exist on %s", ctx.module.Revision, ctx.remote)
+	}
+	if remote != local {
+		return CheckFailed, fmt.Sprintf("tag %s differs locally (%.10s) and on %s (%.10s)", ctx.module.Revision, local, ctx.remote, remote)
+	}
+
+	return CheckOK, fmt.Sprintf("tag %s exists locally and on %s (%.10s)", ctx.module.Revision, ctx.remote, local)
+}
+
+func verifyTagVersion(ctx verifyContext) (CheckStatus, string) {
+	doc, err := ctx.pomAt(ctx.tag, ctx.module)
+	if err != nil {
+		return CheckFailed, err.Error()
+	}
+	if doc.Version() != ctx.module.Revision {
+		return CheckFailed, fmt.Sprintf("pom version at tag %s is %s", ctx.module.Revision, doc.Version())
+	}
+	return CheckOK, fmt.Sprintf("pom version at tag is %s", doc.Version())
+}
+
+func verifyDevelopmentVersion(ctx verifyContext) (CheckStatus, string) {
+	expected := ctx.module.ApplyNewVersion()
+	if !strings.HasSuffix(expected, SnapshotSuffix) {
+		return CheckFailed, fmt.Sprintf("next version %q is not a %s version", expected, SnapshotSuffix)
+	}
+
+	messages := make([]string, 0)
+	for _, branch := range ctx.developmentBranches() {
+		ref := "refs/remotes/" + ctx.remote + "/" + branch
+		doc, err := ctx.pomAt(ref, ctx.module)
+		if err != nil {
+			return CheckFailed, err.Error()
+		}
+		if doc.Version() != expected {
+			return CheckFailed, fmt.Sprintf("pom version in %s/%s is %s, expected %s", ctx.remote, branch, doc.Version(), expected)
+		}
+		messages = append(messages, fmt.Sprintf("%s/%s", ctx.remote, branch))
+	}
+
+	return CheckOK, fmt.Sprintf("pom version is %s in %s", expected, strings.Join(messages, ", "))
+}
+
+func verifyServerVersion(ctx verifyContext) (CheckStatus, string) {
+	if ctx.module.Server {
+		return CheckOK, "server module"
+	}
+
+	expected := ctx.server.Revision
+	checked := 0
+	for _, module := range ctx.modules {
+		doc, err := ctx.pomAt(ctx.tag, module)
+		if err != nil {
+			return CheckFailed, err.Error()
+		}
+		if doc.HasParent() && isGraylogPluginParent(doc.ParentGroupId(), doc.ParentArtifactId()) {
+			checked++
+			if doc.ParentVersion() != expected {
+				return CheckFailed, fmt.Sprintf("parent version in %s is %s, expected %s", ctx.file(module, "pom.xml"), doc.ParentVersion(), expected)
+			}
+		}
+		properties := doc.Properties()
+		for _, name := range serverVersionProperties {
+			value, ok := properties[name]
+			if !ok || strings.HasPrefix(value, "${") {
+				continue
+			}
+			checked++
+			if value != expected {
+				return CheckFailed, fmt.Sprintf("%s property in %s is %s, expected %s", name, ctx.file(module, "pom.xml"), value, expected)
+			}
+		}
+	}
+
+	if checked == 0 {
+		return CheckOK, "no plugin parent or server version properties"
+	}
+	return CheckOK, fmt.Sprintf("%d plugin parent and server version references are %s", checked, expected)
+}
+
+func verifyPackageJson(ctx verifyContext) (CheckStatus, string) {
+	refs := [][2]string{{ctx.tag, ctx.module.Revision}}
+	for _, branch := range ctx.developmentBranches() {
+		refs = append(refs, [2]string{"refs/remotes/" + ctx.remote + "/" + branch, ctx.module.ApplyNewVersion()})
+	}
+
+	checked := 0
+	for _, module := range ctx.modules {
+		for _, file := range module.VersionPackageJsonFiles() {
+			path := ctx.file(module, file)
+			for _, refVersion := range refs {
+				ref, expected := refVersion[0], refVersion[1]
+				content, err := git.ShowFile(ctx.repository, ref, path)
+				if err != nil {
+					return CheckFailed, err.Error()
+				}
+				actual, ok := utils.ParsePackageJsonVersion(content)
+				if !ok {
+					return CheckFailed, fmt.Sprintf("couldn't find top-level version field in %s at %s", path, ref)
+				}
+				if actual != expected {
+					return CheckFailed, fmt.Sprintf("version in %s at %s is %s, expected %s", path, ref, actual, expected)
+				}
+			}
+			checked++
+		}
+	}
+
+	if checked == 0 {
+		return CheckOK, "no package.json files"
+	}
+	return CheckOK, fmt.Sprintf("%d package.json files have the release and development versions", checked)
+}
+
+func verifyChangelog(ctx verifyContext) (CheckStatus, string) {
+	if !git.PathExists(ctx.repository, ctx.tag, ctx.file(ctx.module, "changelog")) {
+		return CheckOK, "module has no changelog"
+	}
+
+	v, err := version.NewSemver(ctx.module.Revision)
+	if err != nil {
+		return CheckFailed, fmt.Sprintf("invalid release version %q: %s", ctx.module.Revision, err)
+	}
+
+	// The changelogs of GA releases are rotated before tagging. Pre-releases only rotate the changelogs in the
+	// source branch when a new branch gets created. (see the "rotate-source-branch-changelogs" step)
+	ref := ctx.tag
+	if v.Prerelease() != "" {
+		if ctx.module.ApplyNewBranch() == "" {
+			return CheckOK, "no changelog folder for pre-release version"
+		}
+		ref = "refs/remotes/" + ctx.remote + "/" + ctx.module.ApplyFromRevision()
+	}
+
+	folder := ctx.file(ctx.module, filepath.Join("changelog", ctx.module.Revision))
+	if !git.PathExists(ctx.repository, ref, folder) {
+		return CheckFailed, fmt.Sprintf("changelog folder %s doesn't exist at %s", folder, ref)
+	}
+	return CheckOK, fmt.Sprintf("changelog folder %s exists", folder)
+}
@@ -0,0 +1,96 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifyTestPom = `<project>
+    <modelVersion>4.0.0</modelVersion>
+    <parent>
+        <groupId>org.graylog.plugins</groupId>
+        <artifactId>graylog-plugin-web-parent</artifactId>
+        <version>6.2.0</version>
+    </parent>
+    <groupId>org.graylog.plugins</groupId>
+    <artifactId>graylog-plugin-enterprise</artifactId>
+    <version>6.2.0</version>
+    <properties>
+        <graylog.version>${project.parent.version}</graylog.version>
+    </properties>
+</project>
+`
+
+func TestVerifyChecks(t *testing.T) {
+	workspace, _ := newTestRepo(t, "graylog-plugin-enterprise")
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "pom.xml"), []byte(verifyTestPom), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "changelog", "6.2.0"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "changelog", "6.2.0", "issue-1.toml"), []byte("type = \"f\"\n"), 0644))
+	runGit(t, workspace, "add", "pom.xml", "changelog")
+	runGit(t, workspace, "commit", "--quiet", "--message", "[graylog-plugin-enterprise] prepare release 6.2.0")
+	runGit(t, workspace, "tag", "--annotate", "--message", "Release 6.2.0", "6.2.0")
+	runGit(t, workspace, "tag", "6.2.1")
+	runGit(t, workspace, "push", "--quiet", "origin", "main", "6.2.0")
+
+	ctx := verifyContext{
+		module: project.Module{Name: "graylog-plugin-enterprise", Path: workspace, Revision: "6.2.0"},
+		server: project.Module{Name: "graylog-server", Revision: "6.2.0", Server: true},
+		remote: "origin",
+		tag:    "refs/tags/6.2.0",
+	}
+	ctx.modules = []project.Module{ctx.module}
+	var err error
+	ctx.repository, err = repositoryToplevel(workspace)
+	require.NoError(t, err)
+
+	status, message := verifyTag(ctx)
+	assert.Equal(t, CheckOK, status, message)
+	status, message = verifyTagVersion(ctx)
+	assert.Equal(t, CheckOK, status, message)
+	status, message = verifyServerVersion(ctx)
+	assert.Equal(t, CheckOK, status, message)
+	assert.Equal(t, "1 plugin parent and server version references are 6.2.0", message)
+	status, message = verifyChangelog(ctx)
+	assert.Equal(t, CheckOK, status, message)
+
+	// The server release version doesn't match the plugin parent
+	ctx.server.Revision = "6.2.1"
+	status, message = verifyServerVersion(ctx)
+	assert.Equal(t, CheckFailed, status)
+	assert.Equal(t, "parent version in pom.xml is 6.2.0, expected 6.2.1", message)
+
+	// The tag hasn't been pushed and the pom version doesn't match
+	ctx.module.Revision = "6.2.1"
+	ctx.tag = "refs/tags/6.2.1"
+	status, message = verifyTag(ctx)
+	assert.Equal(t, CheckFailed, status)
+	assert.Equal(t, "tag 6.2.1 doesn't exist on origin", message)
+	status, message = verifyTagVersion(ctx)
+	assert.Equal(t, CheckFailed, status)
+	assert.Equal(t, "pom version at tag 6.2.1 is 6.2.0", message)
+	status, message = verifyChangelog(ctx)
+	assert.Equal(t, CheckFailed, status)
+	assert.Equal(t, "changelog folder changelog/6.2.1 doesn't exist at refs/tags/6.2.1", message)
+
+	// Pre-releases without a new branch don't rotate the changelogs
+	ctx.module.Revision = "6.2.1-rc.1"
+	status, message = verifyChangelog(ctx)
+	assert.Equal(t, CheckOK, status, message)
+
+	// The tag doesn't exist at all
+	ctx.tag = "refs/tags/6.3.0"
+	ctx.module.Revision = "6.3.0"
+	status, message = verifyTag(ctx)
+	assert.Equal(t, CheckFailed, status)
+	assert.Equal(t, "tag 6.3.0 doesn't exist locally", message)
+}
+
+func TestRunVerifyUnknownCheck(t *testing.T) {
+	_, err := RunVerify(project.Project{}, VerifyOptions{Skip: []string{"tag", "nope"}})
+	assert.ErrorContains(t, err, `unknown verify check "nope"`)
+}
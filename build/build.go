@@ -0,0 +1,133 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/logger"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+)
+
+// Property to skip the web interface build in the server module.
+const SkipWebProperty = "skip.web.build"
+
+type Options struct {
+	Goals              []string
+	Profiles           []string
+	AlsoMake           bool
+	AlsoMakeDependents bool
+	SkipWeb            bool
+	SkipTests          bool
+	// Additional arguments that are passed to Maven as is.
+	Args []string
+}
+
+// ReactorProjects returns the Maven reactor coordinates ("groupId:artifactId") for the given modules and their
+// submodules. Non-Maven modules are ignored.
+func ReactorProjects(modules []p.Module) []string {
+	projects := make([]string, 0)
+
+	add := func(module p.Module) {
+		if !module.IsMavenModule() {
+			return
+		}
+		coordinates := fmt.Sprintf("%s:%s", module.GroupId(), module.ArtifactId())
+		if !lo.Contains(projects, coordinates) {
+			projects = append(projects, coordinates)
+		}
+	}
+
+	for _, module := range modules {
+		add(module)
+		for _, submodule := range module.Submodules {
+			add(submodule)
+		}
+	}
+
+	return projects
+}
+
+// MavenArgs returns the Maven arguments for the given options and reactor projects. An empty project list builds
+// the complete reactor.
+func MavenArgs(options Options, projects []string) []string {
+	args := []string{"--show-version", "--batch-mode"}
+
+	if len(options.Profiles) > 0 {
+		args = append(args, "--activate-profiles", strings.Join(options.Profiles, ","))
+	}
+	if len(projects) > 0 {
+		args = append(args, "--projects", strings.Join(projects, ","))
+		if options.AlsoMake {
+			args = append(args, "--also-make")
+		}
+		if options.AlsoMakeDependents {
+			args = append(args, "--also-make-dependents")
+		}
+	}
+	if options.SkipWeb {
+		args = append(args, "-D"+SkipWebProperty+"=true")
+	}
+	if options.SkipTests {
+		args = append(args, "-DskipTests")
+	}
+
+	args = append(args, options.Args...)
+
+	goals := options.Goals
+	if len(goals) == 0 {
+		goals = []string{"package"}
+	}
+
+	return append(args, goals...)
+}
+
+// Run executes Maven with the given arguments in the given path and returns the parsed reactor summary.
+// The Maven output is written to stdout while it's parsed.
+func Run(path string, args []string) ([]ReactorResult, error) {
+	var results []ReactorResult
+	var runErr error
+
+	err := utils.InDirectoryE(path, func() error {
+		mavenBin := utils.MavenBin()
+		logger.ColorPrintln(color.FgMagenta, "[command output: %s %s]", mavenBin, strings.Join(args, " "))
+
+		command := exec.Command(mavenBin, args...)
+		command.Stderr = os.Stderr
+		command.Stdin = os.Stdin
+
+		reader, writer := io.Pipe()
+		command.Stdout = io.MultiWriter(os.Stdout, writer)
+
+		parsed := make(chan []ReactorResult)
+		go func() {
+			parsed <- ParseReactorSummary(reader)
+		}()
+
+		if err := command.Start(); err != nil {
+			_ = writer.Close()
+			<-parsed
+			return fmt.Errorf("couldn't start %s: %w", mavenBin, err)
+		}
+
+		runErr = command.Wait()
+		_ = writer.Close()
+		results = <-parsed
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if runErr != nil {
+		return results, fmt.Errorf("maven build failed: %w", runErr)
+	}
+
+	return results, nil
+}
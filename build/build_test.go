@@ -0,0 +1,97 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mavenOutput = "[INFO] Scanning for projects...\n" +
+	"[INFO] Building Graylog 6.2.0-SNAPSHOT\n" +
+	"[INFO] ------------------------------------------------------------------------\n" +
+	"[INFO] Reactor Summary for Graylog Project 6.2.0-SNAPSHOT:\n" +
+	"[INFO] \n" +
+	"[INFO] Graylog Project .................................... SUCCESS [  0.521 s]\n" +
+	"[INFO] \x1b[1mGraylog\x1b[m ............................................ FAILURE [01:12 min]\n" +
+	"[INFO] Graylog Plugin Enterprise .......................... SKIPPED\n" +
+	"[INFO] ------------------------------------------------------------------------\n" +
+	"[INFO] BUILD FAILURE\n"
+
+func TestParseReactorSummary(t *testing.T) {
+	results := ParseReactorSummary(strings.NewReader(mavenOutput))
+
+	assert.Equal(t, []ReactorResult{
+		{Name: "Graylog Project", Status: StatusSuccess, Duration: "0.521 s"},
+		{Name: "Graylog", Status: StatusFailure, Duration: "01:12 min"},
+		{Name: "Graylog Plugin Enterprise", Status: StatusSkipped},
+	}, results)
+	assert.True(t, results[1].Failed())
+}
+
+func TestResolveModules(t *testing.T) {
+	writePom := func(dir string, content string) {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "pom.xml"), []byte("<project>"+content+"</project>"), 0644))
+	}
+
+	root := t.TempDir()
+	server := filepath.Join(root, "graylog2-server")
+	writePom(server, "<groupId>org.graylog</groupId><artifactId>graylog-parent</artifactId><version>6.2.0</version>"+
+		"<name>Graylog Project</name><modules><module>graylog2-server</module></modules>")
+	writePom(filepath.Join(server, "graylog2-server"), "<groupId>org.graylog</groupId><artifactId>graylog2-server</artifactId>"+
+		"<version>6.2.0</version><name>Graylog</name>")
+	enterprise := filepath.Join(root, "graylog-plugin-enterprise")
+	writePom(enterprise, "<groupId>org.graylog</groupId><artifactId>graylog-plugin-enterprise</artifactId><version>6.2.1</version>")
+
+	modules := []p.Module{
+		{Name: "graylog2-server", Path: server},
+		{Name: "graylog-plugin-enterprise", Path: enterprise},
+	}
+	results := ResolveModules([]ReactorResult{
+		{Name: "Graylog Project", Status: StatusSuccess},
+		{Name: "Graylog", Status: StatusFailure},
+		{Name: "graylog-plugin-enterprise 6.2.1", Status: StatusSkipped},
+		{Name: "Graylog Integrations", Status: StatusSkipped},
+	}, modules)
+
+	assert.Equal(t, []ReactorResult{
+		{Name: "Graylog Project", Status: StatusSuccess, Module: "graylog2-server"},
+		{Name: "Graylog", Status: StatusFailure, Module: "graylog2-server", Path: "graylog2-server"},
+		{Name: "graylog-plugin-enterprise 6.2.1", Status: StatusSkipped, Module: "graylog-plugin-enterprise"},
+		{Name: "Graylog Integrations", Status: StatusSkipped},
+	}, results)
+	assert.Equal(t, "graylog2-server/graylog2-server", results[1].Title())
+	assert.Equal(t, "graylog-plugin-enterprise", results[2].Title())
+	assert.Equal(t, "Graylog Integrations", results[3].Title())
+}
+
+func TestParseReactorSummaryWithoutSummary(t *testing.T) {
+	assert.Empty(t, ParseReactorSummary(strings.NewReader("[INFO] BUILD SUCCESS\n")))
+}
+
+func TestMavenArgs(t *testing.T) {
+	assert.Equal(t, []string{"--show-version", "--batch-mode", "package"}, MavenArgs(Options{AlsoMake: true}, nil))
+
+	assert.Equal(t, []string{
+		"--show-version", "--batch-mode",
+		"--activate-profiles", "release,fast",
+		"--projects", "org.graylog:graylog-server,org.graylog:enterprise",
+		"--also-make", "--also-make-dependents",
+		"-Dskip.web.build=true", "-DskipTests",
+		"-o",
+		"clean", "install",
+	}, MavenArgs(Options{
+		Goals:              []string{"clean", "install"},
+		Profiles:           []string{"release", "fast"},
+		AlsoMake:           true,
+		AlsoMakeDependents: true,
+		SkipWeb:            true,
+		SkipTests:          true,
+		Args:               []string{"-o"},
+	}, []string{"org.graylog:graylog-server", "org.graylog:enterprise"}))
+}
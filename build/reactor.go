@@ -0,0 +1,146 @@
+package build
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/pomparse"
+	p "github.com/Graylog2/graylog-project-cli/project"
+)
+
+const (
+	StatusSuccess = "SUCCESS"
+	StatusFailure = "FAILURE"
+	StatusSkipped = "SKIPPED"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+var reactorSummaryStartPattern = regexp.MustCompile(`^\[INFO\] Reactor Summary`)
+var reactorVersionSuffixPattern = regexp.MustCompile(` \d[\w.-]*$`)
+var reactorSummaryLinePattern = regexp.MustCompile(`^\[INFO\] (.+?) \.+ (SUCCESS|FAILURE|SKIPPED)(?: \[\s*(.+?)\])?\s*$`)
+
+// ReactorResult is a single entry from the Maven "Reactor Summary".
+type ReactorResult struct {
+	// The Maven project name. (or the artifactId for projects without a name)
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration string `json:"duration,omitempty"`
+	// The name of the manifest module that contains the Maven project. (see ResolveModules)
+	Module string `json:"module,omitempty"`
+	// The directory of the Maven project, relative to the module path.
+	Path string `json:"path,omitempty"`
+}
+
+func (r ReactorResult) Failed() bool {
+	return r.Status == StatusFailure
+}
+
+// Title returns the module and the path of the Maven project. (e.g., "graylog2-server/graylog2-web-interface")
+// Results without a module return the Maven project name.
+func (r ReactorResult) Title() string {
+	if r.Module == "" {
+		return r.Name
+	}
+	if r.Path == "" {
+		return r.Module
+	}
+	return r.Module + "/" + r.Path
+}
+
+// ParseReactorSummary reads the given Maven output until EOF and returns the entries of the reactor summary.
+// Single module builds don't print a reactor summary, so the result is empty in that case.
+func ParseReactorSummary(reader io.Reader) []ReactorResult {
+	results := make([]ReactorResult, 0)
+	inSummary := false
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := ansiPattern.ReplaceAllString(scanner.Text(), "")
+
+		if reactorSummaryStartPattern.MatchString(line) {
+			inSummary = true
+			results = make([]ReactorResult, 0)
+			continue
+		}
+		if !inSummary {
+			continue
+		}
+		if strings.HasPrefix(line, "[INFO] ---") {
+			inSummary = false
+			continue
+		}
+
+		if match := reactorSummaryLinePattern.FindStringSubmatch(line); match != nil {
+			results = append(results, ReactorResult{
+				Name:     strings.TrimSpace(match[1]),
+				Status:   match[2],
+				Duration: strings.TrimSpace(match[3]),
+			})
+		}
+	}
+
+	// Make sure to consume the complete output so the writer never blocks.
+	_, _ = io.Copy(io.Discard, reader)
+
+	return results
+}
+
+// ResolveModules returns the results with the manifest module and path of every Maven project. The projects of the
+// modules and their Maven submodules are matched by name and artifactId. Submodules in the manifest take precedence
+// over the module that contains them. Results without a matching project keep the Maven project name only.
+func ResolveModules(results []ReactorResult, modules []p.Module) []ReactorResult {
+	if len(results) == 0 {
+		return results
+	}
+
+	type reactorProject struct {
+		module string
+		path   string
+	}
+	projects := make(map[string]reactorProject)
+
+	var add func(module p.Module)
+	add = func(module p.Module) {
+		for _, submodule := range module.Submodules {
+			add(submodule)
+		}
+		if !module.IsMavenModule() {
+			return
+		}
+		for _, pomFile := range pomparse.FindPomFiles(module.Path) {
+			pom := pomparse.ParsePom(pomFile)
+			path, err := filepath.Rel(module.Path, filepath.Dir(pomFile))
+			if err != nil || path == "." {
+				path = ""
+			}
+			for _, key := range []string{pom.Name, pom.ArtifactId} {
+				if _, ok := projects[key]; key != "" && !ok {
+					projects[key] = reactorProject{module: module.Name, path: filepath.ToSlash(path)}
+				}
+			}
+		}
+	}
+	for _, module := range modules {
+		add(module)
+	}
+
+	resolved := make([]ReactorResult, 0, len(results))
+	for _, result := range results {
+		project, ok := projects[result.Name]
+		if !ok {
+			// Maven appends the version to the names of projects with a different version than the top-level project
+			project, ok = projects[reactorVersionSuffixPattern.ReplaceAllString(result.Name, "")]
+		}
+		if ok {
+			result.Module = project.module
+			result.Path = project.path
+		}
+		resolved = append(resolved, result)
+	}
+	return resolved
+}
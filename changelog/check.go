@@ -0,0 +1,147 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/samber/lo"
+)
+
+// The snippet files in a module. (relative to the module path)
+const unreleasedSnippetsPattern = "changelog/unreleased/*.toml"
+
+// Commit trailer values that skip the check. (e.g., "Changelog: skip")
+var checkSkipTrailerValues = []string{"skip", "none", "no"}
+
+// ErrMissingSnippet is returned by Check if the changes don't contain a snippet and no opt-out applies.
+var ErrMissingSnippet = errors.New("missing changelog snippet")
+
+type CheckOptions struct {
+	// The base ref of the changes. (e.g., "origin/master")
+	Base string
+	// The path of the module in the repository. (e.g., the "path" of a manifest module) Empty for the repository root.
+	Path string
+	// The labels of the pull request.
+	Labels []string
+	// Pull request labels that skip the check. (e.g., "no-changelog")
+	SkipLabels []string
+	// The commit trailer that skips the check with one of the checkSkipTrailerValues. (e.g., "Changelog")
+	SkipTrailer string
+	// Glob patterns for changes that don't need a snippet. (e.g., "docs/**" or "**/*.md")
+	IgnorePatterns []string
+	Lint           LintOptions
+}
+
+type CheckResult struct {
+	// The added or modified snippet files.
+	Snippets []string
+	// The opt-out that skipped the check or an empty string.
+	SkipReason string
+}
+
+// Check checks that the changes since the merge base of the base ref and HEAD in the repository at the given path
+// contain an added or modified snippet file of the module. The snippets are linted. Without snippets, the check fails with
+// ErrMissingSnippet unless a skip label or skip commit trailer is present or all changes match the ignore patterns.
+func Check(repoPath string, options CheckOptions) (CheckResult, error) {
+	var result CheckResult
+
+	changedSnippets, err := git.ChangedFiles(repoPath, options.Base, "AM")
+	if err != nil {
+		return result, err
+	}
+	// The changed files are relative to the repository root
+	snippetsPattern := path.Join(filepath.ToSlash(options.Path), unreleasedSnippetsPattern)
+	result.Snippets = lo.Filter(changedSnippets, func(file string, _ int) bool {
+		matched, _ := path.Match(snippetsPattern, file)
+		return matched
+	})
+
+	if len(result.Snippets) > 0 {
+		logger.Debug("Found changed snippets: %s", strings.Join(result.Snippets, ", "))
+		paths := lo.Map(result.Snippets, func(file string, _ int) string {
+			return filepath.Join(repoPath, filepath.FromSlash(file))
+		})
+		return result, LintPaths(paths, options.Lint)
+	}
+
+	if label, ok := lo.Find(options.Labels, func(label string) bool {
+		return lo.ContainsBy(options.SkipLabels, func(skipLabel string) bool {
+			return strings.EqualFold(strings.TrimSpace(label), strings.TrimSpace(skipLabel))
+		})
+	}); ok {
+		result.SkipReason = fmt.Sprintf("pull request has the %q label", label)
+		return result, nil
+	}
+
+	if options.SkipTrailer != "" {
+		values, err := git.TrailerValues(repoPath, options.Base, options.SkipTrailer)
+		if err != nil {
+			return result, err
+		}
+		if value, ok := lo.Find(values, func(value string) bool {
+			return lo.Contains(checkSkipTrailerValues, strings.ToLower(value))
+		}); ok {
+			result.SkipReason = fmt.Sprintf("commit has the \"%s: %s\" trailer", options.SkipTrailer, value)
+			return result, nil
+		}
+	}
+
+	changedFiles, err := git.ChangedFiles(repoPath, options.Base, "")
+	if err != nil {
+		return result, err
+	}
+	patterns, err := compileGlobs(options.IgnorePatterns)
+	if err != nil {
+		return result, err
+	}
+	// Without any changes, there is nothing to ignore
+	if len(patterns) > 0 && len(changedFiles) > 0 && lo.EveryBy(changedFiles, func(file string) bool {
+		return lo.ContainsBy(patterns, func(pattern *regexp.Regexp) bool {
+			return pattern.MatchString(file)
+		})
+	}) {
+		result.SkipReason = "all changes match the ignore patterns"
+		return result, nil
+	}
+
+	return result, ErrMissingSnippet
+}
+
+// Compiles glob patterns with "**" support. ("*" and "?" don't match "/" and "**" matches any number of directories)
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		var pattern strings.Builder
+		pattern.WriteString("^")
+		for i := 0; i < len(glob); i++ {
+			switch {
+			case strings.HasPrefix(glob[i:], "**/"):
+				pattern.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(glob[i:], "**"):
+				pattern.WriteString(".*")
+				i++
+			case glob[i] == '*':
+				pattern.WriteString("[^/]*")
+			case glob[i] == '?':
+				pattern.WriteString("[^/]")
+			default:
+				pattern.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		}
+		pattern.WriteString("$")
+
+		compiled, err := regexp.Compile(pattern.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", glob, err)
+		}
+		patterns = append(patterns, compiled)
+	}
+	return patterns, nil
+}
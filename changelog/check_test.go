@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	repo := t.TempDir()
+	writeFile := func(name string, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0644))
+	}
+	commit := func(args ...string) {
+		require.NoError(t, git.ExecInPath(repo, "add", "-A"))
+		require.NoError(t, git.ExecInPath(repo, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit"}, args...)...))
+	}
+	branch := func(name string) {
+		require.NoError(t, git.ExecInPath(repo, "checkout", "-q", "-b", name, "main"))
+	}
+
+	require.NoError(t, git.Exec("init", "--initial-branch=main", repo))
+	writeFile("README.md", "readme")
+	writeFile("changelog/unreleased/.gitkeep", "")
+	commit("-m", "initial")
+
+	options := CheckOptions{
+		Base:           "main",
+		SkipLabels:     []string{"no-changelog"},
+		SkipTrailer:    "Changelog",
+		IgnorePatterns: []string{"**/*.md", "docs/**"},
+	}
+
+	branch("snippet")
+	writeFile("src/Main.java", "class Main {}")
+	writeFile("changelog/unreleased/pr-1.toml", "type = \"a\"\nmessage = \"Add main.\"\npulls = [\"1\"]\n")
+	commit("-m", "with snippet")
+	result, err := Check(repo, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"changelog/unreleased/pr-1.toml"}, result.Snippets)
+	assert.Empty(t, result.SkipReason)
+
+	branch("invalid-snippet")
+	writeFile("changelog/unreleased/pr-2.toml", "type = \"a\"\nmessage = \"Add main.\"\n")
+	commit("-m", "with invalid snippet")
+	_, err = Check(repo, options)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMissingSnippet)
+
+	branch("missing")
+	writeFile("src/Main.java", "class Main {}")
+	writeFile("docs/index.md", "docs")
+	commit("-m", "without snippet")
+	_, err = Check(repo, options)
+	assert.ErrorIs(t, err, ErrMissingSnippet)
+
+	labelOptions := options
+	labelOptions.Labels = []string{"bug", "No-Changelog"}
+	result, err = Check(repo, labelOptions)
+	require.NoError(t, err)
+	assert.Equal(t, `pull request has the "No-Changelog" label`, result.SkipReason)
+
+	commit("--allow-empty", "-m", "skip", "--trailer", "Changelog: skip")
+	result, err = Check(repo, options)
+	require.NoError(t, err)
+	assert.Equal(t, `commit has the "Changelog: skip" trailer`, result.SkipReason)
+
+	branch("docs")
+	writeFile("docs/guide/setup.md", "docs")
+	writeFile("CHANGES.md", "changes")
+	commit("-m", "docs only")
+	result, err = Check(repo, options)
+	require.NoError(t, err)
+	assert.Equal(t, "all changes match the ignore patterns", result.SkipReason)
+
+	options.IgnorePatterns = []string{"*.md"}
+	_, err = Check(repo, options)
+	assert.ErrorIs(t, err, ErrMissingSnippet)
+
+	// The ignore patterns don't skip the check without any changes
+	branch("empty")
+	commit("--allow-empty", "-m", "empty")
+	_, err = Check(repo, options)
+	assert.ErrorIs(t, err, ErrMissingSnippet)
+
+	// The snippets of a module are in the module path
+	branch("module")
+	writeFile("plugin/changelog/unreleased/pr-3.toml", "type = \"a\"\nmessage = \"Add plugin.\"\npulls = [\"3\"]\n")
+	commit("-m", "with module snippet")
+	_, err = Check(repo, options)
+	assert.ErrorIs(t, err, ErrMissingSnippet)
+
+	options.Path = "plugin"
+	result, err = Check(repo, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"plugin/changelog/unreleased/pr-3.toml"}, result.Snippets)
+}
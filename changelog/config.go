@@ -18,4 +18,14 @@ type Config struct {
 	ReadStdin               bool
 	MarkdownHeaderBaseLevel int
 	GitHubRepoURL           string
+	// How to render the user and ops details of the snippets. (see AvailableDetailsModes, defaults to DetailsAggregated)
+	Details string
+	// The module names of the snippets paths. Only used for project changelogs.
+	Modules map[string]string
+	// The Go template file for the template format.
+	TemplateFile string
+	// The groups of a grouped project changelog. (see NewGroups) The structured formats ignore the groups.
+	Groups []Group
+	// The heading level of the type sections relative to the default level. (increased for grouped changelogs)
+	sectionLevel int
 }
@@ -0,0 +1,255 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+const FormatJSON = "json"
+const FormatYAML = "yaml"
+
+// DocumentSchemaVersion is the version of the JSON and YAML changelog schema. Fields can be added without changing
+// the version, but renaming or removing fields and changing their meaning requires a new version.
+const DocumentSchemaVersion = 1
+
+var errStructuredFormatter = errors.New("structured formatters only render complete documents")
+
+// Document is the fully resolved changelog model for the structured formats.
+type Document struct {
+	SchemaVersion int             `json:"schema_version" yaml:"schema_version"`
+	Product       string          `json:"product" yaml:"product"`
+	Version       string          `json:"version" yaml:"version"`
+	ReleaseDate   string          `json:"release_date" yaml:"release_date"`
+	Entries       []DocumentEntry `json:"entries" yaml:"entries"`
+}
+
+type DocumentEntry struct {
+	Type         string          `json:"type" yaml:"type"`
+	Message      string          `json:"message" yaml:"message"`
+	Issues       []DocumentLink  `json:"issues" yaml:"issues"`
+	PullRequests []DocumentLink  `json:"pull_requests" yaml:"pull_requests"`
+	Contributors []string        `json:"contributors" yaml:"contributors"`
+	Details      DocumentDetails `json:"details" yaml:"details"`
+	// The snippet file relative to the repository root.
+	File string `json:"file" yaml:"file"`
+	// The module of the snippet. Only set for project changelogs.
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+	// The GitHub URL of the repository that contains the snippet.
+	Repository string `json:"repository" yaml:"repository"`
+}
+
+// DocumentLink is a resolved issue or pull request.
+type DocumentLink struct {
+	// The short reference. (e.g., "Graylog2/graylog2-server#123")
+	Reference string `json:"reference" yaml:"reference"`
+	URL       string `json:"url" yaml:"url"`
+}
+
+type DocumentDetails struct {
+	User string `json:"user" yaml:"user"`
+	Ops  string `json:"ops" yaml:"ops"`
+}
+
+// StructuredRenderer renders the complete changelog document at once instead of the single sections.
+type StructuredRenderer interface {
+	Renderer
+
+	RenderDocument(config Config, document Document, buf *bytes.Buffer) error
+}
+
+// MultiDocumentRenderer renders the documents of multiple versions at once. (see RenderRange) Structured renderers
+// without support for multiple documents render each version on its own.
+type MultiDocumentRenderer interface {
+	StructuredRenderer
+
+	RenderDocuments(config Config, documents []Document, buf *bytes.Buffer) error
+}
+
+// NewDocument resolves the given snippets into a changelog document. The entries are ordered by type.
+func NewDocument(config Config, snippets map[string][]Snippet) (Document, error) {
+	document := Document{
+		SchemaVersion: DocumentSchemaVersion,
+		Product:       config.Product,
+		Version:       config.ReleaseVersion,
+		ReleaseDate:   config.ReleaseDate,
+		Entries:       make([]DocumentEntry, 0),
+	}
+
+	toplevels := make(repositoryToplevels)
+	for _, _type := range sortedTypes {
+		for _, snippet := range snippets[_type] {
+			entry, err := newDocumentEntry(snippet, toplevels.lookup(filepath.Dir(snippet.Filename)))
+			if err != nil {
+				return document, err
+			}
+			document.Entries = append(document.Entries, entry)
+		}
+	}
+
+	return document, nil
+}
+
+func newDocumentEntry(snippet Snippet, toplevel string) (DocumentEntry, error) {
+	entry := DocumentEntry{
+		Type:    snippet.Type,
+		Message: strings.TrimSpace(snippet.Message),
+		Contributors: lo.Filter(snippet.Contributors, func(name string, _ int) bool {
+			return strings.TrimSpace(name) != ""
+		}),
+		Details: DocumentDetails{
+			User: strings.TrimSpace(snippet.Details.User),
+			Ops:  strings.TrimSpace(snippet.Details.Operators),
+		},
+		File:       snippetRepositoryPath(toplevel, snippet.Filename),
+		Module:     snippet.Module,
+		Repository: snippet.GitHubRepoURL,
+	}
+
+	var err error
+	if entry.Issues, err = documentLinks(snippet, snippet.Issues); err != nil {
+		return entry, err
+	}
+	if entry.PullRequests, err = documentLinks(snippet, snippet.PullRequests); err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+func documentLinks(snippet Snippet, values []string) ([]DocumentLink, error) {
+	links := make([]DocumentLink, 0, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		issueURL, err := utils.ResolveGitHubIssueURL(snippet.GitHubRepoURL, value)
+		if err != nil {
+			return nil, &SnippetRenderError{snippet, err}
+		}
+		links = append(links, DocumentLink{
+			Reference: utils.PrettifyGitHubIssueURL(issueURL, utils.PrettyModeOrgRepo),
+			URL:       issueURL,
+		})
+	}
+	return links, nil
+}
+
+// Caches the repository roots of the snippet directories, so git only runs once per directory.
+type repositoryToplevels map[string]string
+
+// Returns the repository root of the given directory or an empty string if it's not in a repository.
+func (t repositoryToplevels) lookup(dir string) string {
+	if toplevel, ok := t[dir]; ok {
+		return toplevel
+	}
+	toplevel, err := git.ToplevelPathOf(dir)
+	if err != nil {
+		toplevel = ""
+	}
+	t[dir] = toplevel
+	return toplevel
+}
+
+// Returns the snippet file relative to the given repository root, so the output doesn't depend on the checkout
+// location. The filename is returned as is without a repository root.
+func snippetRepositoryPath(toplevel string, filename string) string {
+	if toplevel == "" {
+		return filename
+	}
+	if resolved, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = resolved
+	}
+	rel, err := filepath.Rel(toplevel, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Implements the section methods of the Renderer interface for the structured formatters. They are never called
+// because Render uses RenderDocument for structured formatters.
+type structuredFormatter struct {
+}
+
+func (s structuredFormatter) RenderHeader(Config, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+func (s structuredFormatter) RenderType(Config, string, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+func (s structuredFormatter) RenderGroup(Config, string, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+func (s structuredFormatter) RenderSnippets(Config, []Snippet, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+func (s structuredFormatter) RenderDetails(Config, []DetailsEntry, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+func (s structuredFormatter) RenderNoChanges(Config, *bytes.Buffer) error {
+	return errStructuredFormatter
+}
+
+type JSONFormatter struct {
+	structuredFormatter
+}
+
+func (j JSONFormatter) RenderDocument(_ Config, document Document, buf *bytes.Buffer) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("couldn't encode changelog as JSON: %w", err)
+	}
+	return nil
+}
+
+// RenderDocuments renders the documents as a JSON array.
+func (j JSONFormatter) RenderDocuments(_ Config, documents []Document, buf *bytes.Buffer) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(documents); err != nil {
+		return fmt.Errorf("couldn't encode changelogs as JSON: %w", err)
+	}
+	return nil
+}
+
+type YAMLFormatter struct {
+	structuredFormatter
+}
+
+func (y YAMLFormatter) RenderDocument(_ Config, document Document, buf *bytes.Buffer) error {
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("couldn't encode changelog as YAML: %w", err)
+	}
+	return encoder.Close()
+}
+
+// RenderDocuments renders the documents as a YAML stream with one YAML document per version.
+func (y YAMLFormatter) RenderDocuments(_ Config, documents []Document, buf *bytes.Buffer) error {
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return fmt.Errorf("couldn't encode changelog %s as YAML: %w", document.Version, err)
+		}
+	}
+	return encoder.Close()
+}
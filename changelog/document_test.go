@@ -0,0 +1,112 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRenderDocument(t *testing.T) {
+	repository := t.TempDir()
+	output, err := exec.Command("git", "init", "--quiet", repository).CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	dir := filepath.Join(repository, "changelog", "6.2.0")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pr-1.toml"), []byte(`type = "f"
+message = "Fix the thing."
+issues = ["Graylog2/graylog-plugin-enterprise#12"]
+pulls = ["34"]
+contributors = ["@jane", ""]
+details.user = "Check the thing."
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pr-2.toml"), []byte(`type = "a"
+message = "Add a thing."
+`), 0644))
+
+	config := Config{
+		RenderFormat:   FormatJSON,
+		SnippetsPaths:  []string{dir},
+		ReleaseDate:    "2024-05-02",
+		ReleaseVersion: "6.2.0",
+		Product:        "Graylog",
+		GitHubRepoURL:  "https://github.com/Graylog2/graylog2-server",
+		Modules:        map[string]string{dir: "graylog-server"},
+	}
+
+	expected := Document{
+		SchemaVersion: DocumentSchemaVersion,
+		Product:       "Graylog",
+		Version:       "6.2.0",
+		ReleaseDate:   "2024-05-02",
+		Entries: []DocumentEntry{
+			{
+				Type:         TypeAdded,
+				Message:      "Add a thing.",
+				Issues:       []DocumentLink{},
+				PullRequests: []DocumentLink{},
+				Contributors: []string{},
+				File:         "changelog/6.2.0/pr-2.toml",
+				Module:       "graylog-server",
+				Repository:   "https://github.com/Graylog2/graylog2-server",
+			},
+			{
+				Type:    TypeFixed,
+				Message: "Fix the thing.",
+				Issues: []DocumentLink{
+					{Reference: "Graylog2/graylog-plugin-enterprise#12", URL: "https://github.com/Graylog2/graylog-plugin-enterprise/issues/12"},
+				},
+				PullRequests: []DocumentLink{
+					{Reference: "Graylog2/graylog2-server#34", URL: "https://github.com/Graylog2/graylog2-server/issues/34"},
+				},
+				Contributors: []string{"@jane"},
+				Details:      DocumentDetails{User: "Check the thing."},
+				File:         "changelog/6.2.0/pr-1.toml",
+				Module:       "graylog-server",
+				Repository:   "https://github.com/Graylog2/graylog2-server",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(config, &buf))
+	var jsonDocument Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonDocument))
+	assert.Equal(t, expected, jsonDocument)
+	assert.Contains(t, buf.String(), `"schema_version": 1,`)
+
+	buf.Reset()
+	config.RenderFormat = FormatYAML
+	require.NoError(t, Render(config, &buf))
+	var yamlDocument Document
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &yamlDocument))
+	assert.Equal(t, expected, yamlDocument)
+	assert.Contains(t, buf.String(), "schema_version: 1\n")
+}
+
+func TestRepositoryToplevels(t *testing.T) {
+	repository := t.TempDir()
+	output, err := exec.Command("git", "init", "--quiet", repository).CombinedOutput()
+	require.NoError(t, err, string(output))
+	dir := filepath.Join(repository, "changelog", "unreleased")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	outside := t.TempDir()
+
+	toplevels := make(repositoryToplevels)
+	toplevel := toplevels.lookup(dir)
+	require.NotEmpty(t, toplevel)
+	assert.Equal(t, "", toplevels.lookup(outside))
+	assert.Len(t, toplevels, 2)
+
+	filename := filepath.Join(dir, "pr-1.toml")
+	assert.Equal(t, "changelog/unreleased/pr-1.toml", snippetRepositoryPath(toplevel, filename))
+	// Files outside of a repository keep their path
+	assert.Equal(t, filepath.Join(outside, "pr-1.toml"), snippetRepositoryPath("", filepath.Join(outside, "pr-1.toml")))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/samber/lo"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"html"
 	"strings"
 )
 
@@ -15,7 +16,42 @@ const FormatD360HTML = "d360html"
 const FormatMarkdown = "markdown"
 const FormatMD = "md"
 
-var AvailableFormatters = []string{FormatHTML, FormatD360HTML, FormatMD, FormatMarkdown}
+var AvailableFormatters = []string{FormatHTML, FormatD360HTML, FormatMD, FormatMarkdown, FormatJSON, FormatYAML, FormatTemplate}
+
+// Render all details in separate sections after the type sections.
+const DetailsAggregated = "aggregated"
+
+// Render the details below each entry.
+const DetailsInline = "inline"
+
+// Don't render any details.
+const DetailsOmit = "omit"
+
+var AvailableDetailsModes = []string{DetailsAggregated, DetailsInline, DetailsOmit}
+
+// DetailsEntry is a snippet in an aggregated details section together with the details of the section.
+type DetailsEntry struct {
+	Snippet Snippet
+	Details string
+}
+
+// A kind of snippet details with the title of its section.
+type detailsKind struct {
+	title string
+	value func(snippet Snippet) string
+}
+
+var detailsKinds = []detailsKind{
+	{title: "Upgrade notes", value: func(snippet Snippet) string { return snippet.Details.User }},
+	{title: "Operations notes", value: func(snippet Snippet) string { return snippet.Details.Operators }},
+}
+
+func detailsMode(config Config) string {
+	if config.Details == "" {
+		return DetailsAggregated
+	}
+	return config.Details
+}
 
 var renderers = map[string]Renderer{}
 
@@ -26,8 +62,14 @@ type Renderer interface {
 
 	RenderType(config Config, snippetType string, buf *bytes.Buffer) error
 
+	// RenderGroup renders the title of a group in a grouped changelog. (see Config.Groups)
+	RenderGroup(config Config, title string, buf *bytes.Buffer) error
+
 	RenderSnippets(config Config, snippets []Snippet, buf *bytes.Buffer) error
 
+	// RenderDetails renders the entries of an aggregated details section. (e.g., "Upgrade notes")
+	RenderDetails(config Config, entries []DetailsEntry, buf *bytes.Buffer) error
+
 	RenderNoChanges(config Config, buf *bytes.Buffer) error
 }
 
@@ -85,6 +127,45 @@ func mdFormatContributors(snippet Snippet) []string {
 	})
 }
 
+func htmlFormatDetails(snippet Snippet, details string, buf *bytes.Buffer) error {
+	if err := mdDetailsRenderer.Convert([]byte(details), buf); err != nil {
+		return &SnippetRenderError{snippet, fmt.Errorf("couldn't convert details to HTML: %w", err)}
+	}
+	return nil
+}
+
+func htmlRenderInlineDetails(config Config, snippet Snippet, buf *bytes.Buffer) error {
+	if detailsMode(config) != DetailsInline {
+		return nil
+	}
+	for _, kind := range detailsKinds {
+		if details := strings.TrimSpace(kind.value(snippet)); details != "" {
+			buf.WriteString(fmt.Sprintf("\n<p><strong>%s:</strong></p>\n", kind.title))
+			if err := htmlFormatDetails(snippet, details, buf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func htmlRenderDetails(entries []DetailsEntry, buf *bytes.Buffer) error {
+	buf.WriteString("<ul>\n")
+	for _, entry := range entries {
+		buf.WriteString("  <li>")
+		if err := mdMessageRenderer.Convert([]byte(entry.Snippet.Message), buf); err != nil {
+			return fmt.Errorf("couldn't convert message to HTML \"%s\": %w", entry.Snippet.Message, err)
+		}
+		buf.WriteString("\n")
+		if err := htmlFormatDetails(entry.Snippet, entry.Details, buf); err != nil {
+			return err
+		}
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("</ul>\n")
+	return nil
+}
+
 type HTMLFormatter struct {
 }
 
@@ -95,9 +176,14 @@ func (h HTMLFormatter) RenderHeader(config Config, buf *bytes.Buffer) error {
 }
 
 func (h HTMLFormatter) RenderType(config Config, snippetType string, buf *bytes.Buffer) error {
-	buf.WriteString("<h2>")
+	buf.WriteString(fmt.Sprintf("<h%d>", 2+config.sectionLevel))
 	buf.WriteString(titleCaser.String(snippetType))
-	buf.WriteString("</h2>\n")
+	buf.WriteString(fmt.Sprintf("</h%d>\n", 2+config.sectionLevel))
+	return nil
+}
+
+func (h HTMLFormatter) RenderGroup(config Config, title string, buf *bytes.Buffer) error {
+	buf.WriteString(fmt.Sprintf("<h2>%s</h2>\n\n", html.EscapeString(title)))
 	return nil
 }
 
@@ -133,13 +219,19 @@ func (h HTMLFormatter) RenderSnippets(config Config, snippets []Snippet, buf *by
 			buf.WriteString(fmt.Sprintf(` (Thanks: %s)`, strings.Join(formattedContributors, ", ")))
 		}
 
-		// TODO: Write details
+		if err := htmlRenderInlineDetails(config, snippet, buf); err != nil {
+			return err
+		}
 		buf.WriteString("</li>\n")
 	}
 	buf.WriteString("</ul>\n")
 	return nil
 }
 
+func (h HTMLFormatter) RenderDetails(_ Config, entries []DetailsEntry, buf *bytes.Buffer) error {
+	return htmlRenderDetails(entries, buf)
+}
+
 func (h HTMLFormatter) RenderNoChanges(_ Config, buf *bytes.Buffer) error {
 	buf.WriteString(`<p><em>No changes since last release.</em></p>`)
 	buf.WriteString("\n")
@@ -163,6 +255,11 @@ func (h D360HTMLFormatter) RenderType(config Config, snippetType string, buf *by
 	return nil
 }
 
+func (h D360HTMLFormatter) RenderGroup(config Config, title string, buf *bytes.Buffer) error {
+	buf.WriteString(fmt.Sprintf("<h3>%s</h3>\n\n", html.EscapeString(title)))
+	return nil
+}
+
 func (h D360HTMLFormatter) RenderSnippets(config Config, snippets []Snippet, buf *bytes.Buffer) error {
 	buf.WriteString("<ul>\n")
 	for _, snippet := range snippets {
@@ -195,13 +292,19 @@ func (h D360HTMLFormatter) RenderSnippets(config Config, snippets []Snippet, buf
 			buf.WriteString(fmt.Sprintf(` (Thanks: %s)`, strings.Join(formattedContributors, ", ")))
 		}
 
-		// TODO: Write details
+		if err := htmlRenderInlineDetails(config, snippet, buf); err != nil {
+			return err
+		}
 		buf.WriteString("</li>\n")
 	}
 	buf.WriteString("</ul>\n")
 	return nil
 }
 
+func (h D360HTMLFormatter) RenderDetails(_ Config, entries []DetailsEntry, buf *bytes.Buffer) error {
+	return htmlRenderDetails(entries, buf)
+}
+
 func (h D360HTMLFormatter) RenderNoChanges(_ Config, buf *bytes.Buffer) error {
 	buf.WriteString(`<p><em>No changes since last release.</em></p>`)
 	buf.WriteString("\n")
@@ -218,12 +321,17 @@ func (m MarkdownFormatter) RenderHeader(config Config, buf *bytes.Buffer) error
 }
 
 func (m MarkdownFormatter) RenderType(config Config, snippetType string, buf *bytes.Buffer) error {
-	buf.WriteString(fmt.Sprintf("%s ", strings.Repeat("#", config.MarkdownHeaderBaseLevel+1)))
+	buf.WriteString(fmt.Sprintf("%s ", strings.Repeat("#", config.MarkdownHeaderBaseLevel+1+config.sectionLevel)))
 	buf.WriteString(titleCaser.String(snippetType))
 	buf.WriteString("\n\n")
 	return nil
 }
 
+func (m MarkdownFormatter) RenderGroup(config Config, title string, buf *bytes.Buffer) error {
+	buf.WriteString(fmt.Sprintf("%s %s\n\n", strings.Repeat("#", config.MarkdownHeaderBaseLevel+1), title))
+	return nil
+}
+
 func (m MarkdownFormatter) RenderSnippets(config Config, snippets []Snippet, buf *bytes.Buffer) error {
 	for _, snippet := range snippets {
 		buf.WriteString("- ")
@@ -245,10 +353,43 @@ func (m MarkdownFormatter) RenderSnippets(config Config, snippets []Snippet, buf
 		}
 
 		buf.WriteString("\n")
+
+		if detailsMode(config) == DetailsInline {
+			for _, kind := range detailsKinds {
+				if details := strings.TrimSpace(kind.value(snippet)); details != "" {
+					buf.WriteString(fmt.Sprintf("\n  **%s:**\n\n", kind.title))
+					mdWriteIndented(details, buf)
+				}
+			}
+		}
 	}
 	return nil
 }
 
+func (m MarkdownFormatter) RenderDetails(_ Config, entries []DetailsEntry, buf *bytes.Buffer) error {
+	for i, entry := range entries {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("- ")
+		buf.WriteString(entry.Snippet.Message)
+		buf.WriteString("\n\n")
+		mdWriteIndented(entry.Details, buf)
+	}
+	return nil
+}
+
+// Writes the given Markdown indented, so it becomes part of the previous list item.
+func mdWriteIndented(text string, buf *bytes.Buffer) {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			buf.WriteString("  ")
+			buf.WriteString(line)
+		}
+		buf.WriteString("\n")
+	}
+}
+
 func (m MarkdownFormatter) RenderNoChanges(_ Config, buf *bytes.Buffer) error {
 	buf.WriteString(`*No changes since last release.*`)
 	buf.WriteString("\n")
@@ -260,6 +401,9 @@ func init() {
 	renderers[FormatD360HTML] = D360HTMLFormatter{}
 	renderers[FormatMD] = MarkdownFormatter{}
 	renderers[FormatMarkdown] = MarkdownFormatter{}
+	renderers[FormatJSON] = JSONFormatter{}
+	renderers[FormatYAML] = YAMLFormatter{}
+	renderers[FormatTemplate] = TemplateFormatter{}
 }
 
 func GetRenderer(format string) (Renderer, error) {
@@ -0,0 +1,124 @@
+package changelog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/logger"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/samber/lo"
+)
+
+// Render the entries of all modules in the same sections.
+const GroupMerged = "merged"
+
+// Render a section for every module.
+const GroupModule = "module"
+
+// Render a section for every product. (the assemblies of the modules)
+const GroupProduct = "product"
+
+var AvailableGroupModes = []string{GroupMerged, GroupModule, GroupProduct}
+
+// The product group title for modules without assemblies.
+const otherGroupTitle = "Other"
+
+// Group is a section of a grouped project changelog. (e.g., a module or product)
+type Group struct {
+	Title string
+	// The snippets paths of the group.
+	Paths []string
+}
+
+// NewGroups returns the groups for the given modules and grouping mode. The snippetsPath function returns the
+// snippets path of a module. Modules are grouped by their display name. (see project.Module.Title) Products are
+// the assemblies of a module and its submodules, so the same module can be part of several product groups.
+// The merged mode doesn't have any groups.
+func NewGroups(groupBy string, modules []p.Module, snippetsPath func(module p.Module) string) ([]Group, error) {
+	switch groupBy {
+	case "", GroupMerged:
+		return nil, nil
+	case GroupModule:
+		return lo.Map(modules, func(module p.Module, _ int) Group {
+			return Group{Title: module.Title(), Paths: []string{snippetsPath(module)}}
+		}), nil
+	case GroupProduct:
+		groups := make([]Group, 0)
+		index := make(map[string]int)
+		for _, module := range modules {
+			assemblies := lo.Union(module.Assemblies, lo.FlatMap(module.Submodules, func(submodule p.Module, _ int) []string {
+				return submodule.Assemblies
+			}))
+			if len(assemblies) == 0 {
+				assemblies = []string{otherGroupTitle}
+			}
+			for _, assembly := range assemblies {
+				if _, ok := index[assembly]; !ok {
+					index[assembly] = len(groups)
+					groups = append(groups, Group{Title: assembly})
+				}
+				groups[index[assembly]].Paths = append(groups[index[assembly]].Paths, snippetsPath(module))
+			}
+		}
+		// Modules without assemblies go last
+		if i, ok := index[otherGroupTitle]; ok {
+			other := groups[i]
+			groups = append(slices.Delete(groups, i, i+1), other)
+		}
+		return groups, nil
+	default:
+		return nil, fmt.Errorf("invalid group mode: %s (available: %s)", groupBy, strings.Join(AvailableGroupModes, ", "))
+	}
+}
+
+// Returns the snippets of the given group.
+func groupSnippets(group Group, snippets map[string][]Snippet) map[string][]Snippet {
+	result := make(map[string][]Snippet)
+	for _type, typeSnippets := range snippets {
+		for _, snippet := range typeSnippets {
+			if lo.Contains(group.Paths, snippet.snippetsPath) {
+				result[_type] = append(result[_type], snippet)
+			}
+		}
+	}
+	return result
+}
+
+// Tracks the issues and pull requests of the parsed snippets to skip snippets that reference the same issue or pull
+// request as a snippet from another repository. (e.g., a change in the server and in the enterprise plugin) The
+// first snippet wins. Snippets in the same repository are never duplicates, because a single pull request can
+// contain several changes.
+type snippetDeduplicator map[string]string
+
+// Returns true if the snippet is a duplicate. Otherwise, the references of the snippet are recorded.
+func (d snippetDeduplicator) isDuplicate(snippet Snippet) bool {
+	repository := strings.TrimSuffix(snippet.GitHubRepoURL, ".git")
+	references := make([]string, 0)
+
+	for _, value := range append(append([]string{}, snippet.Issues...), snippet.PullRequests...) {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		issueURL, err := utils.ResolveGitHubIssueURL(snippet.GitHubRepoURL, strings.TrimSpace(value))
+		if err != nil {
+			// Invalid references are reported when rendering the snippet
+			continue
+		}
+		// Issues and pull requests share the same numbers
+		reference := strings.Replace(issueURL, "/pull/", "/issues/", 1)
+		if other, ok := d[reference]; ok && other != repository {
+			logger.Debug("Skipping snippet %s because %s is already referenced in %s", snippet.Filename, reference, other)
+			return true
+		}
+		references = append(references, reference)
+	}
+
+	for _, reference := range references {
+		if _, ok := d[reference]; !ok {
+			d[reference] = repository
+		}
+	}
+	return false
+}
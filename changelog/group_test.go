@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGroups(t *testing.T) {
+	server := p.Module{Name: "graylog-server", DisplayName: "Graylog Server", Path: "server", Assemblies: []string{"server"}}
+	web := p.Module{Name: "graylog-web", Path: "web"}
+	enterprise := p.Module{Name: "graylog-enterprise", Path: "enterprise", Submodules: []p.Module{
+		{Name: "enterprise-server", Assemblies: []string{"enterprise"}},
+		{Name: "enterprise-datanode", Assemblies: []string{"datanode"}},
+	}}
+	modules := []p.Module{server, web, enterprise}
+	snippetsPath := func(module p.Module) string {
+		return filepath.Join(module.Path, "changelog/unreleased")
+	}
+
+	groups, err := NewGroups(GroupMerged, modules, snippetsPath)
+	require.NoError(t, err)
+	assert.Nil(t, groups)
+
+	groups, err = NewGroups(GroupModule, modules, snippetsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Group{
+		{Title: "Graylog Server", Paths: []string{"server/changelog/unreleased"}},
+		{Title: "graylog-web", Paths: []string{"web/changelog/unreleased"}},
+		{Title: "graylog-enterprise", Paths: []string{"enterprise/changelog/unreleased"}},
+	}, groups)
+
+	groups, err = NewGroups(GroupProduct, modules, snippetsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []Group{
+		{Title: "server", Paths: []string{"server/changelog/unreleased"}},
+		{Title: "enterprise", Paths: []string{"enterprise/changelog/unreleased"}},
+		{Title: "datanode", Paths: []string{"enterprise/changelog/unreleased"}},
+		{Title: "Other", Paths: []string{"web/changelog/unreleased"}},
+	}, groups)
+
+	_, err = NewGroups("foo", modules, snippetsPath)
+	assert.Error(t, err)
+}
+
+func writeTestRepositorySnippets(t *testing.T, repository string, snippets map[string]string) string {
+	repo := t.TempDir()
+	require.NoError(t, git.Exec("init", "--initial-branch=main", repo))
+	require.NoError(t, git.ExecInPath(repo, "remote", "add", "origin", repository))
+
+	path := filepath.Join(repo, "changelog", "unreleased")
+	require.NoError(t, os.MkdirAll(path, 0755))
+	for name, content := range snippets {
+		require.NoError(t, os.WriteFile(filepath.Join(path, name), []byte(content), 0644))
+	}
+	return path
+}
+
+func TestRenderGroups(t *testing.T) {
+	server := writeTestRepositorySnippets(t, "https://github.com/Graylog2/graylog2-server.git", map[string]string{
+		"pr-1.toml": "type = \"a\"\nmessage = \"Add server thing.\"\npulls = [\"1\"]\n",
+		"pr-2.toml": "type = \"f\"\nmessage = \"Fix shared thing.\"\nissues = [\"10\"]\npulls = [\"2\"]\n",
+		"pr-3.toml": "type = \"c\"\nmessage = \"Change shared thing.\"\npulls = [\"2\"]\n",
+	})
+	enterprise := writeTestRepositorySnippets(t, "https://github.com/Graylog2/graylog-plugin-enterprise.git", map[string]string{
+		"pr-5.toml": "type = \"a\"\nmessage = \"Add enterprise thing.\"\npulls = [\"5\"]\n",
+		"pr-6.toml": "type = \"f\"\nmessage = \"Fix shared thing in enterprise.\"\nissues = [\"Graylog2/graylog2-server#10\"]\npulls = [\"6\"]\n",
+	})
+
+	config := Config{
+		RenderFormat:            FormatMD,
+		SnippetsPaths:           []string{server, enterprise},
+		MarkdownHeaderBaseLevel: 1,
+		SkipHeader:              true,
+		Groups: []Group{
+			{Title: "Graylog Server", Paths: []string{server}},
+			{Title: "Graylog Enterprise", Paths: []string{enterprise}},
+			{Title: "Empty", Paths: []string{}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(config, &buf))
+	assert.Equal(t, `## Graylog Server
+
+### Added
+
+- Add server thing.
+
+### Changed
+
+- Change shared thing.
+
+### Fixed
+
+- Fix shared thing.
+
+## Graylog Enterprise
+
+### Added
+
+- Add enterprise thing.
+
+`, buf.String())
+
+	// Without groups, the snippets aren't de-duplicated
+	buf.Reset()
+	config.Groups = nil
+	require.NoError(t, Render(config, &buf))
+	assert.Equal(t, `## Added
+
+- Add server thing.
+- Add enterprise thing.
+
+## Changed
+
+- Change shared thing.
+
+## Fixed
+
+- Fix shared thing.
+- Fix shared thing in enterprise.
+
+`, buf.String())
+}
@@ -3,17 +3,63 @@ package changelog
 import (
 	"bytes"
 	"fmt"
+	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/samber/lo"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 )
 
-func LintPaths(paths []string, strict bool) error {
+const lintGitHubRepoURL = "https://github.com/Graylog2/___linter-test___.git"
+
+type LintOptions struct {
+	// Return an error if no files got linted.
+	Strict bool
+	// Checks the referenced issues and pull requests on GitHub if set. The references are resolved against the
+	// repository of the snippet file instead of a test repository in that case.
+	GitHub *gh.Client
+}
+
+// The snippet types that match common pull request labels. Labels that aren't listed here are ignored.
+var labelTypes = map[string][]string{
+	"bug":         {TypeFixed, TypeSecurity},
+	"security":    {TypeSecurity},
+	"enhancement": {TypeAdded, TypeChanged},
+	"feature":     {TypeAdded, TypeChanged},
+	"deprecation": {TypeDeprecated, TypeRemoved},
+}
+
+// Leading words of a message that repeat the snippet type.
+var redundantTypePrefixes = map[string][]string{
+	TypeFixed: {"fixed", "fixes"},
+}
+
+// Collects the results of a single snippet file.
+type lintResult struct {
+	file     string
+	errors   []error
+	warnings []string
+}
+
+func (r *lintResult) error(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Errorf("linter error in file %s: %s", r.file, fmt.Sprintf(format, args...)))
+}
+
+func (r *lintResult) warning(format string, args ...any) {
+	r.warnings = append(r.warnings, fmt.Sprintf("linter warning in file %s: %s", r.file, fmt.Sprintf(format, args...)))
+}
+
+// LintPaths checks the snippet files in the given paths for syntax and content errors. Message style violations are
+// reported as warnings and don't fail the linter.
+func LintPaths(paths []string, options LintOptions) error {
 	errors := make([]error, 0)
+	warnings := make([]string, 0)
 	fileCnt := 0
 	okCnt := 0
+	issues := make(map[string]*gh.Issue)
 
 	for _, path := range paths {
 		snippetFiles, err := listSnippets(path)
@@ -25,77 +71,174 @@ func LintPaths(paths []string, strict bool) error {
 			fileCnt += 1
 
 			logger.Debug("Linting %s", file)
-			snippet, err := parseSnippet(file, "https://github.com/Graylog2/___linter-test___.git")
-			if err != nil {
-				errors = append(errors, fmt.Errorf("linter error in file %s: %w", file, err))
-				continue
+			result := lintSnippet(file, options, issues)
+			errors = append(errors, result.errors...)
+			warnings = append(warnings, result.warnings...)
+			if len(result.errors) == 0 {
+				okCnt += 1
 			}
+		}
+	}
 
-			if strings.TrimSpace(snippet.Message) == "" {
-				errors = append(errors, fmt.Errorf("linter error in file %s: message cannot be empty", file))
-				continue
-			}
+	for _, warning := range warnings {
+		logger.Info(warning)
+	}
 
-			urlList := make([]string, 0)
-			for _, issuesOrPulls := range [][]string{snippet.Issues, snippet.PullRequests} {
-				for _, value := range issuesOrPulls {
-					if strings.TrimSpace(value) == "" {
-						continue
-					}
-					url, err := utils.ResolveGitHubIssueURL(snippet.GitHubRepoURL, value)
-					if err != nil {
-						errors = append(errors, fmt.Errorf("linter error in file %s: %w", file, err))
-						continue
-					}
-					urlList = append(urlList, url)
-				}
-			}
+	logger.Info("Linted %d snippet file(s) - ok=%d error=%d warning=%d", fileCnt, okCnt, len(errors), len(warnings))
 
-			// The GitHub security advisories (GHSA) might not have linked issues or pulls because the PRs might be
-			// created in private forks as part of the security advisory process.
-			if len(urlList) == 0 && !strings.HasPrefix(strings.ToLower(filepath.Base(file)), "ghsa-") {
-				errors = append(errors, fmt.Errorf("linter error in file %s: at least one issue or pull request number needs to be present", file))
-				continue
-			}
+	if len(errors) > 0 {
+		for _, e := range errors {
+			logger.Error(e.Error())
+		}
 
-			logger.Debug("Rendering %s", file)
-			renderConfig := Config{
-				RenderFormat:            FormatMD,
-				RenderGitHubLinks:       true,
-				SnippetsPaths:           []string{file},
-				ReleaseDate:             time.Now().Format(time.DateOnly),
-				ReleaseVersion:          "1.0.0",
-				Product:                 "Render Test",
-				SkipHeader:              false,
-				RenderNoChanges:         false,
-				SkipInvalidSnippets:     false,
-				ReadStdin:               false,
-				MarkdownHeaderBaseLevel: 1,
-				GitHubRepoURL:           "https://github.com/Graylog2/___linter-test___.git",
+		return fmt.Errorf("detected errors in %d file(s)", fileCnt-okCnt)
+	}
+
+	if okCnt == 0 && len(errors) == 0 && options.Strict {
+		return fmt.Errorf("no files found for path(s): %s", strings.Join(paths, ", "))
+	}
+
+	return nil
+}
+
+func lintSnippet(file string, options LintOptions, issues map[string]*gh.Issue) lintResult {
+	result := lintResult{file: file}
+
+	githubRepoURL := lintGitHubRepoURL
+	if options.GitHub != nil {
+		// Resolve the references against the real repository
+		githubRepoURL = ""
+	}
+
+	snippet, err := parseSnippet(file, githubRepoURL)
+	if err != nil {
+		result.error("%s", err)
+		return result
+	}
+
+	if strings.TrimSpace(snippet.Message) == "" {
+		result.error("message cannot be empty")
+		return result
+	}
+
+	urlList := make([]string, 0)
+	for _, issuesOrPulls := range [][]string{snippet.Issues, snippet.PullRequests} {
+		for _, value := range issuesOrPulls {
+			if strings.TrimSpace(value) == "" {
+				continue
 			}
-			var output bytes.Buffer
-			if err := Render(renderConfig, &output); err != nil {
-				errors = append(errors, fmt.Errorf("linter error in file: %s: %w", file, err))
+			url, err := utils.ResolveGitHubIssueURL(snippet.GitHubRepoURL, value)
+			if err != nil {
+				result.error("%s", err)
+				continue
 			}
-			logger.Debug("Render output for file %s:\n%s", file, output.String())
+			urlList = append(urlList, url)
+		}
+	}
+
+	// The GitHub security advisories (GHSA) might not have linked issues or pulls because the PRs might be
+	// created in private forks as part of the security advisory process.
+	if len(urlList) == 0 && !strings.HasPrefix(strings.ToLower(filepath.Base(file)), "ghsa-") {
+		result.error("at least one issue or pull request number needs to be present")
+		return result
+	}
 
-			okCnt += 1
+	lintMessageStyle(&result, *snippet)
+
+	if options.GitHub != nil {
+		for _, url := range lo.Uniq(urlList) {
+			lintGitHubReference(&result, options.GitHub, issues, *snippet, url)
 		}
 	}
 
-	logger.Info("Linted %d snippet file(s) - ok=%d error=%d", fileCnt, okCnt, len(errors))
+	logger.Debug("Rendering %s", file)
+	renderConfig := Config{
+		RenderFormat:            FormatMD,
+		RenderGitHubLinks:       true,
+		SnippetsPaths:           []string{file},
+		ReleaseDate:             time.Now().Format(time.DateOnly),
+		ReleaseVersion:          "1.0.0",
+		Product:                 "Render Test",
+		SkipHeader:              false,
+		RenderNoChanges:         false,
+		SkipInvalidSnippets:     false,
+		ReadStdin:               false,
+		MarkdownHeaderBaseLevel: 1,
+		GitHubRepoURL:           snippet.GitHubRepoURL,
+	}
+	var output bytes.Buffer
+	if err := Render(renderConfig, &output); err != nil {
+		result.error("%s", err)
+	}
+	logger.Debug("Render output for file %s:\n%s", file, output.String())
 
-	if len(errors) > 0 {
-		for _, e := range errors {
-			logger.Error(e.Error())
+	return result
+}
+
+// Checks the message for sentence case, a trailing period, and leading words that repeat the snippet type.
+func lintMessageStyle(result *lintResult, snippet Snippet) {
+	message := strings.TrimSpace(snippet.Message)
+
+	if first := []rune(message)[0]; unicode.IsLetter(first) && !unicode.IsUpper(first) {
+		result.warning("message should start with an uppercase letter")
+	}
+
+	if !strings.HasSuffix(message, ".") {
+		result.warning("message should end with a period")
+	}
+
+	firstWord := strings.ToLower(strings.TrimRight(strings.Fields(message)[0], ":,"))
+	if lo.Contains(redundantTypePrefixes[snippet.Type], firstWord) {
+		result.warning("message shouldn't start with %q because the type is already %q", strings.Fields(message)[0], snippet.Type)
+	}
+}
+
+// Checks that the referenced issue or pull request exists, that pull requests are merged, and that the snippet type
+// matches the pull request labels. The issues map caches the results across snippets.
+func lintGitHubReference(result *lintResult, client *gh.Client, issues map[string]*gh.Issue, snippet Snippet, url string) {
+	reference := utils.PrettifyGitHubIssueURL(url, utils.PrettyModeOrgRepo)
+
+	issue, ok := issues[reference]
+	if !ok {
+		repository, number, err := utils.ParseGitHubPRString(reference)
+		if err != nil {
+			result.error("%s", err)
+			return
+		}
+		owner, repo, err := gh.SplitRepoString(repository)
+		if err != nil {
+			result.error("%s", err)
+			return
 		}
 
-		return fmt.Errorf("detected errors in %d file(s)", len(errors))
+		logger.Debug("Fetching %s from GitHub", reference)
+		issue, err = client.GetIssue(owner, repo, number)
+		if err != nil {
+			result.error("%s", err)
+			return
+		}
+		issues[reference] = issue
 	}
 
-	if okCnt == 0 && len(errors) == 0 && strict {
-		return fmt.Errorf("no files found for path(s): %s", strings.Join(paths, ", "))
+	if issue == nil {
+		result.error("%s doesn't exist", reference)
+		return
+	}
+	if !issue.PullRequest {
+		return
 	}
 
-	return nil
+	if issue.State == "open" {
+		result.warning("pull request %s is still open", reference)
+	} else if !issue.Merged {
+		result.error("pull request %s has been closed without merging", reference)
+	}
+
+	labelTypeList := lo.Uniq(lo.FlatMap(issue.Labels, func(label string, _ int) []string {
+		return labelTypes[strings.ToLower(label)]
+	}))
+	if len(labelTypeList) > 0 && !lo.Contains(labelTypeList, snippet.Type) {
+		result.warning("type %q doesn't match the labels of pull request %s (%s)", snippet.Type, reference,
+			strings.Join(issue.Labels, ", "))
+	}
 }
@@ -0,0 +1,96 @@
+package changelog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintMessageStyle(t *testing.T) {
+	dir := writeTestSnippets(t, map[string]string{
+		"pr-1.toml": "type = \"f\"\nmessage = \"Fixed the `foo` setting\"\npulls = [\"1\"]\n",
+		"pr-2.toml": "type = \"a\"\nmessage = \"add a thing.\"\npulls = [\"2\"]\n",
+		"pr-3.toml": "type = \"f\"\nmessage = \"Fix the bar setting.\"\npulls = [\"3\"]\n",
+		"pr-4.toml": "type = \"c\"\nmessage = \"`foo` defaults to bar now.\"\npulls = [\"4\"]\n",
+	})
+
+	result := lintSnippet(filepath.Join(dir, "pr-1.toml"), LintOptions{}, nil)
+	assert.Empty(t, result.errors)
+	assert.Len(t, result.warnings, 2)
+	assert.Contains(t, result.warnings[0], "message should end with a period")
+	assert.Contains(t, result.warnings[1], `message shouldn't start with "Fixed"`)
+
+	result = lintSnippet(filepath.Join(dir, "pr-2.toml"), LintOptions{}, nil)
+	assert.Empty(t, result.errors)
+	assert.Len(t, result.warnings, 1)
+	assert.Contains(t, result.warnings[0], "message should start with an uppercase letter")
+
+	for _, name := range []string{"pr-3.toml", "pr-4.toml"} {
+		result = lintSnippet(filepath.Join(dir, name), LintOptions{}, nil)
+		assert.Empty(t, result.errors, name)
+		assert.Empty(t, result.warnings, name)
+	}
+
+	// Warnings don't fail the linter
+	assert.NoError(t, LintPaths([]string{dir}, LintOptions{Strict: true}))
+}
+
+func TestLintGitHub(t *testing.T) {
+	issues := map[string]map[string]any{
+		"1": {"number": 1, "state": "closed", "labels": []any{map[string]any{"name": "bug"}},
+			"pull_request": map[string]any{"url": "x", "merged_at": "2024-05-02T10:00:00Z"}},
+		"2": {"number": 2, "state": "open", "labels": []any{}, "pull_request": map[string]any{"url": "x"}},
+		"3": {"number": 3, "state": "closed", "labels": []any{}, "pull_request": map[string]any{"url": "x"}},
+		"4": {"number": 4, "state": "open", "labels": []any{map[string]any{"name": "bug"}}},
+	}
+	requests := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/issues/{number}", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		issue, ok := issues[r.PathValue("number")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(issue)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := gh.NewGitHubClientWithBaseURL("token", server.URL)
+	require.NoError(t, err)
+
+	dir := writeTestRepositorySnippets(t, "https://github.com/Graylog2/graylog2-server.git", map[string]string{
+		"pr-1.toml": "type = \"a\"\nmessage = \"Add a thing.\"\nissues = [\"4\"]\npulls = [\"1\"]\n",
+		"pr-2.toml": "type = \"f\"\nmessage = \"Fix a thing.\"\npulls = [\"2\", \"3\", \"5\"]\n",
+	})
+	options := LintOptions{GitHub: client}
+	cache := make(map[string]*gh.Issue)
+
+	result := lintSnippet(filepath.Join(dir, "pr-1.toml"), options, cache)
+	assert.Empty(t, result.errors)
+	assert.Len(t, result.warnings, 1)
+	assert.Contains(t, result.warnings[0], `type "added" doesn't match the labels of pull request Graylog2/graylog2-server#1 (bug)`)
+
+	result = lintSnippet(filepath.Join(dir, "pr-2.toml"), options, cache)
+	require.Len(t, result.errors, 2)
+	assert.Contains(t, result.errors[0].Error(), "pull request Graylog2/graylog2-server#3 has been closed without merging")
+	assert.Contains(t, result.errors[1].Error(), "Graylog2/graylog2-server#5 doesn't exist")
+	require.Len(t, result.warnings, 1)
+	assert.Contains(t, result.warnings[0], "pull request Graylog2/graylog2-server#2 is still open")
+
+	// Cached references aren't fetched again
+	assert.Equal(t, 5, requests)
+	lintSnippet(filepath.Join(dir, "pr-1.toml"), options, cache)
+	assert.Equal(t, 5, requests)
+
+	assert.Error(t, LintPaths([]string{dir}, options))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/manifoldco/promptui"
 	"github.com/mattn/go-isatty"
@@ -14,8 +15,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 )
 
 const entryTemplate = `# PLEASE REMOVE COMMENTS AND OPTIONAL FIELDS! THANKS!
@@ -28,7 +31,7 @@ message = "{{ .Message }}"
 issues = [{{ .Issues }}]
 pulls = [{{ .PullRequests }}]
 
-contributors = [""]
+contributors = [{{ or .Contributors "\"\"" }}]
 
 details.user = """
 This text contains a more detailed description of the change for users.
@@ -50,6 +53,10 @@ message = "{{ .Message }}"
 
 issues = [{{ .Issues }}]
 pulls = [{{ .PullRequests }}]
+{{- with .Contributors }}
+
+contributors = [{{ . }}]
+{{- end }}
 `
 
 var filenamePattern = regexp.MustCompile("^(issue|pr)-(\\d+)\\.toml$")
@@ -59,11 +66,12 @@ type TemplateData struct {
 	Message      string
 	Issues       string
 	PullRequests string
+	// The quoted and comma separated contributors. (optional)
+	Contributors string
 }
 
 func NewEntry(path string, edit bool, useMinimalTemplate bool, interactive bool) error {
 	file := filepath.Base(path)
-	directory := filepath.Dir(path)
 
 	if !filenamePattern.MatchString(file) {
 		return fmt.Errorf("invalid entry filename - allowed pattern: %s (Examples: issue-123.toml, pr-456.toml)", filenamePattern)
@@ -80,6 +88,30 @@ func NewEntry(path string, edit bool, useMinimalTemplate bool, interactive bool)
 		return fmt.Errorf("changelog entry file names should have a .toml suffix")
 	}
 
+	var issueNumber, prNumber string
+	switch entryType {
+	case "issue":
+		issueNumber = number
+	case "pr":
+		prNumber = number
+	default:
+		return fmt.Errorf("unknown changelog entry type: %s", entryType)
+	}
+
+	data := TemplateData{
+		Type:         "fixed",
+		Message:      "Fix [...].",
+		Issues:       fmt.Sprintf("\"%s\"", issueNumber),
+		PullRequests: fmt.Sprintf("\"%s\"", prNumber),
+	}
+
+	return writeEntry(path, data, edit, useMinimalTemplate, interactive)
+}
+
+// Writes the entry file unless it already exists and starts the editor if requested.
+func writeEntry(path string, data TemplateData, edit bool, useMinimalTemplate bool, interactive bool) error {
+	directory := filepath.Dir(path)
+
 	if !utils.FileExists(directory) {
 		if err := os.MkdirAll(directory, 0755); err != nil {
 			return fmt.Errorf("couldn't create entry directory %s: %w", directory, err)
@@ -96,25 +128,6 @@ func NewEntry(path string, edit bool, useMinimalTemplate bool, interactive bool)
 			return fmt.Errorf("couldn't parse entry template: %w", err)
 		}
 
-		issueNumber := ""
-		prNumber := ""
-
-		switch entryType {
-		case "issue":
-			issueNumber = number
-		case "pr":
-			prNumber = number
-		default:
-			return fmt.Errorf("unknown changelog entry type: %s", entryType)
-		}
-
-		data := TemplateData{
-			Type:         "fixed",
-			Message:      "Fix [...].",
-			Issues:       fmt.Sprintf("\"%s\"", issueNumber),
-			PullRequests: fmt.Sprintf("\"%s\"", prNumber),
-		}
-
 		if interactive {
 			if !isatty.IsTerminal(os.Stdout.Fd()) {
 				return errors.New("unable to use interactive mode, output is not a terminal")
@@ -159,6 +172,106 @@ func NewEntry(path string, edit bool, useMinimalTemplate bool, interactive bool)
 	return nil
 }
 
+// NewEntryFromPullRequest creates the "pr-<num>.toml" entry for the given pull request in the given directory. The
+// message is the pull request title, the type is derived from the labels, and the issues are the linked issues of
+// the pull request. The author is added as contributor unless they are a member of the repository organization.
+func NewEntryFromPullRequest(client *gh.Client, owner string, repo string, number int, directory string, edit bool, useMinimalTemplate bool) error {
+	pr, err := client.GetPullRequest(owner, repo, number)
+	if err != nil {
+		return err
+	}
+
+	contributors := make([]string, 0)
+	if pr.Author != "" && !strings.HasSuffix(pr.Author, "[bot]") {
+		member := lo.Contains([]string{"MEMBER", "OWNER"}, pr.AuthorAssociation)
+		if !member {
+			if member, err = client.IsOrgMember(owner, pr.Author); err != nil {
+				return err
+			}
+		}
+		if !member {
+			contributors = append(contributors, "@"+pr.Author)
+		}
+	}
+
+	data := TemplateData{
+		Type:         typeFromLabels(pr.Labels),
+		Message:      messageFromTitle(pr.Title),
+		Issues:       quoteList(pullRequestIssues(*pr)),
+		PullRequests: quoteList([]string{strconv.Itoa(pr.Number)}),
+		Contributors: strings.Join(lo.Map(contributors, func(contributor string, _ int) string {
+			return strconv.Quote(contributor)
+		}), ", "),
+	}
+
+	return writeEntry(filepath.Join(directory, fmt.Sprintf("pr-%d.toml", pr.Number)), data, edit, useMinimalTemplate, false)
+}
+
+// The snippet types in priority order for pull requests with several type labels.
+var labelTypePriority = []string{TypeSecurity, TypeFixed, TypeRemoved, TypeDeprecated, TypeChanged, TypeAdded}
+
+// Returns the snippet type for the given pull request labels. Labels can be type names (e.g., "added") or common
+// labels. (see labelTypes) The default type is "fixed".
+func typeFromLabels(labels []string) string {
+	types := lo.FlatMap(labels, func(label string, _ int) []string {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if _, ok := availableTypesMap[label]; ok {
+			return []string{label}
+		}
+		if labelTypeList, ok := labelTypes[label]; ok {
+			return labelTypeList[:1]
+		}
+		return nil
+	})
+	for _, _type := range labelTypePriority {
+		if lo.Contains(types, _type) {
+			return _type
+		}
+	}
+	return TypeFixed
+}
+
+// Returns the entry message for a pull request title. The message starts with an uppercase letter and ends with a
+// period. (see the lint style rules) It's escaped for a TOML basic string.
+func messageFromTitle(title string) string {
+	message := []rune(strings.TrimSpace(title))
+	if len(message) == 0 {
+		return "Fix [...]."
+	}
+	message[0] = unicode.ToUpper(message[0])
+	if message[len(message)-1] != '.' {
+		message = append(message, '.')
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(string(message))
+}
+
+// Returns the linked issues of the pull request. Issues in the pull request repository are returned as numbers.
+func pullRequestIssues(pr gh.PullRequest) []string {
+	repository := pr.Owner + "/" + pr.Repo
+	return lo.Map(pr.LinkedIssues(), func(reference string, _ int) string {
+		if number, ok := strings.CutPrefix(reference, "#"); ok {
+			return number
+		}
+		if prefix, number, ok := strings.Cut(reference, "#"); ok && strings.EqualFold(prefix, repository) {
+			return number
+		}
+		if prefix, number, ok := strings.Cut(reference, "/issues/"); ok && strings.EqualFold(prefix, "https://github.com/"+repository) {
+			return number
+		}
+		return reference
+	})
+}
+
+// Returns the quoted and comma separated values. An empty list returns an empty string value. (same as the template)
+func quoteList(values []string) string {
+	if len(values) == 0 {
+		return `""`
+	}
+	return strings.Join(lo.Map(values, func(value string, _ int) string {
+		return strconv.Quote(value)
+	}), ", ")
+}
+
 func askForContent(data *TemplateData) error {
 	types := []string{"added", "changed", "deprecated", "removed", "fixed", "security"}
 	defaultType := 4 // Fixed should be the default choice
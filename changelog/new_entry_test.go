@@ -0,0 +1,73 @@
+package changelog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntryFromPullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/pulls/456", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 456, "title": "add \"foo\" setting", "user": {"login": "jane"},
+			"author_association": "CONTRIBUTOR", "labels": [{"name": "enhancement"}, {"name": "docs"}],
+			"body": "Fixes #123\nCloses Graylog2/graylog-plugin-enterprise#7\nResolves https://github.com/Graylog2/graylog2-server/issues/124"}`))
+	})
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/pulls/457", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 457, "title": "Fix the bar setting.", "user": {"login": "john"},
+			"author_association": "CONTRIBUTOR", "labels": [{"name": "enhancement"}, {"name": "bug"}], "body": ""}`))
+	})
+	mux.HandleFunc("GET /orgs/Graylog2/members/jane", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /orgs/Graylog2/members/john", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := gh.NewGitHubClientWithBaseURL("token", server.URL)
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "changelog", "unreleased")
+
+	require.NoError(t, NewEntryFromPullRequest(client, "Graylog2", "graylog2-server", 456, dir, false, true))
+	content, err := os.ReadFile(filepath.Join(dir, "pr-456.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, `type = "added" # One of: a(dded), c(hanged), d(eprecated), r(emoved), f(ixed), s(ecurity)
+message = "Add \"foo\" setting."
+
+issues = ["123", "Graylog2/graylog-plugin-enterprise#7", "124"]
+pulls = ["456"]
+
+contributors = ["@jane"]
+`, string(content))
+
+	snippet, err := parseSnippet(filepath.Join(dir, "pr-456.toml"), "https://github.com/Graylog2/graylog2-server.git")
+	require.NoError(t, err)
+	assert.Equal(t, `Add "foo" setting.`, snippet.Message)
+
+	require.NoError(t, NewEntryFromPullRequest(client, "Graylog2", "graylog2-server", 457, dir, false, true))
+	content, err = os.ReadFile(filepath.Join(dir, "pr-457.toml"))
+	require.NoError(t, err)
+	assert.Equal(t, `type = "fixed" # One of: a(dded), c(hanged), d(eprecated), r(emoved), f(ixed), s(ecurity)
+message = "Fix the bar setting."
+
+issues = [""]
+pulls = ["457"]
+`, string(content))
+}
+
+func TestTypeFromLabels(t *testing.T) {
+	assert.Equal(t, TypeFixed, typeFromLabels(nil))
+	assert.Equal(t, TypeFixed, typeFromLabels([]string{"docs"}))
+	assert.Equal(t, TypeAdded, typeFromLabels([]string{"Feature"}))
+	assert.Equal(t, TypeChanged, typeFromLabels([]string{"changed", "enhancement"}))
+	assert.Equal(t, TypeSecurity, typeFromLabels([]string{"bug", "security"}))
+}
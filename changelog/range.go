@@ -0,0 +1,190 @@
+package changelog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/hashicorp/go-version"
+	"github.com/samber/lo"
+)
+
+// VersionRange selects the versioned changelog folders for rendering. The From version is exclusive and the To
+// version is inclusive, so the range contains the changes for an upgrade from From to To. Both are optional.
+type VersionRange struct {
+	From string
+	To   string
+}
+
+// ReleaseFolder contains the versioned changelog folders of a single version. (see ReleaseInPath)
+type ReleaseFolder struct {
+	Version string
+	// The "changelog/<version>" folders of all changelog paths that have one.
+	Paths []string
+	// The date of the version tag or an empty string if the version hasn't been tagged.
+	ReleaseDate string
+	// The changelog path of each folder in Paths.
+	changelogPaths map[string]string
+	semver         *version.Version
+}
+
+func (r VersionRange) bounds() (*version.Version, *version.Version, error) {
+	var from, to *version.Version
+	var err error
+	if r.From != "" {
+		if from, err = version.NewSemver(r.From); err != nil {
+			return nil, nil, fmt.Errorf("invalid from version %q: %w", r.From, err)
+		}
+	}
+	if r.To != "" {
+		if to, err = version.NewSemver(r.To); err != nil {
+			return nil, nil, fmt.Errorf("invalid to version %q: %w", r.To, err)
+		}
+	}
+	if from != nil && to != nil && !from.LessThan(to) {
+		return nil, nil, fmt.Errorf("from version %s must be lower than to version %s", r.From, r.To)
+	}
+	return from, to, nil
+}
+
+// FindReleaseFolders returns the versioned changelog folders in the given changelog paths (e.g., "changelog") that
+// are part of the given range, ordered by semver from the newest to the oldest version. Pre-release versions are
+// included.
+func FindReleaseFolders(changelogPaths []string, versionRange VersionRange) ([]ReleaseFolder, error) {
+	from, to, err := versionRange.bounds()
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make(map[string]*ReleaseFolder)
+	for _, changelogPath := range changelogPaths {
+		entries, err := os.ReadDir(changelogPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				logger.Debug("Skipping missing changelog path %s", changelogPath)
+				continue
+			}
+			return nil, fmt.Errorf("couldn't read changelog path %s: %w", changelogPath, err)
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || !SemverVersionPatternWithPreRelease.MatchString(entry.Name()) {
+				continue
+			}
+			v, err := version.NewSemver(entry.Name())
+			if err != nil {
+				continue
+			}
+			if (from != nil && !v.GreaterThan(from)) || (to != nil && v.GreaterThan(to)) {
+				continue
+			}
+
+			folder, ok := folders[v.String()]
+			if !ok {
+				folder = &ReleaseFolder{Version: entry.Name(), semver: v, changelogPaths: make(map[string]string)}
+				folders[v.String()] = folder
+			}
+			path := filepath.Join(changelogPath, entry.Name())
+			folder.Paths = append(folder.Paths, path)
+			folder.changelogPaths[path] = changelogPath
+
+			if folder.ReleaseDate == "" {
+				if folder.ReleaseDate, err = git.TagDate(changelogPath, entry.Name()); err != nil {
+					logger.Debug("Couldn't get release date of %s: %s", path, err)
+				}
+			}
+		}
+	}
+
+	result := make([]ReleaseFolder, 0, len(folders))
+	for _, folder := range folders {
+		result = append(result, *folder)
+	}
+	slices.SortFunc(result, func(a, b ReleaseFolder) int {
+		return b.semver.Compare(a.semver)
+	})
+
+	return result, nil
+}
+
+// RenderRange renders every version in the given range as its own section, starting with the newest version.
+// Versions without a tag use the release date of the config. The JSON format renders an array with one document per
+// version and the YAML format a stream of documents. (see MultiDocumentRenderer)
+func RenderRange(config Config, changelogPaths []string, versionRange VersionRange, writer io.Writer) error {
+	if err := checkDetailsMode(config); err != nil {
+		return err
+	}
+	renderer, err := GetRenderer(config.RenderFormat)
+	if err != nil {
+		return err
+	}
+	multiRenderer, multiDocument := renderer.(MultiDocumentRenderer)
+
+	folders, err := FindReleaseFolders(changelogPaths, versionRange)
+	if err != nil {
+		return err
+	}
+	if len(folders) == 0 {
+		return fmt.Errorf("couldn't find changelog versions in range (%s, %s] in: %s",
+			versionRange.From, versionRange.To, strings.Join(changelogPaths, ", "))
+	}
+
+	documents := make([]Document, 0, len(folders))
+	for _, folder := range folders {
+		versionConfig := config
+		versionConfig.SnippetsPaths = folder.Paths
+		versionConfig.ReleaseVersion = folder.Version
+		if folder.ReleaseDate != "" {
+			versionConfig.ReleaseDate = folder.ReleaseDate
+		}
+		if config.Modules != nil {
+			versionConfig.Modules = make(map[string]string)
+			for _, path := range folder.Paths {
+				versionConfig.Modules[path] = config.Modules[folder.changelogPaths[path]]
+			}
+		}
+		if config.Groups != nil {
+			versionConfig.Groups = make([]Group, 0, len(config.Groups))
+			for _, group := range config.Groups {
+				versionConfig.Groups = append(versionConfig.Groups, Group{
+					Title: group.Title,
+					Paths: lo.Filter(folder.Paths, func(path string, _ int) bool {
+						return lo.Contains(group.Paths, folder.changelogPaths[path])
+					}),
+				})
+			}
+		}
+
+		if multiDocument {
+			document, err := renderDocument(versionConfig)
+			if err != nil {
+				return fmt.Errorf("couldn't render version %s: %w", folder.Version, err)
+			}
+			documents = append(documents, document)
+			continue
+		}
+
+		if err := Render(versionConfig, writer); err != nil {
+			return fmt.Errorf("couldn't render version %s: %w", folder.Version, err)
+		}
+	}
+
+	if multiDocument {
+		buf := bytes.Buffer{}
+		if err := multiRenderer.RenderDocuments(config, documents, &buf); err != nil {
+			return fmt.Errorf("couldn't render documents: %w", err)
+		}
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("couldn't write documents: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeTestReleaseFolders(t *testing.T) string {
+	repo := t.TempDir()
+	changelogPath := filepath.Join(repo, "changelog")
+
+	for _, folder := range []string{"5.2.0", "6.0.0-rc.1", "6.0.0", "6.1.0", "unreleased"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(changelogPath, folder), 0755))
+		snippet := "type = \"a\"\nmessage = \"Add " + folder + ".\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(changelogPath, folder, "pr-1.toml"), []byte(snippet), 0644))
+	}
+
+	gitCommit := func(message string) {
+		require.NoError(t, git.ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--allow-empty", "-m", message))
+	}
+
+	require.NoError(t, git.Exec("init", "--initial-branch=main", repo))
+	t.Setenv("GIT_COMMITTER_DATE", "2024-01-10T10:00:00Z")
+	gitCommit("5.2.0")
+	require.NoError(t, git.ExecInPath(repo, "tag", "5.2.0"))
+	t.Setenv("GIT_COMMITTER_DATE", "2024-03-20T10:00:00Z")
+	gitCommit("6.0.0")
+	require.NoError(t, git.ExecInPath(repo, "tag", "6.0.0"))
+	t.Setenv("GIT_COMMITTER_DATE", "2024-05-02T10:00:00Z")
+	gitCommit("6.1.0")
+	require.NoError(t, git.ExecInPath(repo, "tag", "6.1.0"))
+
+	return changelogPath
+}
+
+func TestFindReleaseFolders(t *testing.T) {
+	changelogPath := writeTestReleaseFolders(t)
+
+	versions := func(folders []ReleaseFolder) []string {
+		var result []string
+		for _, folder := range folders {
+			result = append(result, folder.Version)
+		}
+		return result
+	}
+
+	folders, err := FindReleaseFolders([]string{changelogPath}, VersionRange{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"6.1.0", "6.0.0", "6.0.0-rc.1", "5.2.0"}, versions(folders))
+	assert.Equal(t, "2024-05-02", folders[0].ReleaseDate)
+	assert.Equal(t, []string{filepath.Join(changelogPath, "6.1.0")}, folders[0].Paths)
+	assert.Empty(t, folders[2].ReleaseDate, "untagged versions don't have a release date")
+
+	folders, err = FindReleaseFolders([]string{changelogPath}, VersionRange{From: "5.2.0", To: "6.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"6.0.0", "6.0.0-rc.1"}, versions(folders))
+
+	folders, err = FindReleaseFolders([]string{changelogPath}, VersionRange{From: "6.0.0-rc.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"6.1.0", "6.0.0"}, versions(folders))
+
+	folders, err = FindReleaseFolders([]string{changelogPath, filepath.Join(changelogPath, "missing")}, VersionRange{To: "5.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5.2.0"}, versions(folders))
+
+	_, err = FindReleaseFolders([]string{changelogPath}, VersionRange{From: "6.1.0", To: "6.0.0"})
+	assert.Error(t, err)
+
+	_, err = FindReleaseFolders([]string{changelogPath}, VersionRange{From: "foo"})
+	assert.Error(t, err)
+}
+
+func TestRenderRange(t *testing.T) {
+	changelogPath := writeTestReleaseFolders(t)
+
+	var buf bytes.Buffer
+	config := Config{
+		RenderFormat:            FormatMD,
+		Product:                 "Graylog",
+		ReleaseDate:             "2024-06-01",
+		MarkdownHeaderBaseLevel: 1,
+		GitHubRepoURL:           "https://github.com/Graylog2/graylog2-server",
+	}
+	require.NoError(t, RenderRange(config, []string{changelogPath}, VersionRange{From: "5.2.0", To: "6.0.0"}, &buf))
+
+	assert.Equal(t, `# Graylog 6.0.0
+
+Released: 2024-03-20
+
+## Added
+
+- Add 6.0.0.
+
+# Graylog 6.0.0-rc.1
+
+Released: 2024-06-01
+
+## Added
+
+- Add 6.0.0-rc.1.
+
+`, buf.String())
+
+	config.RenderFormat = FormatMD
+	assert.Error(t, RenderRange(config, []string{changelogPath}, VersionRange{From: "6.1.0"}, &buf))
+}
+
+func TestRenderRangeStructured(t *testing.T) {
+	changelogPath := writeTestReleaseFolders(t)
+	versionRange := VersionRange{From: "5.2.0", To: "6.0.0"}
+
+	var buf bytes.Buffer
+	config := Config{
+		RenderFormat:  FormatJSON,
+		Product:       "Graylog",
+		ReleaseDate:   "2024-06-01",
+		GitHubRepoURL: "https://github.com/Graylog2/graylog2-server",
+	}
+	versions := func(documents []Document) []string {
+		var result []string
+		for _, document := range documents {
+			require.Len(t, document.Entries, 1)
+			result = append(result, document.Version+" "+document.ReleaseDate+" "+document.Entries[0].Message)
+		}
+		return result
+	}
+	expected := []string{"6.0.0 2024-03-20 Add 6.0.0.", "6.0.0-rc.1 2024-06-01 Add 6.0.0-rc.1."}
+
+	// JSON renders an array of documents
+	require.NoError(t, RenderRange(config, []string{changelogPath}, versionRange, &buf))
+	var jsonDocuments []Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &jsonDocuments))
+	assert.Equal(t, expected, versions(jsonDocuments))
+
+	// YAML renders a stream with one document per version
+	buf.Reset()
+	config.RenderFormat = FormatYAML
+	require.NoError(t, RenderRange(config, []string{changelogPath}, versionRange, &buf))
+	var yamlDocuments []Document
+	decoder := yaml.NewDecoder(&buf)
+	for {
+		var document Document
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		yamlDocuments = append(yamlDocuments, document)
+	}
+	assert.Equal(t, expected, versions(yamlDocuments))
+
+	// Templates are rendered once per version
+	buf.Reset()
+	config.RenderFormat = FormatTemplate
+	config.TemplateFile = filepath.Join(t.TempDir(), "release.tmpl")
+	require.NoError(t, os.WriteFile(config.TemplateFile, []byte("{{.Document.Version}}: {{.Config.ReleaseVersion}}\n"), 0644))
+	require.NoError(t, RenderRange(config, []string{changelogPath}, versionRange, &buf))
+	assert.Equal(t, "6.0.0: 6.0.0\n6.0.0-rc.1: 6.0.0-rc.1\n", buf.String())
+}
@@ -13,6 +13,11 @@ import (
 
 const gitkeepContent = "# Keep the directory in Git"
 
+// ReleaseCommitMessage returns the commit message for the changelog release of the given version.
+func ReleaseCommitMessage(version string) string {
+	return fmt.Sprintf("Release changelog for version %s", version)
+}
+
 func Release(project p.Project, versionPattern *regexp.Regexp) error {
 	return p.ForEachSelectedModuleE(project, func(module p.Module) error {
 		err := ReleaseInPath(module.Path, module.Revision, versionPattern)
@@ -63,8 +68,7 @@ func ReleaseInPath(path string, version string, versionPattern *regexp.Regexp) e
 			return fmt.Errorf("couldn't add unreleased changelog path to Git: %w", err)
 		}
 
-		commitMsg := fmt.Sprintf("Release changelog for version %s", version)
-		if _, err := git.GitE("commit", "-m", commitMsg); err != nil {
+		if _, err := git.GitE("commit", "-m", ReleaseCommitMessage(version)); err != nil {
 			return fmt.Errorf("couldn't commit changelog release: %w", err)
 		}
 
@@ -8,11 +8,11 @@ import (
 	"github.com/samber/lo"
 	"io"
 	"os"
+	"strings"
 )
 
 func Render(config Config, writer io.Writer) error {
-	parsedSnippets, err := parseSnippets(config)
-	if err != nil {
+	if err := checkDetailsMode(config); err != nil {
 		return err
 	}
 
@@ -21,6 +21,26 @@ func Render(config Config, writer io.Writer) error {
 		return err
 	}
 
+	if structuredRenderer, ok := renderer.(StructuredRenderer); ok {
+		document, err := renderDocument(config)
+		if err != nil {
+			return err
+		}
+		buf := bytes.Buffer{}
+		if err := structuredRenderer.RenderDocument(config, document, &buf); err != nil {
+			return fmt.Errorf("couldn't render document: %w", err)
+		}
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("couldn't write document: %w", err)
+		}
+		return nil
+	}
+
+	parsedSnippets, err := parseSnippets(config)
+	if err != nil {
+		return err
+	}
+
 	if !config.SkipHeader {
 		headBuf := bytes.Buffer{}
 		if err := renderer.RenderHeader(config, &headBuf); err != nil {
@@ -46,6 +66,36 @@ func Render(config Config, writer io.Writer) error {
 		return nil
 	}
 
+	if len(config.Groups) == 0 {
+		return renderSections(config, renderer, parsedSnippets, writer)
+	}
+
+	groupConfig := config
+	groupConfig.sectionLevel++
+	for _, group := range config.Groups {
+		groupSnippets := groupSnippets(group, parsedSnippets)
+		if len(groupSnippets) == 0 {
+			continue
+		}
+
+		buf := bytes.Buffer{}
+		if err := renderer.RenderGroup(config, group.Title, &buf); err != nil {
+			return fmt.Errorf("couldn't render group \"%s\": %w", group.Title, err)
+		}
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("couldn't write group: %w", err)
+		}
+
+		if err := renderSections(groupConfig, renderer, groupSnippets, writer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Renders the type sections and the aggregated details sections of the given snippets.
+func renderSections(config Config, renderer Renderer, parsedSnippets map[string][]Snippet, writer io.Writer) error {
 	for _, _type := range sortedTypes {
 		if len(parsedSnippets[_type]) > 0 {
 			buf := bytes.Buffer{}
@@ -65,11 +115,60 @@ func Render(config Config, writer io.Writer) error {
 		}
 	}
 
+	if detailsMode(config) == DetailsAggregated {
+		for _, kind := range detailsKinds {
+			// Keep the type order of the sections above
+			entries := make([]DetailsEntry, 0)
+			for _, _type := range sortedTypes {
+				for _, snippet := range parsedSnippets[_type] {
+					if details := strings.TrimSpace(kind.value(snippet)); details != "" {
+						entries = append(entries, DetailsEntry{Snippet: snippet, Details: details})
+					}
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+
+			buf := bytes.Buffer{}
+
+			if err := renderer.RenderType(config, kind.title, &buf); err != nil {
+				return fmt.Errorf("couldn't render details section \"%s\": %w", kind.title, err)
+			}
+
+			if err := renderer.RenderDetails(config, entries, &buf); err != nil {
+				return fmt.Errorf("couldn't render details: %w", err)
+			}
+
+			buf.WriteString("\n")
+			if _, err := writer.Write(buf.Bytes()); err != nil {
+				return fmt.Errorf("couldn't write details: %w", err)
+			}
+		}
+	}
+
 	return nil
 }
 
+func checkDetailsMode(config Config) error {
+	if !lo.Contains(AvailableDetailsModes, detailsMode(config)) {
+		return fmt.Errorf("invalid details mode: %s (available: %s)", config.Details, strings.Join(AvailableDetailsModes, ", "))
+	}
+	return nil
+}
+
+// Parses the snippets of the config and resolves them into a changelog document.
+func renderDocument(config Config) (Document, error) {
+	parsedSnippets, err := parseSnippets(config)
+	if err != nil {
+		return Document{}, err
+	}
+	return NewDocument(config, parsedSnippets)
+}
+
 func parseSnippets(config Config) (map[string][]Snippet, error) {
 	parsedSnippets := make(map[string][]Snippet)
+	deduplicator := make(snippetDeduplicator)
 	paths := config.SnippetsPaths
 	stdin := config.ReadStdin
 
@@ -102,6 +201,13 @@ func parseSnippets(config Config) (map[string][]Snippet, error) {
 				return parsedSnippets, err
 			}
 
+			// Only the grouped project changelog merges the snippets of several repositories
+			if len(config.Groups) > 0 && deduplicator.isDuplicate(*snippetData) {
+				continue
+			}
+
+			snippetData.Module = config.Modules[path]
+			snippetData.snippetsPath = path
 			parsedSnippets[snippetData.Type] = append(parsedSnippets[snippetData.Type], *snippetData)
 		}
 	}
@@ -0,0 +1,133 @@
+package changelog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestSnippets(t *testing.T, snippets map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range snippets {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func renderTestChangelog(t *testing.T, config Config) string {
+	var buf bytes.Buffer
+	config.SkipHeader = true
+	config.GitHubRepoURL = "https://github.com/Graylog2/graylog2-server"
+	require.NoError(t, Render(config, &buf))
+	return buf.String()
+}
+
+func TestRenderDetails(t *testing.T) {
+	dir := writeTestSnippets(t, map[string]string{
+		"pr-1.toml": `type = "c"
+message = "Change the default."
+details.user = """
+The setting now defaults to ` + "`bar`" + `.
+
+- Check your config
+"""
+details.ops = "Restart all nodes."
+`,
+		"pr-2.toml": `type = "a"
+message = "Add a thing."
+`,
+	})
+
+	assert.Equal(t, `## Added
+
+- Add a thing.
+
+## Changed
+
+- Change the default.
+
+## Upgrade Notes
+
+- Change the default.
+
+  The setting now defaults to `+"`bar`"+`.
+
+  - Check your config
+
+## Operations Notes
+
+- Change the default.
+
+  Restart all nodes.
+
+`, renderTestChangelog(t, Config{RenderFormat: FormatMD, SnippetsPaths: []string{dir}, MarkdownHeaderBaseLevel: 1}))
+
+	assert.Equal(t, `## Added
+
+- Add a thing.
+
+## Changed
+
+- Change the default.
+
+  **Upgrade notes:**
+
+  The setting now defaults to `+"`bar`"+`.
+
+  - Check your config
+
+  **Operations notes:**
+
+  Restart all nodes.
+
+`, renderTestChangelog(t, Config{RenderFormat: FormatMD, SnippetsPaths: []string{dir}, MarkdownHeaderBaseLevel: 1, Details: DetailsInline}))
+
+	assert.Equal(t, `## Added
+
+- Add a thing.
+
+## Changed
+
+- Change the default.
+
+`, renderTestChangelog(t, Config{RenderFormat: FormatMD, SnippetsPaths: []string{dir}, MarkdownHeaderBaseLevel: 1, Details: DetailsOmit}))
+
+	assert.Equal(t, `<h2>Added</h2>
+<ul>
+  <li>Add a thing.</li>
+</ul>
+
+<h2>Changed</h2>
+<ul>
+  <li>Change the default.</li>
+</ul>
+
+<h2>Upgrade Notes</h2>
+<ul>
+  <li>Change the default.
+<p>The setting now defaults to <code>bar</code>.</p>
+<ul>
+<li>Check your config</li>
+</ul>
+</li>
+</ul>
+
+<h2>Operations Notes</h2>
+<ul>
+  <li>Change the default.
+<p>Restart all nodes.</p>
+</li>
+</ul>
+
+`, renderTestChangelog(t, Config{RenderFormat: FormatHTML, SnippetsPaths: []string{dir}}))
+
+	assert.Contains(t, renderTestChangelog(t, Config{RenderFormat: FormatD360HTML, SnippetsPaths: []string{dir}, Details: DetailsInline}),
+		"  <li>Change the default.\n<p><strong>Upgrade notes:</strong></p>\n<p>The setting now defaults to <code>bar</code>.</p>\n")
+
+	err := Render(Config{RenderFormat: FormatMD, SnippetsPaths: []string{dir}, Details: "all"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "invalid details mode: all")
+}
@@ -66,6 +66,10 @@ type Snippet struct {
 	Details       SnippetDetails `toml:"details"`
 	GitHubRepoURL string
 	Filename      string
+	// The module of the snippets path. (see Config.Modules)
+	Module string
+	// The snippets path that contains the snippet file. (see Config.SnippetsPaths)
+	snippetsPath string
 }
 
 func listSnippets(path string) ([]string, error) {
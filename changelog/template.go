@@ -0,0 +1,131 @@
+package changelog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/yuin/goldmark"
+)
+
+const FormatTemplate = "template"
+
+// RenderTemplateData is the data model for user-defined changelog templates. (see the template format)
+type RenderTemplateData struct {
+	Config Config
+	// The resolved changelog document with all entries. (see the JSON and YAML formats)
+	Document Document
+	// The types that have entries, in sort order.
+	Types []RenderTemplateType
+}
+
+type RenderTemplateType struct {
+	// The snippet type. (e.g., "added")
+	Name string
+	// The section title. (e.g., "Added")
+	Title   string
+	Entries []DocumentEntry
+}
+
+// TemplateFormatter renders the changelog with the Go template in Config.TemplateFile. Templates with an ".html" or
+// ".htm" extension (also before a final ".tmpl" extension) are parsed with html/template, all others with
+// text/template.
+type TemplateFormatter struct {
+	structuredFormatter
+}
+
+func (t TemplateFormatter) RenderDocument(config Config, document Document, buf *bytes.Buffer) error {
+	if config.TemplateFile == "" {
+		return errors.New("missing template file for template format")
+	}
+
+	content, err := os.ReadFile(config.TemplateFile)
+	if err != nil {
+		return fmt.Errorf("couldn't read template: %w", err)
+	}
+
+	data := newTemplateData(config, document)
+	name := filepath.Base(config.TemplateFile)
+
+	if isHTMLTemplate(config.TemplateFile) {
+		tmpl, err := htmltemplate.New(name).Funcs(templateFuncs(true)).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("couldn't parse template %s: %w", config.TemplateFile, err)
+		}
+		if err := tmpl.Execute(buf, data); err != nil {
+			return fmt.Errorf("couldn't execute template %s: %w", config.TemplateFile, err)
+		}
+		return nil
+	}
+
+	tmpl, err := texttemplate.New(name).Funcs(templateFuncs(false)).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("couldn't parse template %s: %w", config.TemplateFile, err)
+	}
+	if err := tmpl.Execute(buf, data); err != nil {
+		return fmt.Errorf("couldn't execute template %s: %w", config.TemplateFile, err)
+	}
+	return nil
+}
+
+func newTemplateData(config Config, document Document) RenderTemplateData {
+	data := RenderTemplateData{Config: config, Document: document, Types: make([]RenderTemplateType, 0)}
+	for _, _type := range sortedTypes {
+		var entries []DocumentEntry
+		for _, entry := range document.Entries {
+			if entry.Type == _type {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) > 0 {
+			data.Types = append(data.Types, RenderTemplateType{Name: _type, Title: titleCaser.String(_type), Entries: entries})
+		}
+	}
+	return data
+}
+
+func isHTMLTemplate(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == ".tmpl" {
+		ext = strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, filepath.Ext(filename))))
+	}
+	return ext == ".html" || ext == ".htm"
+}
+
+// Returns the template helper functions. The Markdown conversion results are not escaped in HTML templates.
+func templateFuncs(html bool) map[string]any {
+	convert := func(renderer goldmark.Markdown) func(string) (any, error) {
+		return func(markdown string) (any, error) {
+			var buf bytes.Buffer
+			if err := renderer.Convert([]byte(markdown), &buf); err != nil {
+				return nil, fmt.Errorf("couldn't convert Markdown to HTML: %w", err)
+			}
+			if html {
+				return htmltemplate.HTML(buf.String()), nil
+			}
+			return buf.String(), nil
+		}
+	}
+
+	return map[string]any{
+		// Converts multi-line Markdown (e.g., details) to HTML
+		"markdown": convert(mdDetailsRenderer),
+		// Converts a single Markdown line (e.g., messages) to HTML without a paragraph
+		"markdownInline": convert(mdMessageRenderer),
+		"title":          titleCaser.String,
+		"join":           func(sep string, values []string) string { return strings.Join(values, sep) },
+		"repeat":         func(count int, value string) string { return strings.Repeat(value, count) },
+		"indent": func(spaces int, value string) string {
+			return strings.ReplaceAll(value, "\n", "\n"+strings.Repeat(" ", spaces))
+		},
+		"trim":  strings.TrimSpace,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"add":   func(a, b int) int { return a + b },
+	}
+}
@@ -0,0 +1,67 @@
+package changelog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	dir := writeTestSnippets(t, map[string]string{
+		"pr-1.toml": `type = "f"
+message = "Fix <the> *thing*."
+pulls = ["34"]
+contributors = ["@jane"]
+details.user = "Check the **thing**."
+`,
+		"pr-2.toml": `type = "a"
+message = "Add a thing."
+`,
+	})
+	templates := t.TempDir()
+
+	render := func(name string, content string) string {
+		filename := filepath.Join(templates, name)
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+
+		var buf bytes.Buffer
+		require.NoError(t, Render(Config{
+			RenderFormat:   FormatTemplate,
+			TemplateFile:   filename,
+			SnippetsPaths:  []string{dir},
+			ReleaseVersion: "6.2.0",
+			Product:        "Graylog",
+			GitHubRepoURL:  "https://github.com/Graylog2/graylog2-server",
+		}, &buf))
+		return buf.String()
+	}
+
+	assert.Equal(t, `*Graylog 6.2.0*
+Added:
+• Add a thing.
+Fixed:
+• Fix <the> *thing*. (Graylog2/graylog2-server#34) Thanks: @jane
+  Check the **thing**.
+`, render("slack.tmpl", `*{{ .Config.Product }} {{ .Config.ReleaseVersion }}*
+{{ range .Types }}{{ .Title }}:
+{{ range .Entries }}• {{ .Message }}{{ range .PullRequests }} ({{ .Reference }}){{ end }}{{ with .Contributors }} Thanks: {{ join ", " . }}{{ end }}
+{{ with .Details.User }}  {{ indent 2 . }}
+{{ end }}{{ end }}{{ end }}`))
+
+	// HTML templates escape the values but not the converted Markdown (raw HTML in Markdown is omitted)
+	assert.Equal(t, `<h2>Fixed</h2>
+<p>Fix &lt;the&gt; *thing*.</p>
+<li>Fix <!-- raw HTML omitted --> <em>thing</em>.</li>
+<p>Check the <strong>thing</strong>.</p>
+`, render("notes.html.tmpl", `{{ range .Types }}{{ if eq .Name "fixed" }}<h2>{{ .Title }}</h2>
+{{ range .Entries }}<p>{{ .Message }}</p>
+<li>{{ markdownInline .Message }}</li>
+{{ markdown .Details.User }}{{ end }}{{ end }}{{ end }}`))
+
+	err := Render(Config{RenderFormat: FormatTemplate, SnippetsPaths: []string{dir}, GitHubRepoURL: "https://github.com/Graylog2/graylog2-server"}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "missing template file")
+}
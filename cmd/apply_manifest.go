@@ -1,14 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Graylog2/graylog-project-cli/apply"
-	"github.com/Graylog2/graylog-project-cli/git"
+	c "github.com/Graylog2/graylog-project-cli/config"
+	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/manifest"
 	"github.com/Graylog2/graylog-project-cli/pomparse"
 	"github.com/Graylog2/graylog-project-cli/project"
 	"github.com/Graylog2/graylog-project-cli/projectstate"
+	"github.com/Graylog2/graylog-project-cli/repo"
 	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/fatih/color"
 	"github.com/hashicorp/go-version"
@@ -35,6 +38,63 @@ Example:
 
   # Actually execute all commands!
   $ graylog-project apply-manifest --execute manifests/release-2.2.0.json
+
+Before any repository gets modified, the "preflight" step runs the checks
+of "apply-manifest check". Failed checks abort the release unless --force
+is used. Single checks can be skipped with --skip-check. Modules that
+haven't been cloned yet are checked by the "setup-repositories" step right
+after the checkout.
+
+The release is executed as a list of named steps. (see --list-steps) The
+progress of every step and module is recorded in a state file next to the
+manifest. (e.g., manifests/release-2.2.0.apply-state.json) If a release
+fails, it can be continued with the first incomplete step after the
+problem is fixed. Before resuming, the command verifies that all
+repositories are still on the recorded branch and commit.
+
+Release commits, tags and branches are created locally with git. Nothing
+gets pushed until the "push" step, which pushes the branches and tags of
+every module atomically to the configured remote. (see --remote) Tags
+are annotated with the --tag-message template unless --lightweight-tags
+is used. The "deploy" step runs after a successful push, so no artifacts
+get published for a release that couldn't be pushed. It builds the checked
+out release tags and restores the branches afterward.
+
+A dry-run can write a release plan for review. The plan lists the version
+changes, modified files, commits, tags, branches, changelog moves and Maven
+invocations of every module. When the plan is passed to an --execute run,
+the release is computed again and aborted if it doesn't match the plan.
+Paths are recorded relative to the project directory and the Maven
+invocations without the Maven binary and CI specific options, so the plan
+can be verified in another checkout.
+
+  # Write the release plan in JSON and Markdown format
+  $ graylog-project apply-manifest --write-plan plan.json --write-plan plan.md manifests/release-2.2.0.json
+
+  # Execute the release after verifying it against the reviewed plan
+  $ graylog-project apply-manifest --execute --plan plan.json manifests/release-2.2.0.json
+
+With --github-release, the "github-releases" step creates a GitHub release
+for every release tag after everything has been pushed. The release notes
+are rendered from the versioned changelog folder of each module.
+
+Every action of an --execute run (Maven commands, git commands, pom.xml and
+package.json changes, changelog rotations, pushes and GitHub releases) is
+appended to a JSON lines audit log next to the manifest with its working
+directory, timestamps, exit status and truncated output.
+(e.g., manifests/release-2.2.0.audit.jsonl)
+
+Use "apply-manifest sandbox" to rehearse the complete release against local
+bare remotes without pushing anything.
+
+  # Continue a failed release
+  $ graylog-project apply-manifest --execute --resume manifests/release-2.2.0.json
+
+  # Re-run the given step and all following steps
+  $ graylog-project apply-manifest --execute --from-step deploy manifests/release-2.2.0.json
+
+  # Only run the given step
+  $ graylog-project apply-manifest --execute --only-step create-branches manifests/release-2.2.0.json
 `,
 	Run: applyManifestCommand,
 }
@@ -43,6 +103,15 @@ var applyManifestExecute bool
 var applyManifestForce bool
 var applyManifestSkipMavenDeploy bool
 var applyManifestSkipTests bool
+var applyManifestResume bool
+var applyManifestFromStep string
+var applyManifestOnlyStep string
+var applyManifestListSteps bool
+var applyManifestSkipChecks []string
+var applyManifestWritePlan []string
+var applyManifestPlan string
+var applyManifestGitHubRelease bool
+var applyManifestGitHubReleaseDraft string
 
 func init() {
 	RootCmd.AddCommand(applyManifestCmd)
@@ -51,6 +120,29 @@ func init() {
 	applyManifestCmd.Flags().BoolVarP(&applyManifestForce, "force", "f", false, "Ignore some sanity checks")
 	applyManifestCmd.Flags().BoolVarP(&applyManifestSkipMavenDeploy, "skip-maven-deploy", "", false, "Skip maven deployment")
 	applyManifestCmd.Flags().BoolVarP(&applyManifestSkipTests, "skip-tests", "", false, "Skip running tests via maven")
+	applyManifestCmd.Flags().BoolVar(&applyManifestResume, "resume", false, "Resume a previous run with the first incomplete step")
+	applyManifestCmd.Flags().StringVar(&applyManifestFromStep, "from-step", "", "Start with the given step and skip all previous steps")
+	applyManifestCmd.Flags().StringVar(&applyManifestOnlyStep, "only-step", "", "Only run the given step")
+	applyManifestCmd.Flags().BoolVar(&applyManifestListSteps, "list-steps", false, "List the release steps and exit")
+	applyManifestCmd.Flags().StringSliceVar(&applyManifestSkipChecks, "skip-check", []string{}, "Pre-flight checks to skip (comma separated, see \"apply-manifest check --help\")")
+	applyManifestCmd.PersistentFlags().String("remote", apply.DefaultRemote, "Git remote for the release branches and tags")
+	applyManifestCmd.Flags().String("tag-message", apply.DefaultTagMessage, "Go template for the annotated tag message (fields: Module, Version, Tag)")
+	applyManifestCmd.Flags().Bool("lightweight-tags", false, "Create lightweight tags instead of annotated tags")
+	applyManifestCmd.Flags().StringSliceVar(&applyManifestWritePlan, "write-plan", []string{}, "Write the release plan of a dry-run to the given file (JSON, or Markdown for *.md files)")
+	applyManifestCmd.Flags().StringVar(&applyManifestPlan, "plan", "", "Verify that the release matches the given JSON release plan before executing it")
+	applyManifestCmd.Flags().BoolVar(&applyManifestGitHubRelease, "github-release", false, "Create GitHub releases for the release tags (needs "+githubAccessTokenEnv+")")
+	applyManifestCmd.Flags().String("deploy-repository", "", "Deploy the Maven artifacts to the given repository (id::url) instead of the distribution management repository")
+	applyManifestCmd.Flags().StringVar(&applyManifestGitHubReleaseDraft, "github-release-draft", string(apply.DraftNone), "Create GitHub releases as draft (\"none\", \"prerelease\" or \"all\")")
+	applyManifestCmd.MarkFlagsMutuallyExclusive("from-step", "only-step")
+	applyManifestCmd.MarkFlagsMutuallyExclusive("plan", "resume")
+	applyManifestCmd.MarkFlagsMutuallyExclusive("plan", "from-step")
+	applyManifestCmd.MarkFlagsMutuallyExclusive("plan", "only-step")
+	applyManifestCmd.MarkFlagsMutuallyExclusive("plan", "write-plan")
+
+	viper.BindPFlag("apply-manifest.remote", applyManifestCmd.PersistentFlags().Lookup("remote"))
+	viper.BindPFlag("apply-manifest.tag-message", applyManifestCmd.Flags().Lookup("tag-message"))
+	viper.BindPFlag("apply-manifest.lightweight-tags", applyManifestCmd.Flags().Lookup("lightweight-tags"))
+	viper.BindPFlag("apply-manifest.deploy-repository", applyManifestCmd.Flags().Lookup("deploy-repository"))
 
 	viper.BindPFlag("apply-manifest.execute", applyManifestCmd.Flags().Lookup("execute"))
 	viper.BindPFlag("apply-manifest.force", applyManifestCmd.Flags().Lookup("force"))
@@ -70,11 +162,40 @@ func applyManifestCommand(cmd *cobra.Command, args []string) {
 	mavenProfiles := []string{"release"}
 	config, repoManager, proj := prepareCheckoutCommand(cmd, args)
 	var applier apply.Applier
+	var plan *apply.Plan
+	var auditLog *apply.AuditLog
+
+	if applyManifestExecute && len(applyManifestWritePlan) > 0 {
+		logger.Fatal("The --write-plan flag can only be used for dry-runs")
+	}
+	if !applyManifestExecute && applyManifestPlan != "" {
+		logger.Fatal("The --plan flag can only be used together with --execute")
+	}
+	if !lo.Contains(apply.DraftModes, apply.DraftMode(applyManifestGitHubReleaseDraft)) {
+		logger.Fatal("Invalid --github-release-draft value %q (valid: %v)", applyManifestGitHubReleaseDraft, apply.DraftModes)
+	}
+
+	gitConfig := apply.GitConfig{
+		Remote:          viper.GetString("apply-manifest.remote"),
+		TagMessage:      viper.GetString("apply-manifest.tag-message"),
+		LightweightTags: viper.GetBool("apply-manifest.lightweight-tags"),
+	}
 
 	if applyManifestExecute {
-		applier = apply.NewExecuteApplier(mavenProfiles)
+		var githubClient *gh.Client
+		if applyManifestGitHubRelease {
+			token := viper.GetString("github.access-token")
+			if token == "" {
+				logger.Fatal("Missing GitHub access token for --github-release (env: %s)", githubAccessTokenEnv)
+			}
+			githubClient = gh.NewGitHubClient(token)
+		}
+		auditLog = openAuditLog(config.Checkout.ManifestFiles, "apply-manifest")
+		defer auditLog.Close()
+		applier = apply.NewExecuteApplier(mavenProfiles, apply.WithGitConfig(gitConfig), apply.WithGitHubClient(githubClient), apply.WithAuditLog(auditLog))
 	} else {
-		applier = apply.NewNoopApplier(mavenProfiles)
+		plan = apply.NewPlan(proj, config.Checkout.ManifestFiles)
+		applier = apply.NewNoopApplier(mavenProfiles, apply.WithGitConfig(gitConfig), apply.WithPlan(plan))
 	}
 
 	msg := func(message string) {
@@ -89,6 +210,27 @@ func applyManifestCommand(cmd *cobra.Command, args []string) {
 		return !item.SkipRelease
 	})
 
+	ctx := applyManifestContext{
+		config:      config,
+		repoManager: repoManager,
+		project:     proj,
+		applier:     applier,
+		msg:         msg,
+	}
+	runner := apply.StepRunner{Steps: applyManifestSteps(ctx)}
+	if plan != nil {
+		runner.BeforeStep = func(step apply.Step) {
+			plan.SetStep(step.Name)
+		}
+	}
+
+	if applyManifestListSteps {
+		for _, step := range runner.Steps {
+			fmt.Printf("%-32s %s\n", step.Name, step.Description)
+		}
+		return
+	}
+
 	msg("Sanity check for apply manifest")
 	applyManifestErrors := 0
 	apply.ForEachModule(proj, false, func(module project.Module) {
@@ -108,216 +250,552 @@ func applyManifestCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	repoManager.SetupProjectRepositoriesWithApply(proj, true)
-
-	projectstate.Sync(proj, config)
-	manifest.WriteState(config.Checkout.ManifestFiles)
+	if applyManifestPlan != "" {
+		verifyApplyManifestPlan(ctx, mavenProfiles, gitConfig)
+	}
 
-	// Check that there are no modifications in the repositories via "git status --porcelain ."
-	msg("Checking every module for uncommitted changes")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			output := git.GitValue("status", "--porcelain")
+	runner.State = applyManifestState(config.Checkout.ManifestFiles)
 
-			if output != "" {
-				logger.Error("Module %s has uncommitted changes:", module.Name)
-				for line := range strings.SplitSeq(output, "\n") {
-					logger.Error("%s", line)
-				}
-				if !applyManifestForce {
-					os.Exit(1)
-				}
-			}
-		})
+	err := runner.Run(apply.RunOptions{
+		Resume:   applyManifestResume,
+		FromStep: applyManifestFromStep,
+		OnlyStep: applyManifestOnlyStep,
 	})
+	printAuditSummary(auditLog)
+	if err != nil {
+		logger.Error("ERROR: %s", err)
+		if applyManifestExecute {
+			logger.Error("Fix the problem and continue the release with: apply-manifest --execute --resume %s", strings.Join(args, " "))
+		}
+		// Deferred calls don't run with os.Exit
+		auditLog.Close()
+		os.Exit(1)
+	}
 
-	// Set release version in all web modules
-	msg("Setting release version in all web modules")
-	apply.ForEachModule(proj, true, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.NpmVersionSet(module, module.Revision)
-		})
-	})
+	for _, filename := range applyManifestWritePlan {
+		if err := plan.WriteFile(filename); err != nil {
+			logger.Fatal("ERROR: %s", err)
+		}
+		logger.Info("Wrote release plan to %s", filename)
+	}
 
-	// Set release version in all modules
-	msg("Setting release version in all modules")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.MavenVersionsSet(module.Revision)
-		})
+	logger.Info("DONE! - took: %s", time.Since(t))
+}
+
+// Computes the release plan with a dry-run and aborts if it doesn't match the reviewed plan.
+func verifyApplyManifestPlan(ctx applyManifestContext, mavenProfiles []string, gitConfig apply.GitConfig) {
+	reviewed, err := apply.ReadPlan(applyManifestPlan)
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	ctx.msg(fmt.Sprintf("Computing release plan to verify it against %s", applyManifestPlan))
+
+	computed := apply.NewPlan(ctx.project, ctx.config.Checkout.ManifestFiles)
+	ctx.applier = apply.NewNoopApplier(mavenProfiles, apply.WithGitConfig(gitConfig), apply.WithPlan(computed))
+	ctx.planOnly = true
 
-		// Update all versions after each change!
-		applyManifestUpdateVersions(msg, proj, applier)
+	// The setup steps don't record any plan actions and the actual run executes them right after the verification.
+	steps := lo.Reject(applyManifestSteps(ctx), func(step apply.Step, _ int) bool {
+		return lo.Contains(applyManifestSetupSteps, step.Name)
 	})
 
-	// Regenerate the graylog-project pom and assembly files to get the latest versions
-	msg("Regenerate pom and assembly templates")
-	projectstate.Sync(proj, config)
+	runner := apply.StepRunner{
+		Steps: steps,
+		BeforeStep: func(step apply.Step) {
+			computed.SetStep(step.Name)
+		},
+	}
+	if err := runner.Run(apply.RunOptions{}); err != nil {
+		logger.Fatal("Couldn't compute release plan: %s", err)
+	}
 
-	// Run tests via package to also test the jar creation
-	msg("Running tests and build artifacts")
-	logger.ColorInfo(color.FgMagenta, "[%s]", utils.GetCwd())
-	if applyManifestSkipTests {
-		msg("Skipping tests!")
-		applier.MavenRun("-DskipTests", "clean", "package")
-	} else {
-		applier.MavenRun("clean", "package")
+	if diffs := reviewed.Diff(computed); len(diffs) > 0 {
+		for _, diff := range diffs {
+			logger.Error("Release plan mismatch: %s", diff)
+		}
+		logger.Fatal("The computed release plan doesn't match the reviewed plan in %s - aborting", applyManifestPlan)
 	}
 
-	msg("Rotate changelogs for release")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			v, err := version.NewSemver(module.Revision)
-			if err != nil {
-				logger.Fatal("Couldn't create new semver for %s: %s", module.Revision, err)
+	ctx.msg(fmt.Sprintf("Release plan matches %s", applyManifestPlan))
+}
+
+// Opens the audit log next to the last of the given manifest files.
+func openAuditLog(manifestFiles []string, command string) *apply.AuditLog {
+	auditLog, err := apply.OpenAuditLog(apply.AuditLogFilename(manifestFiles[len(manifestFiles)-1]), command)
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+	logger.Info("Recording executed actions in audit log %s", auditLog.Filename())
+	return auditLog
+}
+
+// Prints the audit summary, closes the audit log and exits with the given error. Commands that record an audit log
+// use this instead of logger.Fatal so the summary is also printed for failed runs.
+func exitWithAuditSummary(auditLog *apply.AuditLog, err error) {
+	printAuditSummary(auditLog)
+	auditLog.Close()
+	logger.Fatal("ERROR: %s", err)
+}
+
+func printAuditSummary(auditLog *apply.AuditLog) {
+	if auditLog == nil {
+		return
+	}
+	summaryColor := color.FgGreen
+	summary := auditLog.Summary()
+	if summary.Failed > 0 {
+		summaryColor = color.FgRed
+	}
+	logger.ColorInfo(color.FgYellow, "===> Audit log summary")
+	logger.ColorInfo(summaryColor, "  %s", summary)
+	logger.Info("  Audit log: %s", auditLog.Filename())
+}
+
+// Returns the apply state for the given manifest files. The state is only persisted when the manifest gets
+// executed. For dry-runs, an existing state is used to show which steps would be skipped.
+func applyManifestState(manifestFiles []string) *apply.State {
+	stateFile := apply.StateFilename(manifestFiles[len(manifestFiles)-1])
+	continueRun := applyManifestResume || applyManifestFromStep != "" || applyManifestOnlyStep != ""
+
+	var state *apply.State
+	if utils.FileExists(stateFile) {
+		if !continueRun && applyManifestExecute && !applyManifestForce {
+			logger.Fatal("Found state of a previous run in %s. Use --resume, --from-step or --only-step to continue, or remove the file to start from scratch.", stateFile)
+		}
+		if continueRun {
+			var err error
+			if state, err = apply.ReadState(stateFile); err != nil {
+				logger.Fatal("ERROR: %s", err)
 			}
-			// We don't want different changelog folders for each pre-release but one folder for each GA release.
-			if v.Prerelease() == "" {
-				if err := applier.ChangelogRelease(module.Path, module.Revision); err != nil {
-					logger.Fatal("ERROR: %s", err)
+			if applyManifestResume {
+				if errs := state.Verify(); len(errs) > 0 {
+					for _, err := range errs {
+						logger.Error("Repository state doesn't match the recorded state: %s", err)
+					}
+					if !applyManifestForce {
+						os.Exit(1)
+					}
 				}
-			} else {
-				logger.Info("Skipping changelog release for pre-release version: %s", v)
 			}
-		})
-	})
-
-	// Committing new version in web modules
-	// Run this before the maven scm checkin is pushing to GitHub
-	msg("Committing new version in web modules")
-	apply.ForEachModule(proj, true, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.NpmVersionCommit(module, module.Revision)
-		})
-	})
+		}
+	} else if applyManifestResume {
+		logger.Fatal("Couldn't find state file %s to resume from", stateFile)
+	}
 
-	// Commit and push new versions and create and push tag
-	msg("Committing and pushing new versions and tags")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.MavenScmCheckinRelease(module.Name, module.Revision)
-			applier.MavenScmTag(module.Revision)
-		})
-	})
+	if state == nil {
+		state = apply.NewState(stateFile, manifestFiles)
+	}
 
-	// Run deploy & build artifacts
-	msg("Running deploy and build artifacts")
-	logger.ColorInfo(color.FgMagenta, "[%s]", utils.GetCwd())
-	if applyManifestSkipMavenDeploy {
-		msg("Skipping maven deployment!")
-		applier.MavenRunWithProfiles([]string{"release"}, "-DskipTests", "clean", "package")
+	if applyManifestExecute {
+		logger.Info("Recording release progress in %s", stateFile)
+		if err := state.Save(); err != nil {
+			logger.Fatal("ERROR: %s", err)
+		}
 	} else {
-		applier.MavenRunWithProfiles(
-			[]string{"release"},
-			"-DskipTests",
-			"-Dlocal.repo.path="+filepath.Join(utils.GetCwd(), "target", "local-maven-repo"),
-			"clean",
-			"deploy",
-		)
+		state.Detach()
 	}
 
-	// Set development version in all web modules
-	msg("Setting development version in all web modules")
-	apply.ForEachModule(proj, true, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.NpmVersionSet(module, module.ApplyNewVersion())
-		})
-	})
+	return state
+}
 
-	// Set development version
-	msg("Set development versions")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.MavenVersionsSet(module.ApplyNewVersion())
-		})
+type applyManifestContext struct {
+	config      c.Config
+	repoManager *repo.RepoManager
+	project     project.Project
+	applier     apply.Applier
+	msg         func(string)
+	// Set when the steps only compute the release plan. The project state isn't synced in that case.
+	planOnly bool
+}
 
-		// Update all versions after each change!
-		applyManifestUpdateVersions(msg, proj, applier)
-	})
+// Regenerates the graylog-project files for the current module versions.
+func (ctx applyManifestContext) syncProjectState() {
+	if ctx.planOnly {
+		return
+	}
+	projectstate.Sync(ctx.project, ctx.config)
+}
 
-	// Committing new development version in web modules
-	// Run this before the maven scm checkin is pushing to GitHub
-	msg("Committing new development version in web modules")
-	apply.ForEachModule(proj, true, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.NpmVersionCommit(module, module.ApplyNewVersion())
-		})
-	})
+// The steps that prepare the repositories. They are skipped when computing the plan for --plan.
+var applyManifestSetupSteps = []string{"preflight", "setup-repositories"}
+
+// Returns the ordered release steps.
+func applyManifestSteps(ctx applyManifestContext) []apply.Step {
+	proj := ctx.project
+	applier := ctx.applier
+	msg := ctx.msg
+
+	return []apply.Step{
+		{
+			Name:        "preflight",
+			Description: "Run pre-flight checks",
+			Run: func(run *apply.StepRun) error {
+				return applyManifestPreflight(proj, applyManifestSkipChecks)
+			},
+		},
+		{
+			Name:        "setup-repositories",
+			Description: "Checkout the apply revisions of all modules",
+			Run: func(run *apply.StepRun) error {
+				cloned := lo.Filter(proj.Modules, func(module project.Module, _ int) bool {
+					return !utils.FileExists(module.Path)
+				})
+
+				ctx.repoManager.SetupProjectRepositoriesWithApply(proj, true)
+
+				projectstate.Sync(proj, ctx.config)
+				manifest.WriteState(ctx.config.Checkout.ManifestFiles)
+
+				// The preflight step skipped the modules that didn't exist yet
+				if len(cloned) > 0 {
+					clonedProject := proj
+					clonedProject.Modules = cloned
+					if err := applyManifestPreflight(clonedProject, append([]string{apply.CheckJDK}, applyManifestSkipChecks...)); err != nil {
+						return err
+					}
+				}
 
-	// Commit development version
-	msg("Commit development versions")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			applier.MavenScmCheckinDevelopment(module.Name)
-		})
-	})
+				return run.RecordOrigins(proj)
+			},
+		},
+		{
+			Name:        "set-release-web-versions",
+			Description: "Set release version in all web modules",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, true, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.NpmVersionSet(module, module.Revision)
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "set-release-versions",
+			Description: "Set release version in all modules",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.MavenSetVersion(module, module.Revision)
+					})
+					if stepErr != nil {
+						return stepErr
+					}
+
+					// Update all versions after each change!
+					return applyManifestUpdateVersions(msg, proj, applier)
+				})
+			},
+		},
+		{
+			Name:        "regenerate-templates",
+			Description: "Regenerate pom and assembly templates",
+			Run: func(run *apply.StepRun) error {
+				// Regenerate the graylog-project pom and assembly files to get the latest versions
+				ctx.syncProjectState()
+				return nil
+			},
+		},
+		{
+			Name:        "build",
+			Description: "Run tests and build artifacts",
+			Run: func(run *apply.StepRun) error {
+				// Run tests via package to also test the jar creation
+				logger.ColorInfo(color.FgMagenta, "[%s]", utils.GetCwd())
+				if applyManifestSkipTests {
+					msg("Skipping tests!")
+					return applier.MavenRun("-DskipTests", "clean", "package")
+				}
+				return applier.MavenRun("clean", "package")
+			},
+		},
+		{
+			Name:        "rotate-changelogs",
+			Description: "Rotate changelogs for release",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						v, err := version.NewSemver(module.Revision)
+						if err != nil {
+							stepErr = fmt.Errorf("couldn't create new semver for %s: %w", module.Revision, err)
+							return
+						}
+						// We don't want different changelog folders for each pre-release but one folder for each GA release.
+						if v.Prerelease() == "" {
+							stepErr = applier.ChangelogRelease(module.Path, module.Revision)
+						} else {
+							logger.Info("Skipping changelog release for pre-release version: %s", v)
+						}
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "commit-release-web-versions",
+			Description: "Commit release version in web modules",
+			Run: func(run *apply.StepRun) error {
+				// Run this before the maven scm checkin is pushing to GitHub
+				return run.ForEachModule(proj, true, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.NpmVersionCommit(module, module.Revision)
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "commit-and-tag-release",
+			Description: "Commit release versions and create tags",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					if err := applier.GitCommitRelease(module, module.Revision); err != nil {
+						return err
+					}
+					return applier.GitTag(module, module.Revision)
+				})
+			},
+		},
+		{
+			Name:        "set-development-web-versions",
+			Description: "Set development version in all web modules",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, true, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.NpmVersionSet(module, module.ApplyNewVersion())
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "set-development-versions",
+			Description: "Set development version in all modules",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.MavenSetVersion(module, module.ApplyNewVersion())
+					})
+					if stepErr != nil {
+						return stepErr
+					}
+
+					// Update all versions after each change!
+					return applyManifestUpdateVersions(msg, proj, applier)
+				})
+			},
+		},
+		{
+			Name:        "commit-development-web-versions",
+			Description: "Commit development version in web modules",
+			Run: func(run *apply.StepRun) error {
+				// Run this before the maven scm checkin is pushing to GitHub
+				return run.ForEachModule(proj, true, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						stepErr = applier.NpmVersionCommit(module, module.ApplyNewVersion())
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "commit-development-versions",
+			Description: "Commit development versions",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					return applier.GitCommitDevelopment(module)
+				})
+			},
+		},
+		{
+			Name:        "create-branches",
+			Description: "Create new branches",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					if module.ApplyNewBranch() == "" {
+						return nil
+					}
+					return applier.GitBranch(module, module.ApplyNewBranch())
+				})
+			},
+		},
+		{
+			Name:        "rotate-source-branch-changelogs",
+			Description: "Rotate changelogs in source branch when creating new branch",
+			Run: func(run *apply.StepRun) error {
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					var stepErr error
+					applyManifestInDirectory(module.Path, func() {
+						// If we create a new branch during the release process, we want to move the "unreleased" changelogs in
+						// the source branch to a versioned folder.
+						//
+						// Example: Release 5.1.0-rc.1 and create a "5.1" branch from the "main" branch in that process.
+						// 1. Create "5.1" branch, keeping the "changelog/unreleased" folder for the branch
+						// 2. In the "main" branch move "changelog/unreleased" to "changelog/5.1.0-rc.1"
+						// Result:
+						// branch "main": changelog/5.1.0-rc.1 (and a new empty changelog/unreleased folder)
+						// branch "5.1":  changelog/unreleased
+						//
+						// The drawback here is that the "main" branch only contains the 5.1 changelogs up until the 5.1.0-rc.1
+						// release. All newer 5.1 changelogs will only be in the "5.1" branch. There doesn't seem to be a better
+						// way without merging changelogs between the branches, so we live with that drawback for now since
+						// it doesn't affect changelog generation. For the 5.1.0 GA release the final changelog is generated
+						// from the "5.1" branch which has all the changelogs. (changelogs in the "unreleased" folder of the "main"
+						// branch moved on to the next feature release already, e.g., 5.2)
+						if module.ApplyNewBranch() != "" {
+							// We might have done the renaming in the first ChangelogRelease call above when the version is not
+							// a pre-release AND we are creating a new branch. In that case this call is a no-op because the
+							// changelog rotation code checks if the version changelog folder already exists.
+							stepErr = applier.ChangelogRelease(module.Path, module.Revision)
+						} else {
+							logger.Info("Skipping changelog rotation for module: %s (no branch creation requested)", module.Path)
+						}
+					})
+					return stepErr
+				})
+			},
+		},
+		{
+			Name:        "push",
+			Description: "Push branches and tags",
+			Run: func(run *apply.StepRun) error {
+				// Nothing gets pushed before all modules have been committed and tagged successfully. The push is
+				// atomic per module so a module is either completely pushed or not at all.
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					return applier.GitPush(module, apply.ReleaseRefs(module))
+				})
+			},
+		},
+		{
+			Name:        "deploy",
+			Description: "Deploy the artifacts of the release tags",
+			Run: func(run *apply.StepRun) error {
+				// The repositories contain the development versions at this point. The release tags are checked out
+				// for the build and the branches are restored afterward, even if the build fails.
+				if err := applyManifestCheckout(proj, applier, func(module project.Module) string {
+					return "refs/tags/" + module.Revision
+				}); err != nil {
+					return err
+				}
+				ctx.syncProjectState()
+
+				deployErr := applyManifestDeploy(applier, msg)
+
+				restoreErr := applyManifestCheckout(proj, applier, func(module project.Module) string {
+					return module.ApplyFromRevision()
+				})
+				ctx.syncProjectState()
+
+				return errors.Join(deployErr, restoreErr)
+			},
+		},
+		{
+			Name:        "github-releases",
+			Description: "Create GitHub releases for the release tags (with --github-release)",
+			Run: func(run *apply.StepRun) error {
+				if !applyManifestGitHubRelease {
+					logger.Info("Skipping GitHub releases (use --github-release to enable)")
+					return nil
+				}
+				return run.ForEachModule(proj, false, func(module project.Module) error {
+					release, err := apply.NewGitHubRelease(module, apply.GitHubReleaseOptions{
+						Draft: apply.DraftMode(applyManifestGitHubReleaseDraft),
+					})
+					if err != nil {
+						return err
+					}
+					return applier.GitHubRelease(module, release)
+				})
+			},
+		},
+	}
+}
 
-	// Create new branches
-	msg("Create new branches")
+// Checks out the ref that is returned by the given function in all modules.
+func applyManifestCheckout(proj project.Project, applier apply.Applier, ref func(module project.Module) string) error {
+	var err error
 	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			if module.ApplyNewBranch() != "" {
-				applier.MavenScmBranch(module.ApplyNewBranch())
-			}
-		})
+		if err == nil {
+			err = applier.GitCheckout(module, ref(module))
+		}
 	})
+	return err
+}
 
-	msg("Rotate changelogs in source branch when creating new branch")
-	apply.ForEachModule(proj, false, func(module project.Module) {
-		applyManifestInDirectory(module.Path, func() {
-			// If we create a new branch during the release process, we want to move the "unreleased" changelogs in
-			// the source branch to a versioned folder.
-			//
-			// Example: Release 5.1.0-rc.1 and create a "5.1" branch from the "main" branch in that process.
-			// 1. Create "5.1" branch, keeping the "changelog/unreleased" folder for the branch
-			// 2. In the "main" branch move "changelog/unreleased" to "changelog/5.1.0-rc.1"
-			// Result:
-			// branch "main": changelog/5.1.0-rc.1 (and a new empty changelog/unreleased folder)
-			// branch "5.1":  changelog/unreleased
-			//
-			// The drawback here is that the "main" branch only contains the 5.1 changelogs up until the 5.1.0-rc.1
-			// release. All newer 5.1 changelogs will only be in the "5.1" branch. There doesn't seem to be a better
-			// way without merging changelogs between the branches, so we live with that drawback for now since
-			// it doesn't affect changelog generation. For the 5.1.0 GA release the final changelog is generated
-			// from the "5.1" branch which has all the changelogs. (changelogs in the "unreleased" folder of the "main"
-			// branch moved on to the next feature release already, e.g., 5.2)
-			if module.ApplyNewBranch() != "" {
-				// We might have done the renaming in the first ChangelogRelease call above when the version is not
-				// a pre-release AND we are creating a new branch. In that case this call is a no-op because the
-				// changelog rotation code checks if the version changelog folder already exists.
-				if err := applier.ChangelogRelease(module.Path, module.Revision); err != nil {
-					logger.Fatal("ERROR: %s", err)
-				}
+// Builds and deploys the Maven artifacts of the current checkout.
+func applyManifestDeploy(applier apply.Applier, msg func(string)) error {
+	logger.ColorInfo(color.FgMagenta, "[%s]", utils.GetCwd())
+	if applyManifestSkipMavenDeploy {
+		msg("Skipping maven deployment!")
+		return applier.MavenRunWithProfiles([]string{"release"}, "-DskipTests", "clean", "package")
+	}
 
-				if output, err := git.GitE("push", "origin", module.ApplyFromRevision()); err != nil {
-					logger.Fatal("ERROR: %s\n%s", err, output)
-				} else {
-					logger.Info("%s", output)
-				}
-			} else {
-				logger.Info("Skipping changelog rotation for module: %s (no branch creation requested)", module.Path)
-			}
-		})
-	})
+	args := []string{
+		"-DskipTests",
+		"-Dlocal.repo.path=" + filepath.Join(utils.GetCwd(), "target", "local-maven-repo"),
+	}
+	if repository := viper.GetString("apply-manifest.deploy-repository"); repository != "" {
+		args = append(args, "-DaltDeploymentRepository="+repository)
+	}
+	return applier.MavenRunWithProfiles([]string{"release"}, append(args, "clean", "deploy")...)
+}
 
-	logger.Info("DONE! - took: %s", time.Since(t))
+// Runs the pre-flight checks for the given project and returns an error if a check failed. Failed checks are
+// ignored with --force.
+func applyManifestPreflight(proj project.Project, skipChecks []string) error {
+	results, err := apply.RunPreflight(proj, apply.PreflightOptions{
+		Remote: viper.GetString("apply-manifest.remote"),
+		Skip:   skipChecks,
+	})
+	if err != nil {
+		return err
+	}
+	if failed := printPreflightResults(results); failed > 0 && !applyManifestForce {
+		return fmt.Errorf("%d pre-flight checks failed", failed)
+	}
+	return nil
 }
 
-func applyManifestUpdateVersions(msg func(string), proj project.Project, applier apply.Applier) {
+func applyManifestUpdateVersions(msg func(string), proj project.Project, applier apply.Applier) error {
+	var updateErr error
+	inModule := func(module project.Module, callback func() error) {
+		if updateErr != nil {
+			return
+		}
+		applyManifestInDirectory(module.Path, func() {
+			updateErr = callback()
+		})
+	}
+
 	// Set parent versions and graylog.version properties in non-server modules
 	msg("Setting parent and graylog.version properties in non-server modules")
-	serverVersion := proj.Server.Version()
+	serverVersion := applier.ModuleVersion(proj.Server)
 	apply.ForEachModule(proj, true, func(module project.Module) {
 		if module.Server {
 			// Don't change parent and graylog.version property for server
 			return
 		}
-		applyManifestInDirectory(module.Path, func() {
-			applier.MavenSetParent(module, serverVersion)
-			applier.MavenSetProperty(module, "graylog.version", serverVersion)
-			applier.MavenSetProperty(module, "graylog2.version", serverVersion)
+		inModule(module, func() error {
+			if err := applier.MavenSetParent(module, serverVersion); err != nil {
+				return err
+			}
+			if err := applier.MavenSetProperty(module, "graylog.version", serverVersion); err != nil {
+				return err
+			}
+			return applier.MavenSetProperty(module, "graylog2.version", serverVersion)
 		})
 	})
+	if updateErr != nil {
+		return updateErr
+	}
 
 	// Check if any module uses another module as dependency and update the dependency version to the new one.
 	msg("Checking if any module uses another module as dependency and update dependency versions")
@@ -332,15 +810,21 @@ func applyManifestUpdateVersions(msg func(string), proj project.Project, applier
 		}
 
 		match, matchedModule := project.HasModule(proj, dep.GroupId, dep.ArtifactId)
-		if match && dep.Version != matchedModule.Version() {
-			applyManifestInDirectory(module.Path, func() {
-				applier.MavenDependencyVersionSet(module, dep.GroupId, dep.ArtifactId, matchedModule.Version())
+		if match && dep.Version != applier.ModuleVersion(matchedModule) {
+			inModule(module, func() error {
+				return applier.MavenSetDependencyVersion(module, dep.GroupId, dep.ArtifactId, applier.ModuleVersion(matchedModule))
 			})
 		}
 	}
 	apply.ForEachModule(proj, true, func(module project.Module) {
-		pomFile := filepath.Join(module.Path, "pom.xml")
-		pom := pomparse.ParsePom(pomFile)
+		if updateErr != nil {
+			return
+		}
+		pom, err := pomparse.ParsePomE(filepath.Join(module.Path, "pom.xml"))
+		if err != nil {
+			updateErr = err
+			return
+		}
 
 		for _, dep := range pom.Dependencies {
 			checkDep(module, dep)
@@ -349,4 +833,6 @@ func applyManifestUpdateVersions(msg func(string), proj project.Project, applier
 			checkDep(module, dep)
 		}
 	})
+
+	return updateErr
 }
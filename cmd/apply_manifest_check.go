@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var applyManifestCheckCmd = &cobra.Command{
+	Use:   "check [flags] <apply-manifest>",
+	Short: "Run the release pre-flight checks",
+	Long: `Run the pre-flight checks for the given apply manifest.
+
+The same checks run as the "preflight" step of apply-manifest before any
+repository gets modified. The modules must be checked out already.
+
+Checks:
+
+  uncommitted-changes  The repositories don't have uncommitted changes
+  from-revision        The apply.from_revision branches exist on the remote
+  release-tag          The release tags don't exist locally or on the remote
+  new-branch           The apply.new_branch branches don't exist yet
+  versions             The versions are valid and increase from the current pom version
+  push-access          Pushing to the remote is permitted (dry-run push of HEAD to a new branch)
+  changelog-lint       The changelog/unreleased entries lint cleanly
+  package-json         The package.json files of the modules exist and contain a version field
+  jdk                  The JDK matches the "jvm_version" of the manifest
+
+Examples:
+
+  # Run all checks
+  $ graylog-project apply-manifest check manifests/release-2.2.0.json
+
+  # Skip the JDK check and print the results as JSON
+  $ graylog-project apply-manifest check --skip-check jdk --json manifests/release-2.2.0.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  applyManifestCheckCommand,
+}
+
+func init() {
+	applyManifestCheckCmd.Flags().StringSlice("skip-check", []string{}, "Pre-flight checks to skip (comma separated)")
+	applyManifestCheckCmd.Flags().Bool("json", false, "Print the check results as JSON")
+
+	viper.BindPFlag("apply-manifest.check.skip-check", applyManifestCheckCmd.Flags().Lookup("skip-check"))
+	viper.BindPFlag("apply-manifest.check.json", applyManifestCheckCmd.Flags().Lookup("json"))
+
+	applyManifestCmd.AddCommand(applyManifestCheckCmd)
+}
+
+func applyManifestCheckCommand(cmd *cobra.Command, args []string) {
+	logger.SetPrefix("[graylog-project]")
+
+	_, _, proj := prepareCheckoutCommand(cmd, args)
+
+	proj.Modules = lo.Filter(proj.Modules, func(item project.Module, index int) bool {
+		return !item.SkipRelease
+	})
+
+	results, err := apply.RunPreflight(proj, apply.PreflightOptions{
+		Remote: viper.GetString("apply-manifest.remote"),
+		Skip:   viper.GetStringSlice("apply-manifest.check.skip-check"),
+	})
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	if viper.GetBool("apply-manifest.check.json") {
+		buf, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			logger.Fatal("Couldn't serialize check results: %s", err)
+		}
+		fmt.Println(string(buf))
+	} else {
+		printPreflightResults(results)
+	}
+
+	if lo.SomeBy(results, apply.CheckResult.Failed) {
+		os.Exit(1)
+	}
+}
+
+// Prints the given check results as a table and returns the number of failed checks.
+func printPreflightResults(results []apply.CheckResult) int {
+	return printCheckResults(results, "pre-flight")
+}
+
+// Prints the given check results as a table and returns the number of failed checks. The kind is used in the
+// failure message.
+func printCheckResults(results []apply.CheckResult, kind string) int {
+	checkLength := 0
+	moduleLength := 0
+	for _, result := range results {
+		checkLength = max(checkLength, len(result.Check))
+		moduleLength = max(moduleLength, len(result.Module))
+	}
+
+	failed := 0
+	for _, result := range results {
+		statusColor := color.FgGreen
+		switch result.Status {
+		case apply.CheckFailed:
+			failed++
+			statusColor = color.FgRed
+		case apply.CheckWarning:
+			statusColor = color.FgYellow
+		}
+		logger.ColorInfo(statusColor, "  %-*s  %-*s  %-7s  %s", checkLength, result.Check, moduleLength, result.Module, strings.ToUpper(string(result.Status)), result.Message)
+	}
+
+	if failed > 0 {
+		logger.Error("%d of %d %s checks failed", failed, len(results), kind)
+	}
+
+	return failed
+}
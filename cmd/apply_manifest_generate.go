@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Graylog2/graylog-project-cli/apply"
 	"github.com/Graylog2/graylog-project-cli/ask"
+	c "github.com/Graylog2/graylog-project-cli/config"
+	"github.com/Graylog2/graylog-project-cli/git"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/manifest"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/fatih/color"
 	"github.com/hashicorp/go-version"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +43,32 @@ In batch mode, all required values can be passed as command flags.
       [--new-branch 3.3 \]
       manifests/3.3.json
 
+With --bump, the release and next development versions are computed from the
+current "-SNAPSHOT" pom version of each module. The modules must be checked
+out. Current versions with a pre-release (e.g., 6.2.0-rc.1-SNAPSHOT) are
+rejected. Modules whose versions diverge from the server get their own versions in
+the apply-manifest. The base revision defaults to the checked out branch of
+each module. The computed versions are shown for confirmation before the
+apply-manifest is written. (no confirmation in batch mode)
+
+Strategies:
+
+  patch       Release the current version (6.1.3-SNAPSHOT -> 6.1.3, next 6.1.4-SNAPSHOT)
+  minor       Release the next minor version (6.1.3-SNAPSHOT -> 6.2.0, next 6.2.1-SNAPSHOT)
+  major       Release the next major version (6.1.3-SNAPSHOT -> 7.0.0, next 7.0.1-SNAPSHOT)
+  prerelease  Release the next pre-release based on the existing tags (6.2.0-SNAPSHOT -> 6.2.0-rc.3, next 6.2.0-SNAPSHOT)
+
+The existing tags for the prerelease strategy are read from the Git remote.
+(see --remote) Tags that only exist in the local clone are included as well.
+
+  $ graylog-project apply-manifest-generate --bump patch manifests/3.3.json
+
+  # Release a beta pre-release of the server and a patch release of the integrations plugin
+  $ graylog-project apply-manifest-generate \
+      --bump prerelease --prerelease-id beta \
+      --module-bump graylog-plugin-integrations=patch \
+      manifests/master.json
+
 `,
 	Run: applyManifestGenerateCommand,
 }
@@ -46,6 +80,10 @@ var amgReleaseVersion string
 var amgDevVersion string
 var amgNewBranch string
 var amgBaseRev string
+var amgBump string
+var amgPrereleaseID string
+var amgModuleBump []string
+var amgRemote string
 
 func init() {
 	RootCmd.AddCommand(applyManifestGenerateCmd)
@@ -55,6 +93,10 @@ func init() {
 	applyManifestGenerateCmd.Flags().StringVar(&amgDevVersion, "dev-version", "", "Next development version")
 	applyManifestGenerateCmd.Flags().StringVar(&amgNewBranch, "new-branch", "", "Create new branch (optional)")
 	applyManifestGenerateCmd.Flags().StringVar(&amgBaseRev, "base-rev", "", "Base revision (branch/tag) for the release")
+	applyManifestGenerateCmd.Flags().StringVar(&amgBump, "bump", "", "Compute the versions from the pom versions (patch, minor, major, prerelease)")
+	applyManifestGenerateCmd.Flags().StringVar(&amgPrereleaseID, "prerelease-id", apply.DefaultPrereleaseID, "Pre-release identifier for the prerelease strategy")
+	applyManifestGenerateCmd.Flags().StringVar(&amgRemote, "remote", apply.DefaultRemote, "Git remote to read the existing tags from for the prerelease strategy")
+	applyManifestGenerateCmd.Flags().StringSliceVar(&amgModuleBump, "module-bump", []string{}, "Bump strategy for a single module (<module>=<strategy>, repeatable)")
 }
 
 func applyManifestGenerateCommand(cmd *cobra.Command, args []string) {
@@ -68,7 +110,13 @@ func applyManifestGenerateCommand(cmd *cobra.Command, args []string) {
 
 	var releaseVersion string
 
-	if amgBatchMode {
+	if amgBump != "" {
+		var ok bool
+		if releaseVersion, ok = amgCommandBump(&newManifest, args[0:]); !ok {
+			logger.Info("Aborted")
+			return
+		}
+	} else if amgBatchMode {
 		releaseVersion = amgCommandBatch(&newManifest)
 	} else {
 		releaseVersion = amgCommandInteractive(&newManifest)
@@ -177,3 +225,193 @@ func amgCommandInteractive(newManifest *manifest.Manifest) string {
 
 	return newVersion
 }
+
+// A module version computed by a bump strategy.
+type amgModuleVersion struct {
+	Name         string
+	Strategy     apply.BumpStrategy
+	Version      apply.BumpedVersion
+	BaseRevision string
+}
+
+// Computes the versions of all modules from their pom versions and asks for confirmation in non-batch mode.
+// Returns the release version of the server and false if the user didn't confirm the versions.
+func amgCommandBump(newManifest *manifest.Manifest, manifestFiles []string) (string, bool) {
+	if amgReleaseVersion != "" || amgDevVersion != "" {
+		logger.Fatal("The --bump parameter cannot be used with --release-version or --dev-version")
+	}
+
+	strategy, err := apply.ParseBumpStrategy(amgBump)
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+	moduleStrategies, err := amgParseModuleBump(amgModuleBump)
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	var defaultConfig c.Config
+	defaultConfig.Checkout.ManifestFiles = manifestFiles
+	config := c.Merge(defaultConfig)
+	// Modules are matched by index, so the project must contain the skip_release modules as well
+	config.ReleaseMode = false
+
+	proj := p.New(config, manifestFiles)
+
+	var newModules []manifest.ManifestModule
+	var versions []amgModuleVersion
+	var serverVersion amgModuleVersion
+
+	for i, module := range newManifest.Modules {
+		// Don't include non-modules in the release manifest
+		if module.SkipRelease {
+			continue
+		}
+
+		projectModule := proj.Modules[i]
+		moduleVersion, err := amgBumpModule(projectModule, strategy, moduleStrategies)
+		if err != nil {
+			logger.Fatal("ERROR: %s", err)
+		}
+		delete(moduleStrategies, projectModule.Name)
+		delete(moduleStrategies, utils.NameFromRepository(module.Repository))
+
+		if module.Server {
+			serverVersion = moduleVersion
+		}
+
+		module.Revision = moduleVersion.Version.Release
+		module.Apply = manifest.ManifestApply{}
+		versions = append(versions, moduleVersion)
+		newModules = append(newModules, module)
+	}
+
+	if serverVersion.Name == "" {
+		logger.Fatal("No server module in manifests: %v", manifestFiles)
+	}
+	if len(moduleStrategies) > 0 {
+		logger.Fatal("Unknown modules in --module-bump: %v", lo.Keys(moduleStrategies))
+	}
+
+	// The server versions are the defaults, modules with diverging versions get their own apply settings
+	for i, moduleVersion := range versions {
+		if moduleVersion.Version.Next != serverVersion.Version.Next {
+			newModules[i].Apply.NewVersion = moduleVersion.Version.Next
+		}
+		if moduleVersion.BaseRevision != serverVersion.BaseRevision {
+			newModules[i].Apply.FromRevision = moduleVersion.BaseRevision
+		}
+	}
+
+	newManifest.DefaultApply = manifest.ManifestApply{
+		FromRevision: serverVersion.BaseRevision,
+		NewBranch:    amgNewBranch,
+		NewVersion:   serverVersion.Version.Next,
+	}
+	newManifest.Modules = newModules
+
+	amgPrintVersions(versions, serverVersion)
+
+	if !amgBatchMode {
+		asker := ask.NewAsker(os.Stdin)
+		if !asker.AskYesNo(fmt.Sprintf("Write manifests/release-%s.json?", serverVersion.Version.Release), true) {
+			return serverVersion.Version.Release, false
+		}
+	}
+
+	return serverVersion.Version.Release, true
+}
+
+func amgBumpModule(module p.Module, strategy apply.BumpStrategy, moduleStrategies map[string]apply.BumpStrategy) (amgModuleVersion, error) {
+	if s, ok := moduleStrategies[module.Name]; ok {
+		strategy = s
+	} else if s, ok := moduleStrategies[utils.NameFromRepository(module.Repository)]; ok {
+		strategy = s
+	}
+
+	current := module.Version()
+	if current == "" {
+		return amgModuleVersion{}, fmt.Errorf("couldn't read pom version of module %s in %s (not checked out?)", module.Name, module.Path)
+	}
+
+	opts := apply.BumpOptions{PrereleaseID: amgPrereleaseID}
+	if strategy == apply.BumpPrerelease {
+		// The local clone might not have all tags, so the pre-release number is computed from the remote tags.
+		// Local tags are included to also skip tags of a previous run that haven't been pushed yet.
+		remoteTags, err := git.RemoteTags(module.Path, amgRemote, "*")
+		if err != nil {
+			return amgModuleVersion{}, err
+		}
+		localTags, err := git.Tags(module.Path, "*")
+		if err != nil {
+			return amgModuleVersion{}, err
+		}
+		opts.Tags = lo.Uniq(append(remoteTags, localTags...))
+	}
+
+	bumped, err := apply.BumpVersion(current, strategy, opts)
+	if err != nil {
+		return amgModuleVersion{}, fmt.Errorf("couldn't compute versions for module %s: %w", module.Name, err)
+	}
+
+	baseRevision := amgBaseRev
+	if baseRevision == "" {
+		if baseRevision, err = git.CurrentBranch(module.Path); err != nil {
+			return amgModuleVersion{}, err
+		}
+	}
+
+	return amgModuleVersion{
+		Name:         module.Name,
+		Strategy:     strategy,
+		Version:      bumped,
+		BaseRevision: baseRevision,
+	}, nil
+}
+
+// Parses the "<module>=<strategy>" values of the --module-bump flag.
+func amgParseModuleBump(values []string) (map[string]apply.BumpStrategy, error) {
+	strategies := make(map[string]apply.BumpStrategy)
+	for _, value := range values {
+		name, strategyName, found := strings.Cut(value, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid --module-bump value %q: expected <module>=<strategy>", value)
+		}
+		strategy, err := apply.ParseBumpStrategy(strategyName)
+		if err != nil {
+			return nil, err
+		}
+		strategies[name] = strategy
+	}
+	return strategies, nil
+}
+
+// Prints the computed module versions as a table. Modules with versions that diverge from the server are marked.
+func amgPrintVersions(versions []amgModuleVersion, serverVersion amgModuleVersion) {
+	header := amgModuleVersion{
+		Name:         "MODULE",
+		Strategy:     "STRATEGY",
+		Version:      apply.BumpedVersion{Current: "CURRENT", Release: "RELEASE", Next: "NEXT"},
+		BaseRevision: "BASE",
+	}
+	rows := append([]amgModuleVersion{header}, versions...)
+
+	widths := make([]int, 5)
+	for _, row := range rows {
+		for i, value := range []string{row.Name, string(row.Strategy), row.Version.Current, row.Version.Release, row.Version.Next} {
+			widths[i] = max(widths[i], len(value))
+		}
+	}
+
+	logger.ColorInfo(color.FgYellow, "===> Release versions")
+	for i, row := range rows {
+		marker := " "
+		if i > 0 && (row.Version.Next != serverVersion.Version.Next || row.BaseRevision != serverVersion.BaseRevision) {
+			marker = "*"
+		}
+		logger.Info("%s %-*s  %-*s  %-*s  %-*s  %-*s  %s", marker,
+			widths[0], row.Name, widths[1], row.Strategy, widths[2], row.Version.Current,
+			widths[3], row.Version.Release, widths[4], row.Version.Next, row.BaseRevision)
+	}
+	logger.Info("(* = independent module versions)")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/ask"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var applyManifestRollbackCmd = &cobra.Command{
+	Use:   "rollback [flags] <apply-manifest>",
+	Short: "Roll back a failed release",
+	Long: `Roll back the changes of a failed apply-manifest run.
+
+The command works out which release commits, tags and branches have been
+created for the given apply manifest and shows a rollback plan. After
+confirmation, it deletes the release tags and new branches (local and
+remote) and resets the source branches to their pre-release commits.
+
+The pre-release commits are taken from the apply state file next to the
+manifest. Without a state file, the release commits are detected by their
+commit messages.
+
+By default, the source branches are force-pushed to the pre-release commit.
+Use --revert to create revert commits instead.
+
+Examples:
+
+  # Show the rollback plan and ask for confirmation
+  $ graylog-project apply-manifest rollback manifests/release-2.2.0.json
+
+  # Revert the release commits instead of resetting the branches
+  $ graylog-project apply-manifest rollback --revert manifests/release-2.2.0.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  applyManifestRollbackCommand,
+}
+
+func init() {
+	applyManifestRollbackCmd.Flags().Bool("revert", false, "Create revert commits instead of resetting the branches")
+	applyManifestRollbackCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+
+	viper.BindPFlag("apply-manifest.rollback.revert", applyManifestRollbackCmd.Flags().Lookup("revert"))
+	viper.BindPFlag("apply-manifest.rollback.yes", applyManifestRollbackCmd.Flags().Lookup("yes"))
+
+	applyManifestCmd.AddCommand(applyManifestRollbackCmd)
+}
+
+func applyManifestRollbackCommand(cmd *cobra.Command, args []string) {
+	logger.SetPrefix("[graylog-project]")
+
+	config, _, proj := prepareCheckoutCommand(cmd, args)
+
+	proj.Modules = lo.Filter(proj.Modules, func(item project.Module, index int) bool {
+		return !item.SkipRelease
+	})
+
+	manifestFiles := config.Checkout.ManifestFiles
+	stateFile := apply.StateFilename(manifestFiles[len(manifestFiles)-1])
+
+	var state *apply.State
+	if utils.FileExists(stateFile) {
+		var err error
+		if state, err = apply.ReadState(stateFile); err != nil {
+			logger.Fatal("ERROR: %s", err)
+		}
+		logger.Info("Using pre-release commits from %s", stateFile)
+	} else {
+		logger.Info("No apply state found, detecting release commits by commit message")
+	}
+
+	actions, err := apply.PlanRollback(proj, state, apply.RollbackOptions{
+		Remote: viper.GetString("apply-manifest.remote"),
+		Revert: viper.GetBool("apply-manifest.rollback.revert"),
+	})
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	if len(actions) == 0 {
+		logger.Info("Nothing to roll back")
+		return
+	}
+
+	logger.ColorInfo(color.FgYellow, "===> Rollback plan")
+	for _, action := range actions {
+		logger.Info("  [%s] %s", action.Module, action)
+	}
+
+	if !viper.GetBool("apply-manifest.rollback.yes") {
+		asker := ask.NewAsker(os.Stdin)
+		if !asker.AskYesNo("Execute the rollback?", false) {
+			logger.Info("Aborted")
+			return
+		}
+	}
+
+	results := apply.ExecuteRollback(actions)
+
+	logger.ColorInfo(color.FgYellow, "===> Rollback summary")
+	failed := 0
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+			logger.ColorInfo(color.FgRed, "  FAILED [%s] %s: %s", result.Action.Module, result.Action, result.Error)
+		} else {
+			logger.ColorInfo(color.FgGreen, "  OK     [%s] %s", result.Action.Module, result.Action)
+		}
+	}
+
+	if failed > 0 {
+		logger.Error("%d of %d rollback actions failed", failed, len(results))
+		os.Exit(1)
+	}
+
+	// The release starts from scratch after a successful rollback
+	if state != nil {
+		if err := os.Remove(stateFile); err != nil {
+			logger.Fatal("Couldn't remove apply state %s: %s", stateFile, err)
+		}
+		logger.ColorInfo(color.FgGreen, "  OK     removed apply state %s", stateFile)
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/manifest"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var applyManifestSandboxCmd = &cobra.Command{
+	Use:   "sandbox [flags] <apply-manifest>",
+	Short: "Rehearse a release against local bare remotes",
+	Long: `Rehearse a release with "apply-manifest --execute" without pushing anything.
+
+The command creates a sandbox directory with a local bare repository for each
+module of the apply manifest. The bare repositories contain the branches and
+tags of the current workspace repositories. (local branches take precedence
+over the "origin" branches, so unpushed commits are included) The workspace
+repositories are not modified.
+
+The complete release then runs in a clone of the graylog-project repository
+inside the sandbox. The module repositories of the manifest are rewired to
+the bare repositories, so the modules are cloned from there and "origin"
+points to them. Maven artifacts are deployed into a local file repository
+in the sandbox. GitHub releases are never created.
+
+Afterwards, the command reports the tags and branches that have been pushed
+to the bare repositories with their pom.xml and package.json versions.
+
+Sandbox layout:
+
+  remotes/    The bare repositories
+  repos/      The module checkouts of the release (--repository-root)
+  project/    The graylog-project checkout of the release
+  maven-repo/ The Maven deployment repository
+
+Examples:
+
+  # Rehearse the release in a temporary directory
+  $ graylog-project apply-manifest sandbox manifests/release-2.2.0.json
+
+  # Rehearse the release without tests and deployment and print the report as JSON
+  $ graylog-project apply-manifest sandbox --skip-tests --skip-maven-deploy --json manifests/release-2.2.0.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  applyManifestSandboxCommand,
+}
+
+func init() {
+	applyManifestSandboxCmd.Flags().String("dir", "", "Sandbox directory (default: new temporary directory)")
+	applyManifestSandboxCmd.Flags().Bool("skip-tests", false, "Skip running tests via maven")
+	applyManifestSandboxCmd.Flags().Bool("skip-maven-deploy", false, "Skip maven deployment")
+	applyManifestSandboxCmd.Flags().StringSlice("skip-check", []string{}, "Pre-flight checks to skip (comma separated)")
+	applyManifestSandboxCmd.Flags().Bool("json", false, "Print the sandbox report as JSON")
+	applyManifestSandboxCmd.Flags().Bool("cleanup", false, "Remove the sandbox directory after a successful release")
+
+	viper.BindPFlag("apply-manifest.sandbox.dir", applyManifestSandboxCmd.Flags().Lookup("dir"))
+	viper.BindPFlag("apply-manifest.sandbox.skip-tests", applyManifestSandboxCmd.Flags().Lookup("skip-tests"))
+	viper.BindPFlag("apply-manifest.sandbox.skip-maven-deploy", applyManifestSandboxCmd.Flags().Lookup("skip-maven-deploy"))
+	viper.BindPFlag("apply-manifest.sandbox.skip-check", applyManifestSandboxCmd.Flags().Lookup("skip-check"))
+	viper.BindPFlag("apply-manifest.sandbox.json", applyManifestSandboxCmd.Flags().Lookup("json"))
+	viper.BindPFlag("apply-manifest.sandbox.cleanup", applyManifestSandboxCmd.Flags().Lookup("cleanup"))
+
+	applyManifestCmd.AddCommand(applyManifestSandboxCmd)
+}
+
+func applyManifestSandboxCommand(cmd *cobra.Command, args []string) {
+	logger.SetPrefix("[graylog-project]")
+
+	msg := func(message string) {
+		logger.ColorInfo(color.FgYellow, "===> %s", message)
+	}
+
+	config, _, proj := prepareCheckoutCommand(cmd, args)
+
+	proj.Modules = lo.Filter(proj.Modules, func(item project.Module, index int) bool {
+		return !item.SkipRelease
+	})
+
+	dir := viper.GetString("apply-manifest.sandbox.dir")
+	if dir == "" {
+		var err error
+		if dir, err = os.MkdirTemp("", "graylog-project-sandbox-"); err != nil {
+			logger.Fatal("Couldn't create sandbox directory: %s", err)
+		}
+	}
+
+	sandbox, err := apply.NewSandbox(dir)
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	msg(fmt.Sprintf("Creating sandbox remotes in %s", sandbox.RemotesDir()))
+	remotes := make(map[string]string)
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		remote, err := sandbox.AddModule(module)
+		if err != nil {
+			logger.Fatal("ERROR: %s", err)
+		}
+		remotes[utils.NameFromRepository(module.Repository)] = remote.Path
+		logger.Info("  %s -> %s", module.Name, remote.Path)
+	})
+
+	msg(fmt.Sprintf("Cloning graylog-project into %s", sandbox.ProjectDir()))
+	projectPath, err := git.ToplevelPath()
+	if err != nil {
+		logger.Fatal("Couldn't find graylog-project repository: %s", err)
+	}
+	if err := git.Exec("clone", "--quiet", projectPath, sandbox.ProjectDir()); err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	manifestFile := writeSandboxManifest(sandbox, config.Checkout.ManifestFiles, remotes)
+
+	msg(fmt.Sprintf("Running release in %s", sandbox.ProjectDir()))
+	runErr := runSandboxRelease(sandbox, manifestFile)
+
+	report, err := sandbox.Report()
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	if viper.GetBool("apply-manifest.sandbox.json") {
+		buf, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			logger.Fatal("Couldn't serialize sandbox report: %s", err)
+		}
+		fmt.Println(string(buf))
+	} else {
+		printSandboxReport(report)
+	}
+
+	if runErr != nil {
+		logger.Error("Sandbox release failed: %s", runErr)
+		logger.Error("The sandbox is kept for inspection: %s", sandbox.Dir)
+		os.Exit(1)
+	}
+
+	if viper.GetBool("apply-manifest.sandbox.cleanup") {
+		if err := os.RemoveAll(sandbox.Dir); err != nil {
+			logger.Fatal("Couldn't remove sandbox %s: %s", sandbox.Dir, err)
+		}
+		logger.Info("Removed sandbox %s", sandbox.Dir)
+	} else {
+		logger.Info("Sandbox: %s", sandbox.Dir)
+	}
+}
+
+// Writes the apply manifest with the module repositories rewired to the sandbox remotes into the sandbox project.
+// Returns the manifest path relative to the sandbox project.
+func writeSandboxManifest(sandbox *apply.Sandbox, manifestFiles []string, remotes map[string]string) string {
+	sandboxManifest := manifest.ReadManifest(manifestFiles)
+	sandboxManifest.Includes = nil
+	for i, module := range sandboxManifest.Modules {
+		if remote, ok := remotes[utils.NameFromRepository(module.Repository)]; ok {
+			sandboxManifest.Modules[i].Repository = remote
+		}
+	}
+
+	buf, err := manifest.Marshal(sandboxManifest)
+	if err != nil {
+		logger.Fatal("ERROR: %v", err)
+	}
+
+	manifestFile := filepath.Join("manifests", "sandbox-"+filepath.Base(manifestFiles[len(manifestFiles)-1]))
+	path := filepath.Join(sandbox.ProjectDir(), manifestFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		logger.Fatal("Couldn't create manifest directory: %s", err)
+	}
+	if err := os.WriteFile(path, buf, 0644); err != nil {
+		logger.Fatal("Unable to write sandbox manifest file %s: %v", path, err)
+	}
+
+	return manifestFile
+}
+
+// Runs "apply-manifest --execute" for the given manifest in the sandbox project.
+func runSandboxRelease(sandbox *apply.Sandbox, manifestFile string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("couldn't find graylog-project executable: %w", err)
+	}
+
+	args := sandboxReleaseArgs(sandbox, manifestFile)
+
+	logger.ColorInfo(color.FgMagenta, "[command: graylog-project %s]", strings.Join(args, " "))
+
+	command := exec.Command(executable, args...)
+	command.Dir = sandbox.ProjectDir()
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	return command.Run()
+}
+
+// Returns the graylog-project arguments to execute the given manifest in the sandbox project.
+func sandboxReleaseArgs(sandbox *apply.Sandbox, manifestFile string) []string {
+	args := []string{
+		"--repository-root", sandbox.RepositoriesDir(),
+		"--disable-update-check",
+		"apply-manifest",
+		"--execute",
+		"--remote", apply.DefaultRemote,
+		"--deploy-repository", sandbox.DeployRepository(),
+	}
+	if viper.GetBool("apply-manifest.sandbox.skip-tests") {
+		args = append(args, "--skip-tests")
+	}
+	if viper.GetBool("apply-manifest.sandbox.skip-maven-deploy") {
+		args = append(args, "--skip-maven-deploy")
+	}
+	if skipChecks := viper.GetStringSlice("apply-manifest.sandbox.skip-check"); len(skipChecks) > 0 {
+		args = append(args, "--skip-check", strings.Join(skipChecks, ","))
+	}
+	return append(args, manifestFile)
+}
+
+func printSandboxReport(report apply.SandboxReport) {
+	logger.ColorInfo(color.FgYellow, "===> Sandbox release report")
+
+	for _, module := range report.Modules {
+		logger.Info("  %s (%s)", module.Module, module.Remote)
+
+		refs := append(
+			lo.Map(module.Tags, func(ref apply.SandboxRef, _ int) lo.Tuple2[string, apply.SandboxRef] {
+				return lo.T2("tag", ref)
+			}),
+			lo.Map(module.Branches, func(ref apply.SandboxRef, _ int) lo.Tuple2[string, apply.SandboxRef] {
+				return lo.T2("branch", ref)
+			})...,
+		)
+		if len(refs) == 0 {
+			logger.ColorInfo(color.FgRed, "    no tags or branches pushed")
+			continue
+		}
+
+		nameLength := 0
+		for _, ref := range refs {
+			nameLength = max(nameLength, len(ref.B.Name))
+		}
+
+		for _, ref := range refs {
+			status := "updated"
+			if ref.B.New {
+				status = "new"
+			}
+			versions := []string{"pom.xml=" + ref.B.PomVersion}
+			for _, file := range slices.Sorted(maps.Keys(ref.B.PackageJsonVersions)) {
+				versions = append(versions, file+"="+ref.B.PackageJsonVersions[file])
+			}
+			logger.ColorInfo(color.FgGreen, "    %-6s  %-*s  %-7s  %.10s  %s", ref.A, nameLength, ref.B.Name, status, ref.B.Commit, strings.Join(versions, " "))
+		}
+	}
+}
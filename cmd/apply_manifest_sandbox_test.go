@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	c "github.com/Graylog2/graylog-project-cli/config"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/repo"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sandboxTestPom = `<project>
+    <modelVersion>4.0.0</modelVersion>
+    <groupId>org.graylog</groupId>
+    <artifactId>graylog-server</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+</project>
+`
+
+const sandboxTestPackageJson = `{
+  "name": "graylog-web-interface",
+  "version": "6.2.0-SNAPSHOT",
+  "private": true
+}
+`
+
+const sandboxTestManifest = `{
+  "jvm_version": 17,
+  "modules": [
+    {
+      "repository": "https://github.com/Graylog2/graylog2-server.git",
+      "revision": "6.2.0",
+      "server": true,
+      "package_json_files": ["package.json"],
+      "apply": {"from_revision": "main", "new_branch": "6.2", "new_version": "6.2.1-SNAPSHOT"}
+    }
+  ]
+}
+`
+
+// The Maven wrapper records the arguments together with the checked out tag and the tags of the sandbox remote.
+const sandboxTestMavenWrapper = `#!/bin/sh
+tag=$(git -C "$SANDBOX_CHECKOUT" describe --tags --exact-match HEAD 2>/dev/null)
+pushed=$(git -C "$SANDBOX_REMOTE" tag --list | paste -s -d ' ' -)
+echo "$* | checkout=$tag | pushed=$pushed" >> "$SANDBOX_MAVEN_LOG"
+`
+
+// Runs the release steps with the arguments of the sandbox command against a sandbox remote.
+func TestApplyManifestSandboxRelease(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	runGit := func(dir string, args ...string) string {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+
+	// The workspace repository has an "origin" remote and an unpushed local commit in the "main" branch
+	upstream := filepath.Join(t.TempDir(), "graylog2-server.git")
+	workspace := filepath.Join(t.TempDir(), "graylog2-server")
+	runGit(".", "init", "--quiet", "--bare", "--initial-branch", "main", upstream)
+	runGit(".", "init", "--quiet", "--initial-branch", "main", workspace)
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "pom.xml"), []byte(sandboxTestPom), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "package.json"), []byte(sandboxTestPackageJson), 0644))
+	// The project state sync writes the web modules into the server module
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, ".gitignore"), []byte("web-modules.json\n"), 0644))
+	runGit(workspace, "add", "pom.xml", "package.json", ".gitignore")
+	runGit(workspace, "commit", "--quiet", "--message", "initial")
+	runGit(workspace, "tag", "6.1.0")
+	runGit(workspace, "remote", "add", "origin", upstream)
+	runGit(workspace, "push", "--quiet", "origin", "main", "6.1.0")
+	runGit(workspace, "commit", "--quiet", "--allow-empty", "--message", "unpushed")
+
+	sandbox, err := apply.NewSandbox(t.TempDir())
+	require.NoError(t, err)
+	remote, err := sandbox.AddModule(project.Module{Name: "graylog-server", Path: workspace})
+	require.NoError(t, err)
+
+	sourceManifest := filepath.Join(t.TempDir(), "release.json")
+	require.NoError(t, os.WriteFile(sourceManifest, []byte(sandboxTestManifest), 0644))
+	manifestFile := writeSandboxManifest(sandbox, []string{sourceManifest}, map[string]string{"graylog2-server": remote.Path})
+
+	// The Maven wrapper in the project directory takes precedence over "mvn"
+	mavenLog := filepath.Join(t.TempDir(), "maven.log")
+	require.NoError(t, os.WriteFile(filepath.Join(sandbox.ProjectDir(), "mvnw"), []byte(sandboxTestMavenWrapper), 0755))
+	t.Setenv("SANDBOX_CHECKOUT", filepath.Join(sandbox.RepositoriesDir(), "graylog2-server"))
+	t.Setenv("SANDBOX_REMOTE", remote.Path)
+	t.Setenv("SANDBOX_MAVEN_LOG", mavenLog)
+	t.Chdir(sandbox.ProjectDir())
+
+	// Parse the apply-manifest flags of the sandbox command. (there is no JDK in the test environment)
+	args := sandboxReleaseArgs(sandbox, manifestFile)
+	applyManifestArgs := append(args[slices.Index(args, "apply-manifest")+1:], "--skip-check", apply.CheckJDK)
+	require.NoError(t, applyManifestCmd.ParseFlags(applyManifestArgs))
+	t.Cleanup(func() {
+		for _, name := range []string{"execute", "remote", "deploy-repository", "skip-check"} {
+			flag := applyManifestCmd.Flags().Lookup(name)
+			if slice, ok := flag.Value.(interface{ Replace([]string) error }); ok {
+				require.NoError(t, slice.Replace(nil))
+			} else {
+				require.NoError(t, flag.Value.Set(flag.DefValue))
+			}
+			flag.Changed = false
+		}
+	})
+	require.True(t, applyManifestExecute)
+	assert.Equal(t, []string{manifestFile}, applyManifestCmd.Flags().Args())
+
+	var config c.Config
+	config.RepositoryRoot = sandbox.RepositoriesDir()
+	config.Checkout.ManifestFiles = applyManifestCmd.Flags().Args()
+	proj := project.New(config, config.Checkout.ManifestFiles)
+
+	gitConfig := apply.GitConfig{Remote: viper.GetString("apply-manifest.remote"), TagMessage: apply.DefaultTagMessage}
+	runner := apply.StepRunner{Steps: applyManifestSteps(applyManifestContext{
+		config:      config,
+		repoManager: repo.NewRepoManager(config),
+		project:     proj,
+		applier:     apply.NewExecuteApplier([]string{"release"}, apply.WithGitConfig(gitConfig)),
+		msg:         func(message string) { logger.Info("%s", message) },
+	})}
+	require.NoError(t, runner.Run(apply.RunOptions{}))
+
+	report, err := sandbox.Report()
+	require.NoError(t, err)
+	require.Len(t, report.Modules, 1)
+	moduleReport := report.Modules[0]
+
+	require.Len(t, moduleReport.Tags, 1)
+	assert.Equal(t, "6.2.0", moduleReport.Tags[0].Name)
+	assert.True(t, moduleReport.Tags[0].New)
+	assert.Equal(t, "6.2.0", moduleReport.Tags[0].PomVersion)
+	assert.Equal(t, map[string]string{"package.json": "6.2.0"}, moduleReport.Tags[0].PackageJsonVersions)
+
+	require.Len(t, moduleReport.Branches, 2)
+	assert.Equal(t, "6.2", moduleReport.Branches[0].Name)
+	assert.True(t, moduleReport.Branches[0].New)
+	assert.Equal(t, "6.2.1-SNAPSHOT", moduleReport.Branches[0].PomVersion)
+	assert.Equal(t, "main", moduleReport.Branches[1].Name)
+	assert.False(t, moduleReport.Branches[1].New)
+	assert.Equal(t, "6.2.1-SNAPSHOT", moduleReport.Branches[1].PomVersion)
+	assert.Equal(t, map[string]string{"package.json": "6.2.1-SNAPSHOT"}, moduleReport.Branches[1].PackageJsonVersions)
+	// The unpushed workspace commit is part of the release
+	assert.True(t, strings.HasSuffix(runGit(remote.Path, "log", "--format=%s", "6.2.0"), "unpushed\ninitial\n"))
+
+	// The build runs before anything is tagged and pushed, the deployment runs for the pushed release tag
+	buf, err := os.ReadFile(mavenLog)
+	require.NoError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	require.Len(t, calls, 2)
+	assert.Equal(t, "--show-version --batch-mode --fail-fast clean package | checkout= | pushed=6.1.0", calls[0])
+	assert.Equal(t, "--show-version --batch-mode --fail-fast --activate-profiles release -DskipTests"+
+		" -Dlocal.repo.path="+filepath.Join(sandbox.ProjectDir(), "target", "local-maven-repo")+
+		" -DaltDeploymentRepository="+sandbox.DeployRepository()+
+		" clean deploy | checkout=6.2.0 | pushed=6.1.0 6.2.0", calls[1])
+
+	// The module is back on the source branch after the deployment
+	assert.Equal(t, "main\n", runGit(filepath.Join(sandbox.RepositoriesDir(), "graylog2-server"), "rev-parse", "--abbrev-ref", "HEAD"))
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyManifestStepOrder(t *testing.T) {
+	names := lo.Map(applyManifestSteps(applyManifestContext{}), func(step apply.Step, _ int) string {
+		return step.Name
+	})
+	index := func(name string) int {
+		t.Helper()
+		i := lo.IndexOf(names, name)
+		assert.GreaterOrEqual(t, i, 0, "missing step %q", name)
+		return i
+	}
+
+	// Nothing gets modified before the checks passed
+	assert.Equal(t, "preflight", names[0])
+	assert.Less(t, index("preflight"), index("setup-repositories"))
+
+	// All commits, tags and branches get created before anything is pushed
+	for _, name := range []string{"commit-and-tag-release", "commit-development-versions", "create-branches", "rotate-source-branch-changelogs"} {
+		assert.Less(t, index(name), index("push"), name)
+	}
+
+	// Artifacts and GitHub releases are only published after a successful push
+	assert.Less(t, index("push"), index("deploy"))
+	assert.Less(t, index("push"), index("github-releases"))
+	assert.Equal(t, len(names), len(lo.Uniq(names)), "step names must be unique")
+}
+
+func TestApplyManifestSetupStepsExist(t *testing.T) {
+	names := lo.Map(applyManifestSteps(applyManifestContext{}), func(step apply.Step, _ int) string {
+		return step.Name
+	})
+
+	// The plan verification skips these steps by name
+	for _, name := range applyManifestSetupSteps {
+		assert.Contains(t, names, name)
+	}
+}
+
+// The plan of a dry-run in one checkout must match the plan of the execution in another checkout or machine.
+func TestApplyManifestDeployPlanInDifferentCheckouts(t *testing.T) {
+	recordPlan := func(dir string) *apply.Plan {
+		t.Chdir(dir)
+		plan := apply.NewPlan(project.Project{}, []string{"release.json"})
+		plan.SetStep("deploy")
+		require.NoError(t, applyManifestDeploy(apply.NewNoopApplier(nil, apply.WithPlan(plan)), func(string) {}))
+		return plan
+	}
+
+	// A local dry-run with the Maven wrapper
+	local := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(local, "mvnw"), []byte("#!/bin/sh\n"), 0755))
+	dryRun := recordPlan(local)
+
+	// The execution on a CI agent without the Maven wrapper and with colored output
+	t.Setenv("BUILD_ID", "42")
+	execute := recordPlan(t.TempDir())
+
+	assert.Empty(t, dryRun.Diff(execute))
+	assert.Equal(t, []apply.MavenInvocation{{
+		Step:      "deploy",
+		Directory: ".",
+		Profiles:  []string{"release"},
+		Args:      []string{"-DskipTests", "-Dlocal.repo.path=target/local-maven-repo", "clean", "deploy"},
+	}}, execute.Maven)
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/manifest"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var branchCutCmd = &cobra.Command{
+	Use:   "branch-cut [flags] <manifest>",
+	Short: "Create a new stable branch for all release modules",
+	Long: `Create a new stable branch (e.g., "6.2") without creating a release.
+
+The branch gets created in every module of the manifest that isn't skipped
+for releases. The base revision of each module is the revision in the
+manifest unless --base-rev is used. The base revisions must be branches.
+
+The new branch keeps the current development version. (e.g., 6.2.0-SNAPSHOT)
+The source branch gets bumped to the next minor development version.
+(e.g., 6.3.0-SNAPSHOT) The branch name defaults to the major and minor
+version of the server module.
+
+The "changelog/unreleased" folder is split like in an apply-manifest release
+that creates a new branch. The new branch keeps "changelog/unreleased" and
+the source branch moves it to the versioned changelog folder of the current
+version. (e.g., changelog/6.2.0)
+
+  branch "main": changelog/6.2.0 (and a new empty changelog/unreleased folder)
+  branch "6.2":  changelog/unreleased
+
+Both branches of each module are pushed atomically to the configured remote
+after all modules have been updated. Afterwards, a manifest for the new
+branch gets written to manifests/<branch>.json.
+
+Without --execute, the command only shows what would be done.
+
+Examples:
+
+  # Show what would be done
+  $ graylog-project branch-cut manifests/master.json
+
+  # Create the "6.2" branch from the "master" branches
+  $ graylog-project branch-cut --execute --branch 6.2 manifests/master.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  branchCutCommand,
+}
+
+func init() {
+	branchCutCmd.Flags().Bool("execute", false, "Actually create the branches!")
+	branchCutCmd.Flags().String("branch", "", "Name of the new branch (default: major and minor version of the server)")
+	branchCutCmd.Flags().String("base-rev", "", "Base branch for all modules (default: manifest revisions)")
+	branchCutCmd.Flags().String("remote", apply.DefaultRemote, "Git remote for the branches")
+	branchCutCmd.Flags().String("changelog-version", "", "Versioned changelog folder for the unreleased changelogs of the source branch (default: current version)")
+	branchCutCmd.Flags().String("manifest-file", "", "File name of the new branch manifest (default: manifests/<branch>.json)")
+
+	viper.BindPFlag("branch-cut.execute", branchCutCmd.Flags().Lookup("execute"))
+	viper.BindPFlag("branch-cut.branch", branchCutCmd.Flags().Lookup("branch"))
+	viper.BindPFlag("branch-cut.base-rev", branchCutCmd.Flags().Lookup("base-rev"))
+	viper.BindPFlag("branch-cut.remote", branchCutCmd.Flags().Lookup("remote"))
+	viper.BindPFlag("branch-cut.changelog-version", branchCutCmd.Flags().Lookup("changelog-version"))
+	viper.BindPFlag("branch-cut.manifest-file", branchCutCmd.Flags().Lookup("manifest-file"))
+
+	RootCmd.AddCommand(branchCutCmd)
+}
+
+func branchCutCommand(cmd *cobra.Command, args []string) {
+	logger.SetPrefix("[graylog-project]")
+
+	t := time.Now()
+	execute := viper.GetBool("branch-cut.execute")
+	config, repoManager, proj := prepareCheckoutCommand(cmd, args)
+
+	msg := func(message string) {
+		logger.ColorInfo(color.FgYellow, "===> %s", message)
+	}
+
+	proj.Modules = lo.Filter(proj.Modules, func(item project.Module, index int) bool {
+		if item.SkipRelease {
+			msg(fmt.Sprintf("Skipping branch cut for module: %s", item.Name))
+		}
+		return !item.SkipRelease
+	})
+
+	msg("Checkout base revisions")
+	sources := make(map[string]string)
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		base := lo.Ternary(viper.GetString("branch-cut.base-rev") != "", viper.GetString("branch-cut.base-rev"), module.Revision)
+
+		repoManager.EnsureRepository(module, module.Path)
+		repoManager.CheckoutRevision(module.Path, base, module.BaseRevision, module.FetchRevision)
+
+		if branch, err := git.CurrentBranch(module.Path); err != nil || branch != base {
+			logger.Fatal("Base revision %q of module %s must be a branch", base, module.Name)
+		}
+		sources[module.Path] = base
+	})
+
+	// The versions of all modules and submodules by path. Submodules get their web versions set individually.
+	cuts := make(map[string]apply.BranchCut)
+	apply.ForEachModule(proj, true, func(module project.Module) {
+		cut, err := apply.NewBranchCut(module.Version())
+		if err != nil {
+			logger.Fatal("Couldn't compute branch cut versions for %s: %s", module.Name, err)
+		}
+		if changelogVersion := viper.GetString("branch-cut.changelog-version"); changelogVersion != "" {
+			cut.ChangelogVersion = changelogVersion
+		}
+		cuts[module.Path] = cut
+	})
+
+	branch := viper.GetString("branch-cut.branch")
+	if branch == "" {
+		branch = cuts[proj.Server.Path].Branch
+	}
+	manifestFile := viper.GetString("branch-cut.manifest-file")
+	if manifestFile == "" {
+		manifestFile = filepath.Join("manifests", branch+".json")
+	}
+
+	branchCutPreflight(proj, branch, manifestFile)
+	branchCutPrintVersions(proj, sources, cuts, branch)
+
+	gitConfig := apply.GitConfig{Remote: viper.GetString("branch-cut.remote")}
+	var applier apply.Applier
+	var auditLog *apply.AuditLog
+	if execute {
+		auditLog = openAuditLog(config.Checkout.ManifestFiles, "branch-cut")
+		defer auditLog.Close()
+		applier = apply.NewExecuteApplier(nil, apply.WithGitConfig(gitConfig), apply.WithAuditLog(auditLog))
+	} else {
+		applier = apply.NewNoopApplier(nil, apply.WithGitConfig(gitConfig))
+	}
+
+	fail := func(err error) {
+		exitWithAuditSummary(auditLog, err)
+	}
+
+	msg(fmt.Sprintf("Creating branch %s", branch))
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		if err := applier.GitBranch(module, branch); err != nil {
+			fail(err)
+		}
+	})
+
+	// The new branch keeps the "unreleased" changelogs. In the source branch they get moved to the versioned folder,
+	// same as in the "rotate-source-branch-changelogs" step of apply-manifest.
+	msg("Rotating changelogs in source branches")
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		applyManifestInDirectory(module.Path, func() {
+			if err := applier.ChangelogRelease(module.Path, cuts[module.Path].ChangelogVersion); err != nil {
+				fail(err)
+			}
+		})
+	})
+
+	msg("Setting next development version in source branches")
+	apply.ForEachModule(proj, true, func(module project.Module) {
+		applyManifestInDirectory(module.Path, func() {
+			if err := applier.NpmVersionSet(module, cuts[module.Path].Next); err != nil {
+				fail(err)
+			}
+		})
+	})
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		applyManifestInDirectory(module.Path, func() {
+			if err := applier.MavenSetVersion(module, cuts[module.Path].Next); err != nil {
+				fail(err)
+			}
+		})
+
+		// Update all versions after each change!
+		if err := applyManifestUpdateVersions(msg, proj, applier); err != nil {
+			fail(err)
+		}
+	})
+	apply.ForEachModule(proj, true, func(module project.Module) {
+		applyManifestInDirectory(module.Path, func() {
+			if err := applier.NpmVersionCommit(module, cuts[module.Path].Next); err != nil {
+				fail(err)
+			}
+		})
+	})
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		if err := applier.GitCommitDevelopment(module); err != nil {
+			fail(err)
+		}
+	})
+
+	// Nothing gets pushed before all modules have been updated successfully
+	msg("Pushing branches")
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		if err := applier.GitPush(module, apply.BranchCutRefs(sources[module.Path], branch)); err != nil {
+			fail(err)
+		}
+	})
+
+	printAuditSummary(auditLog)
+
+	if execute {
+		branchCutWriteManifest(config.Checkout.ManifestFiles, proj, branch, manifestFile)
+	} else {
+		logger.Info("Would write branch manifest to: %s", manifestFile)
+	}
+
+	logger.Info("DONE! - took: %s", time.Since(t))
+}
+
+// Aborts if the new branch or the branch manifest already exist.
+func branchCutPreflight(proj project.Project, branch string, manifestFile string) {
+	failures := 0
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		exists := false
+		utils.InDirectory(module.Path, func() {
+			exists = git.HasLocalBranch(branch)
+		})
+		if exists {
+			failures++
+			logger.Error("Branch %s already exists in %s", branch, module.Path)
+		}
+
+		remote := viper.GetString("branch-cut.remote")
+		if commit, err := git.RemoteRef(module.Path, remote, "refs/heads/"+branch); err != nil {
+			failures++
+			logger.Error("Couldn't check remote branch %s in %s: %s", branch, module.Path, err)
+		} else if commit != "" {
+			failures++
+			logger.Error("Branch %s already exists on %s for %s", branch, remote, module.Name)
+		}
+	})
+	if utils.FileExists(manifestFile) {
+		failures++
+		logger.Error("Branch manifest %s already exists", manifestFile)
+	}
+
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
+
+func branchCutPrintVersions(proj project.Project, sources map[string]string, cuts map[string]apply.BranchCut, branch string) {
+	logger.ColorInfo(color.FgYellow, "===> Branch cut %s", branch)
+
+	nameLength := 0
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		nameLength = max(nameLength, len(module.Name))
+	})
+
+	apply.ForEachModule(proj, false, func(module project.Module) {
+		cut := cuts[module.Path]
+		logger.Info("  %-*s  %s: %s -> %s (changelog/%s)  %s: %s", nameLength, module.Name,
+			sources[module.Path], cut.Current, cut.Next, cut.ChangelogVersion, branch, cut.Current)
+	})
+}
+
+// Writes the manifest for the new branch. It contains the modules of the given manifest files with the new branch
+// as revision for all branched modules.
+func branchCutWriteManifest(manifestFiles []string, proj project.Project, branch string, manifestFile string) {
+	branched := lo.SliceToMap(proj.Modules, func(module project.Module) (string, bool) {
+		return module.Repository, true
+	})
+
+	branchManifest := manifest.ReadManifest(manifestFiles)
+	branchManifest.Includes = nil
+	branchManifest.DefaultApply = manifest.ManifestApply{}
+	for i, module := range branchManifest.Modules {
+		branchManifest.Modules[i].Apply = manifest.ManifestApply{}
+		if branched[module.Repository] {
+			branchManifest.Modules[i].Revision = branch
+		}
+	}
+
+	buf, err := manifest.Marshal(branchManifest)
+	if err != nil {
+		logger.Fatal("ERROR: %v", err)
+	}
+
+	if err := os.WriteFile(manifestFile, buf, 0644); err != nil {
+		logger.Fatal("Unable to write branch manifest file %s: %v", manifestFile, err)
+	}
+
+	logger.ColorInfo(color.FgGreen, "Wrote branch manifest to: %s", manifestFile)
+	logger.ColorInfo(color.FgYellow, "Make sure to review and commit the new manifest!")
+}
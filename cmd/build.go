@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/build"
+	"github.com/Graylog2/graylog-project-cli/config"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/manifest"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var buildCmd = &cobra.Command{
+	Use:   "build [flags] [goals...]",
+	Short: "Run a Maven build for the selected modules",
+	Long: `Run a Maven build in the graylog-project repository for the selected modules.
+
+The module selection (--selected-modules, --selected-assemblies, --affected) is
+translated into Maven reactor coordinates for the "--projects" option. Without
+any module selection, the complete project gets built. The default goal is
+"package".
+
+After the build, the results of the Maven reactor are printed per module. The
+Maven projects are listed with their manifest module and the path in the module
+repository. (e.g., "graylog2-server/graylog2-web-interface")
+
+Examples:
+
+  # Build the server and everything it needs
+  $ graylog-project -M graylog2-server build --also-make
+
+  # Build and test all modules with changes and all modules depending on them
+  $ graylog-project --affected build --also-make --also-make-dependents verify
+
+  # Build enterprise modules without tests and web interface
+  $ graylog-project -Y enterprise build --skip-tests --skip-web install
+`,
+	Run: buildCommand,
+}
+
+func init() {
+	buildCmd.Flags().BoolP("also-make", "a", false, "Also build the projects required by the selected modules (-am)")
+	buildCmd.Flags().BoolP("also-make-dependents", "d", false, "Also build the projects that depend on the selected modules (-amd)")
+	buildCmd.Flags().StringSliceP("profiles", "P", []string{}, "Maven profiles to activate (comma separated)")
+	buildCmd.Flags().Bool("skip-web", false, "Skip the web interface build")
+	buildCmd.Flags().Bool("skip-tests", false, "Skip running tests")
+	buildCmd.Flags().Bool("dry-run", false, "Only print the Maven command")
+
+	viper.BindPFlag("build.also-make", buildCmd.Flags().Lookup("also-make"))
+	viper.BindPFlag("build.also-make-dependents", buildCmd.Flags().Lookup("also-make-dependents"))
+	viper.BindPFlag("build.profiles", buildCmd.Flags().Lookup("profiles"))
+	viper.BindPFlag("build.skip-web", buildCmd.Flags().Lookup("skip-web"))
+	viper.BindPFlag("build.skip-tests", buildCmd.Flags().Lookup("skip-tests"))
+	viper.BindPFlag("build.dry-run", buildCmd.Flags().Lookup("dry-run"))
+
+	RootCmd.AddCommand(buildCmd)
+}
+
+func buildCommand(cmd *cobra.Command, args []string) {
+	cfg := config.Get()
+	manifestFiles := manifest.ReadState().Files()
+	project := p.New(cfg, manifestFiles)
+
+	var projects []string
+	if cfg.SelectedModules != "" || cfg.SelectedAssemblies != "" || cfg.Affected {
+		projects = build.ReactorProjects(p.SelectedModules(project))
+		if len(projects) == 0 {
+			logger.Info("No Maven modules selected, nothing to build")
+			return
+		}
+	}
+
+	options := build.Options{
+		Goals:              args,
+		Profiles:           viper.GetStringSlice("build.profiles"),
+		AlsoMake:           viper.GetBool("build.also-make"),
+		AlsoMakeDependents: viper.GetBool("build.also-make-dependents"),
+		SkipWeb:            viper.GetBool("build.skip-web"),
+		SkipTests:          viper.GetBool("build.skip-tests"),
+	}
+	mavenArgs := build.MavenArgs(options, projects)
+
+	if viper.GetBool("build.dry-run") {
+		logger.Println("%s %s", utils.MavenBin(), strings.Join(mavenArgs, " "))
+		return
+	}
+
+	results, err := build.Run(utils.GetCwd(), mavenArgs)
+	// The reactor might contain modules that aren't selected (e.g., with --also-make)
+	results = build.ResolveModules(results, project.Modules)
+
+	if len(results) > 0 {
+		maxLength := 0
+		for _, result := range results {
+			maxLength = max(maxLength, len(result.Title()))
+		}
+
+		logger.Info("Reactor results:")
+		for _, result := range results {
+			statusColor := color.FgGreen
+			switch result.Status {
+			case build.StatusFailure:
+				statusColor = color.FgRed
+			case build.StatusSkipped:
+				statusColor = color.FgYellow
+			}
+			logger.ColorInfo(statusColor, "  %-*s  %-7s  %s", maxLength, result.Title(), result.Status, result.Duration)
+		}
+	}
+
+	if err != nil {
+		logger.Error("%s", err)
+		os.Exit(1)
+	}
+}
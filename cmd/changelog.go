@@ -11,12 +11,16 @@ import (
 
 	"github.com/Graylog2/graylog-project-cli/changelog"
 	c "github.com/Graylog2/graylog-project-cli/config"
+	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/git"
 	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/manifest"
 	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/hashicorp/go-version"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var changelogCmd = &cobra.Command{
@@ -37,8 +41,38 @@ var changelogRenderCmd = &cobra.Command{
 	Short:   "Render changelog snippets.",
 	Long: `Render the changelog snippets in the given directory.
 
+The "json" and "yaml" formats render the resolved entries for further
+processing. The documents contain a "schema_version" field which only
+changes for incompatible schema changes.
+
+The "template" format renders a Go template file. (--template) Templates
+with an ".html" or ".htm" extension (optionally followed by ".tmpl") use
+html/template, all others use text/template. The template data contains:
+
+    .Config    The render configuration (e.g., .Config.Product, .Config.ReleaseVersion)
+    .Document  The resolved document of the "json" format (e.g., .Document.Entries)
+    .Types     The types with entries in sort order (.Name, .Title, .Entries)
+
+Helper functions:
+
+    markdown        Convert multi-line Markdown (e.g., details) to HTML
+    markdownInline  Convert a single Markdown line (e.g., messages) to HTML without paragraph
+    title, lower, upper, trim, join SEP LIST, repeat N STR, indent N STR, add A B
+
+With --from and/or --to, the command renders all versioned changelog folders
+in the given changelog directories (default: "changelog") after the --from
+version up to and including the --to version. The versions are ordered by
+semver (newest first, including pre-releases) and every version is rendered
+as its own section. The release date of a version is the date of its tag.
+The "json" format renders an array with one document per version, the "yaml"
+format one YAML document per version, and templates are rendered once per
+version.
+
 Example:
     graylog-project changelog render path/to/snippets
+    graylog-project changelog render --from 5.2.0 --to 6.1.0 changelog
+    graylog-project changelog render --format json path/to/snippets
+    graylog-project changelog render --format template --template slack.tmpl path/to/snippets
 `,
 	Run: changelogRenderCommand,
 }
@@ -74,11 +108,30 @@ var changelogNewCmd = &cobra.Command{
 	Aliases: []string{"n"},
 	Example: `
   graylog-project changelog new changelog/unreleased/issue-123.toml
-  graylog-project changelog new changelog/unreleased/pr-456.toml`,
+  graylog-project changelog new changelog/unreleased/pr-456.toml
+  graylog-project changelog new --from-pr Graylog2/graylog2-server#456
+  graylog-project changelog new --from-pr https://github.com/Graylog2/graylog2-server/pull/456`,
 	Short: "Create new changelog entry.",
-	Args:  cobra.ExactArgs(1),
-	Long:  "Create a new changelog entry based on a template.",
-	Run:   changelogNewCommand,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if changelogEntryFromPR != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	Long: `Create a new changelog entry based on a template.
+
+With --from-pr, the entry gets created from a GitHub pull request. The
+message is the pull request title and the type is derived from the labels.
+(e.g., "bug" for "fixed", default: "fixed") The issues are the issues that
+get closed by the pull request. (e.g., "Fixes #123" in the description) The
+author is added as contributor unless they are a member of the organization.
+
+The "pr-<num>.toml" file is written to the "changelog/unreleased" folder of
+the project module for the pull request repository, or of the current
+repository outside a project. An optional argument overrides the folder.
+The --from-pr flag needs a GitHub access token. (GPC_GITHUB_TOKEN or
+GITHUB_ACCESS_TOKEN)`,
+	Run: changelogNewCommand,
 }
 
 var changelogLintCmd = &cobra.Command{
@@ -89,8 +142,22 @@ var changelogLintCmd = &cobra.Command{
   graylog-project changelog lint changelog/unreleased/pr-456.toml`,
 	Short: "Check changelog entry for syntax and content errors.",
 	Args:  cobra.MinimumNArgs(1),
-	Long:  "Checks a changelog entry for syntax and content errors.",
-	Run:   changelogLintCommand,
+	Long: `Checks a changelog entry for syntax and content errors.
+
+The message style is checked as well. Messages should start with an uppercase
+letter, end with a period, and shouldn't start with words that repeat the
+type. (e.g., "Fixed" for the "fixed" type) Style violations are warnings.
+
+With --github, the referenced issues and pull requests are checked on GitHub.
+The references are resolved against the repository of the snippet file.
+
+  - Referenced issues and pull requests must exist
+  - Pull requests must not be closed without merging (open ones are warnings)
+  - The type should match the pull request labels (warning)
+
+The --github flag needs a GitHub access token. (GPC_GITHUB_TOKEN or
+GITHUB_ACCESS_TOKEN) Use --github-api-url for GitHub Enterprise servers.`,
+	Run: changelogLintCommand,
 }
 
 var changelogRenderFormat string
@@ -103,12 +170,18 @@ var changelogProduct string
 var changelogEntryEdit bool
 var changelogEntryMinimalTemplate bool
 var changelogEntryInteractive bool
+var changelogEntryFromPR string
 var changelogSkipHeader bool
 var changelogRenderNoChanges bool
 var changelogSkipInvalidSnippets bool
 var changelogReadStdin bool
 var changelogMarkdownHeaderBaseLevel int
+var changelogRenderDetails string
+var changelogRenderTemplate string
+var changelogRenderFrom string
+var changelogRenderTo string
 var changelogLintStrict bool
+var changelogLintGitHub bool
 
 func init() {
 	changelogCmd.AddCommand(changelogRenderCmd)
@@ -123,16 +196,21 @@ func init() {
 	changelogNewCmd.Flags().BoolVarP(&changelogEntryEdit, "edit", "e", false, "Start $EDITOR after creating new entry")
 	changelogNewCmd.Flags().BoolVarP(&changelogEntryMinimalTemplate, "minimal-template", "m", false, "Use a minimal entry template")
 	changelogNewCmd.Flags().BoolVarP(&changelogEntryInteractive, "interactive", "i", false, "Fill template values interactively")
+	changelogNewCmd.Flags().StringVar(&changelogEntryFromPR, "from-pr", "", "Create the entry from a GitHub pull request (e.g., \"Graylog2/graylog2-server#123\" or a pull request URL)")
 
 	changelogReleaseCmd.Flags().BoolVar(&changelogReleaseAllowPreRelease, "allow-pre-release", false, "allow pre-release")
 	changelogReleasePathCmd.Flags().StringVarP(&changelogReleaseVersionPattern, "version-pattern", "P", changelog.SemverVersionPattern.String(), "version number pattern")
 	changelogReleasePathCmd.Flags().BoolVar(&changelogReleaseAllowPreRelease, "allow-pre-release", false, "allow pre-release")
 
 	changelogLintCmd.Flags().BoolVarP(&changelogLintStrict, "strict", "s", false, "Exit with an error if no files got linted")
+	changelogLintCmd.Flags().BoolVar(&changelogLintGitHub, "github", false, "Check the referenced issues and pull requests on GitHub (needs "+githubAccessTokenEnv+")")
+	for _, cmd := range []*cobra.Command{changelogNewCmd, changelogLintCmd} {
+		cmd.Flags().String("github-api-url", "", "The GitHub API base URL (e.g., \"https://ghe.example.com/api/v3/\") (env: GPC_GITHUB_API_URL)")
+	}
 }
 
 func applyChangelogRenderFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&changelogRenderFormat, "format", "f", changelog.FormatMD, "The render format. (e.g., \"md\", \"html\", or \"d360html\")")
+	cmd.Flags().StringVarP(&changelogRenderFormat, "format", "f", changelog.FormatMD, "The render format. (e.g., \"md\", \"html\", \"d360html\", \"json\", \"yaml\", or \"template\")")
 	cmd.Flags().BoolVarP(&changelogDisableGitHubLinks, "no-links", "N", false, "Do not render issue or pull-request links for entries.")
 	cmd.Flags().StringVarP(&changelogReleaseDate, "date", "d", time.Now().Format("2006-01-02"), "The release date.")
 	cmd.Flags().StringVarP(&changelogReleaseVersion, "version", "V", "0.0.0", "The release version.")
@@ -143,9 +221,16 @@ func applyChangelogRenderFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&changelogSkipInvalidSnippets, "skip-invalid-snippets", false, "Skip invalid snippet files")
 	cmd.Flags().BoolVar(&changelogReadStdin, "stdin", false, "Read paths from STDIN")
 	cmd.Flags().IntVar(&changelogMarkdownHeaderBaseLevel, "md-header-base-level", 1, "The Markdown header base level")
+	cmd.Flags().StringVar(&changelogRenderFrom, "from", "", "Render all versions after the given version (range mode, exclusive)")
+	cmd.Flags().StringVar(&changelogRenderTo, "to", "", "Render all versions up to the given version (range mode, inclusive)")
+	cmd.Flags().StringVar(&changelogRenderTemplate, "template", "", "The Go template file for the \"template\" format")
+	cmd.Flags().StringVar(&changelogRenderDetails, "details", changelog.DetailsAggregated, "How to render the user and ops details. (\"aggregated\" sections after the types, \"inline\" per entry, or \"omit\")")
 }
 
 func changelogRenderCommand(cmd *cobra.Command, args []string) {
+	if changelogRangeMode() && len(args) == 0 {
+		args = []string{changelogDefaultPath}
+	}
 	if len(args) == 0 && !changelogReadStdin {
 		logger.Error("Missing snippet directories")
 		if err := cmd.UsageFunc()(cmd); err != nil {
@@ -162,21 +247,53 @@ func changelogRenderCommand(cmd *cobra.Command, args []string) {
 		return path
 	})
 
-	if err := execChangelogRenderCommand(snippetsPaths); err != nil {
+	if err := execChangelogRenderCommand(cmd, snippetsPaths, nil, nil); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 }
 
-func execChangelogRenderCommand(snippetsPaths []string) error {
+// The changelog folder of a repository. (contains the "unreleased" and versioned folders)
+const changelogDefaultPath = "changelog"
+
+// Returns true if a version range should be rendered. (--from and --to flags)
+func changelogRangeMode() bool {
+	return changelogRenderFrom != "" || changelogRenderTo != ""
+}
+
+// Renders the changelog for the given snippets paths. The modules map the snippets paths to module names.
+// In range mode, the paths are changelog folders that contain the versioned snippets folders.
+func execChangelogRenderCommand(cmd *cobra.Command, snippetsPaths []string, modules map[string]string, groups []changelog.Group) error {
 	if !lo.Contains(changelog.AvailableFormatters, changelogRenderFormat) {
 		return fmt.Errorf("invalid render format: %s (available: %s)", changelogRenderFormat, strings.Join(changelog.AvailableFormatters, ", "))
 	}
 
+	if changelogRenderFormat == changelog.FormatTemplate && changelogRenderTemplate == "" {
+		return errors.New("missing --template flag for template format")
+	}
+	if changelogRenderFormat != changelog.FormatTemplate && changelogRenderTemplate != "" {
+		return fmt.Errorf("the --template flag requires the %q format", changelog.FormatTemplate)
+	}
+
+	if !lo.Contains(changelog.AvailableDetailsModes, changelogRenderDetails) {
+		return fmt.Errorf("invalid details mode: %s (available: %s)", changelogRenderDetails, strings.Join(changelog.AvailableDetailsModes, ", "))
+	}
+
 	if len(snippetsPaths) == 0 && !changelogReadStdin {
 		return errors.New("missing snippet directories")
 	}
 
+	if changelogRangeMode() {
+		if changelogReadStdin {
+			return errors.New("the --stdin flag can't be used with --from and --to")
+		}
+		if cmd.Flags().Changed("version") {
+			return errors.New("the --version flag can't be used with --from and --to")
+		}
+		versionRange := changelog.VersionRange{From: changelogRenderFrom, To: changelogRenderTo}
+		return changelog.RenderRange(changelogRenderConfig(nil, "", modules, groups), snippetsPaths, versionRange, os.Stdout)
+	}
+
 	versionPattern, err := regexp.Compile(changelogReleaseVersionPattern)
 	if err != nil {
 		return fmt.Errorf("invalid version pattern: %s", changelogReleaseVersionPattern)
@@ -197,7 +314,15 @@ func execChangelogRenderCommand(snippetsPaths []string) error {
 		return fmt.Errorf("invalid version: %s", releaseVersion)
 	}
 
-	config := changelog.Config{
+	if err := changelog.Render(changelogRenderConfig(snippetsPaths, releaseVersion, modules, groups), os.Stdout); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func changelogRenderConfig(snippetsPaths []string, releaseVersion string, modules map[string]string, groups []changelog.Group) changelog.Config {
+	return changelog.Config{
 		RenderFormat:            changelogRenderFormat,
 		RenderGitHubLinks:       !changelogDisableGitHubLinks,
 		SnippetsPaths:           snippetsPaths,
@@ -209,13 +334,11 @@ func execChangelogRenderCommand(snippetsPaths []string) error {
 		RenderNoChanges:         changelogRenderNoChanges,
 		SkipInvalidSnippets:     changelogSkipInvalidSnippets,
 		MarkdownHeaderBaseLevel: changelogMarkdownHeaderBaseLevel,
+		Details:                 changelogRenderDetails,
+		Modules:                 modules,
+		TemplateFile:            changelogRenderTemplate,
+		Groups:                  groups,
 	}
-
-	if err := changelog.Render(config, os.Stdout); err != nil {
-		return err
-	}
-
-	return nil
 }
 
 func changelogReleaseCommand(cmd *cobra.Command, args []string) error {
@@ -278,13 +401,103 @@ func changelogReleasePathCommand(cmd *cobra.Command, args []string) {
 }
 
 func changelogNewCommand(cmd *cobra.Command, args []string) {
+	if changelogEntryFromPR != "" {
+		changelogNewFromPullRequest(cmd, args)
+		return
+	}
+
 	if err := changelog.NewEntry(args[0], changelogEntryEdit, changelogEntryMinimalTemplate, changelogEntryInteractive); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 }
+func changelogNewFromPullRequest(cmd *cobra.Command, args []string) {
+	if changelogEntryInteractive {
+		logger.Fatal("The --interactive flag can't be used with --from-pr")
+	}
+
+	repository, number, err := utils.ParseGitHubPRString(changelogEntryFromPR)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	owner, repo, err := gh.SplitRepoString(repository)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	var directory string
+	if len(args) > 0 {
+		directory = args[0]
+	} else if directory, err = changelogUnreleasedPath(owner + "/" + repo); err != nil {
+		logger.Fatal(err.Error())
+	}
+
+	client := changelogGitHubClient(cmd, "--from-pr")
+	if err := changelog.NewEntryFromPullRequest(client, owner, repo, number, directory, changelogEntryEdit, changelogEntryMinimalTemplate); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// Returns the "changelog/unreleased" folder of the project module for the given GitHub repository. (e.g.,
+// "Graylog2/graylog2-server") Outside a project, the current repository must match.
+func changelogUnreleasedPath(repository string) (string, error) {
+	matches := func(repositoryURL string) bool {
+		githubURL, err := utils.ParseGitHubURL(repositoryURL)
+		return err == nil && strings.EqualFold(strings.TrimSuffix(githubURL.Repository(), ".git"), repository)
+	}
+
+	if utils.FileExists(manifest.ManifestStateFile) {
+		proj := p.New(c.Get(), manifest.ReadState().Files())
+		for _, module := range proj.Modules {
+			if matches(module.Repository) {
+				return filepath.Join(module.Path, "changelog", "unreleased"), nil
+			}
+		}
+		return "", fmt.Errorf("couldn't find a project module for repository %s", repository)
+	}
+
+	toplevel, err := git.ToplevelPath()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find a project or Git repository for repository %s: %w", repository, err)
+	}
+	remoteURL, err := git.GetRemoteUrl(toplevel, "origin")
+	if err != nil {
+		return "", err
+	}
+	if !matches(remoteURL) {
+		return "", fmt.Errorf("current repository %s doesn't match repository %s", remoteURL, repository)
+	}
+	return filepath.Join(toplevel, "changelog", "unreleased"), nil
+}
+
+// Returns the GitHub client for the changelog commands. The given flag is only used for the error message.
+func changelogGitHubClient(cmd *cobra.Command, flag string) *gh.Client {
+	token := viper.GetString("github.access-token")
+	if token == "" {
+		logger.Fatal("Missing GitHub access token for %s (env: %s)", flag, githubAccessTokenEnv)
+	}
+
+	baseURL := viper.GetString("github.api-url")
+	if cmd.Flags().Changed("github-api-url") {
+		baseURL, _ = cmd.Flags().GetString("github-api-url")
+	}
+
+	client, err := gh.NewGitHubClientWithBaseURL(token, baseURL)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	return client
+}
+
 func changelogLintCommand(cmd *cobra.Command, args []string) {
-	if err := changelog.LintPaths(args, changelogLintStrict); err != nil {
+	options := changelog.LintOptions{Strict: changelogLintStrict}
+
+	if changelogLintGitHub {
+		options.GitHub = changelogGitHubClient(cmd, "--github")
+	}
+
+	if err := changelog.LintPaths(args, options); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
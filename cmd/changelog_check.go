@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/changelog"
+	"github.com/Graylog2/graylog-project-cli/gh"
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	annotationsAuto   = "auto"
+	annotationsGitHub = "github"
+	annotationsPlain  = "plain"
+)
+
+var changelogCheckCmd = &cobra.Command{
+	Use:   "check [flags]",
+	Short: "Check that a branch contains a changelog snippet",
+	Long: `Check that the changes of the current branch contain a changelog snippet.
+
+The command compares HEAD with the merge base of the --base ref and checks
+that a "changelog/unreleased/*.toml" file has been added or modified. The
+changed snippets are linted. (see "changelog lint --help") For modules in a
+repository subdirectory, the --path flag sets the module path. (e.g., the
+"path" of the manifest module)
+
+Without a changed snippet, the check fails unless an opt-out applies:
+
+  - The pull request has one of the --skip-label labels. The labels are
+    passed with --labels or fetched from GitHub with --pr.
+  - A commit has the --skip-trailer trailer with the value "skip", "none",
+    or "no". (e.g., "Changelog: skip")
+  - All changed files match the --ignore glob patterns. ("*" and "?" don't
+    match "/", "**" matches any number of directories)
+
+Failures are printed as GitHub Actions annotations when running in GitHub
+Actions and as plain "ERROR:" lines otherwise. (see --annotations)
+
+The flags can also be set in the config file. (e.g., "changelog.check.ignore")
+
+Examples:
+
+  $ graylog-project changelog check --base origin/master
+
+  $ graylog-project changelog check --base origin/master --labels "bug,no-changelog"
+
+  $ graylog-project changelog check --base origin/master --ignore "docs/**" --ignore "**/*.md"
+
+  $ graylog-project changelog check --base origin/master --path graylog-plugin-foo
+`,
+	Args: cobra.NoArgs,
+	Run:  changelogCheckCommand,
+}
+
+func init() {
+	changelogCheckCmd.Flags().String("base", "", "Base ref of the changes (e.g., \"origin/master\")")
+	changelogCheckCmd.Flags().String("path", "", "Path of the module in the repository (default: the repository root)")
+	changelogCheckCmd.Flags().StringSlice("labels", []string{}, "Labels of the pull request (comma separated)")
+	changelogCheckCmd.Flags().String("pr", "", "Fetch the labels of the given pull request from GitHub (e.g., \"Graylog2/graylog2-server#123\")")
+	changelogCheckCmd.Flags().StringSlice("skip-label", []string{"no-changelog"}, "Pull request labels that skip the check")
+	changelogCheckCmd.Flags().String("skip-trailer", "Changelog", "Commit trailer that skips the check (empty to disable)")
+	changelogCheckCmd.Flags().StringSlice("ignore", []string{}, "Glob patterns for changes that don't need a snippet")
+	changelogCheckCmd.Flags().String("annotations", annotationsAuto, "Annotation format for failures (\"auto\", \"github\", or \"plain\")")
+	changelogCheckCmd.Flags().String("github-api-url", "", "The GitHub API base URL (e.g., \"https://ghe.example.com/api/v3/\") (env: GPC_GITHUB_API_URL)")
+
+	viper.BindPFlag("changelog.check.base", changelogCheckCmd.Flags().Lookup("base"))
+	viper.BindPFlag("changelog.check.path", changelogCheckCmd.Flags().Lookup("path"))
+	viper.BindPFlag("changelog.check.labels", changelogCheckCmd.Flags().Lookup("labels"))
+	viper.BindPFlag("changelog.check.pr", changelogCheckCmd.Flags().Lookup("pr"))
+	viper.BindPFlag("changelog.check.skip-label", changelogCheckCmd.Flags().Lookup("skip-label"))
+	viper.BindPFlag("changelog.check.skip-trailer", changelogCheckCmd.Flags().Lookup("skip-trailer"))
+	viper.BindPFlag("changelog.check.ignore", changelogCheckCmd.Flags().Lookup("ignore"))
+	viper.BindPFlag("changelog.check.annotations", changelogCheckCmd.Flags().Lookup("annotations"))
+
+	changelogCmd.AddCommand(changelogCheckCmd)
+}
+
+func changelogCheckCommand(cmd *cobra.Command, args []string) {
+	options := changelog.CheckOptions{
+		Base:           viper.GetString("changelog.check.base"),
+		Path:           viper.GetString("changelog.check.path"),
+		Labels:         viper.GetStringSlice("changelog.check.labels"),
+		SkipLabels:     viper.GetStringSlice("changelog.check.skip-label"),
+		SkipTrailer:    viper.GetString("changelog.check.skip-trailer"),
+		IgnorePatterns: viper.GetStringSlice("changelog.check.ignore"),
+	}
+	if options.Base == "" {
+		exitWithUsage(cmd, "Missing --base flag")
+	}
+
+	annotations := viper.GetString("changelog.check.annotations")
+	switch annotations {
+	case annotationsAuto:
+		annotations = annotationsPlain
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			annotations = annotationsGitHub
+		}
+	case annotationsGitHub, annotationsPlain:
+	default:
+		logger.Fatal("Invalid annotations format: %s (available: %s, %s, %s)", annotations, annotationsAuto, annotationsGitHub, annotationsPlain)
+	}
+
+	if pr := viper.GetString("changelog.check.pr"); pr != "" {
+		repository, number, err := utils.ParseGitHubPRString(pr)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		owner, repo, err := gh.SplitRepoString(repository)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		pullRequest, err := changelogGitHubClient(cmd, "--pr").GetPullRequest(owner, repo, number)
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		options.Labels = append(options.Labels, pullRequest.Labels...)
+	}
+
+	toplevel, err := git.ToplevelPath()
+	if err != nil {
+		logger.Fatal("Couldn't find Git repository: %s", err)
+	}
+
+	result, err := changelog.Check(toplevel, options)
+	if err != nil {
+		title := "Changelog check failed"
+		message := err.Error()
+		if errors.Is(err, changelog.ErrMissingSnippet) {
+			title = "Missing changelog snippet"
+			message = fmt.Sprintf("The changes since %s don't contain a changelog snippet. Please add a %q file. (see \"graylog-project changelog new --help\")",
+				options.Base, path.Join(filepath.ToSlash(options.Path), "changelog/unreleased/pr-<number>.toml"))
+		}
+		printCheckAnnotation(annotations, title, message)
+		os.Exit(1)
+	}
+
+	if result.SkipReason != "" {
+		logger.ColorInfo(color.FgYellow, "Skipping changelog check: %s", result.SkipReason)
+		return
+	}
+	logger.ColorInfo(color.FgGreen, "Found changelog snippet(s): %s", strings.Join(result.Snippets, ", "))
+}
+
+// Prints the failure as GitHub Actions workflow command or as plain "ERROR:" line for other CI systems.
+func printCheckAnnotation(format string, title string, message string) {
+	if format == annotationsGitHub {
+		escape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+		propertyEscape := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+		fmt.Printf("::error title=%s::%s\n", propertyEscape.Replace(title), escape.Replace(message))
+		return
+	}
+	fmt.Printf("ERROR: %s: %s\n", title, message)
+}
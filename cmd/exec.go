@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,8 +11,10 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Graylog2/graylog-project-cli/config"
+	pexec "github.com/Graylog2/graylog-project-cli/exec"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/manifest"
 	p "github.com/Graylog2/graylog-project-cli/project"
@@ -38,6 +42,16 @@ The command has access to the following environment variables:
 - GPC_MODULE_VERSION: Maven version of the module
 - GPC_MODULE_SERVER: Whether the module is a server module
 - GPC_MODULE_SKIP_RELEASE: Whether the module is skipped for release
+
+When the --log-dir flag is used, the output of every module is also written to
+"<log-dir>/<module-name>.log" and a report with one entry per module is written
+to "<log-dir>/exec-report.xml" (JUnit XML) and "<log-dir>/exec-report.json".
+The command exits with a non-zero code if the command failed in any module.
+
+Example:
+
+  # Run checks in all modules and create per-module logs and a JUnit report for CI
+  $ graylog-project exec --force --log-dir target/exec-logs "npm run lint"
 `,
 	Run: execCommand,
 }
@@ -48,11 +62,15 @@ func init() {
 	execCmd.Flags().BoolP("force", "f", false, "Continue to execute the command even when it returns a non-zero code")
 	execCmd.Flags().BoolP("template", "t", false, "Process the command as Go template")
 	execCmd.Flags().BoolP("web", "w", false, "Exec command only in web modules")
+	execCmd.Flags().StringP("log-dir", "l", "", "Write per-module logs and JUnit/JSON reports to the given directory")
 	viper.BindPFlag("exec.force", execCmd.Flags().Lookup("force"))
 	viper.BindPFlag("exec.template", execCmd.Flags().Lookup("template"))
 	viper.BindPFlag("exec.web", execCmd.Flags().Lookup("web"))
+	viper.BindPFlag("exec.log-dir", execCmd.Flags().Lookup("log-dir"))
 }
 
+const execReportBasename = "exec-report"
+
 func execCommand(cmd *cobra.Command, args []string) {
 	if len(args) < 1 {
 		logger.Error("Missing command")
@@ -65,40 +83,48 @@ func execCommand(cmd *cobra.Command, args []string) {
 
 	logger.Info("Current manifests: %v", manifestFiles)
 
+	logDir := viper.GetString("exec.log-dir")
+	var report *pexec.Report
+	if logDir != "" {
+		logDir = utils.GetAbsolutePath(logDir)
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			logger.Fatal("Couldn't create log directory %s: %v", logDir, err)
+		}
+		report = pexec.NewReport("graylog-project exec")
+	}
+
 	if viper.GetBool("exec.web") {
 		logger.Info("Executing `%v` for every selected web module", strings.Join(args, " "))
 		p.ForEachSelectedModuleOrSubmodules(project, func(module p.Module) {
 			if module.IsNpmModule() {
-				execForPath(module, args)
+				execForPath(module, args, logDir, report)
 			}
 		})
 	} else {
 		logger.Info("Executing `%v` for every selected module", strings.Join(args, " "))
 		p.ForEachSelectedModule(project, func(module p.Module) {
-			execForPath(module, args)
+			execForPath(module, args, logDir, report)
 		})
 	}
-}
-
-func execForPath(module p.Module, args []string) {
-	defer utils.Chdir(utils.GetCwd())
-
-	logger.ColorPrintln(color.FgMagenta, "[command output: %v]", filepath.Base(module.Path))
-
-	utils.Chdir(module.Path)
-
-	cmdLine := strings.Join(args, " ")
 
-	var tmpl *template.Template
-	if viper.GetBool("exec.template") {
-		var err error
-		tmpl, err = template.New("cmd").Option("missingkey=error").Parse(cmdLine)
-		if err != nil {
-			logger.Fatal("Couldn't parse template: %v", err)
+	if report != nil {
+		writeExecReport(report, logDir)
+		if report.Failures() > 0 {
+			os.Exit(1)
 		}
 	}
+}
+
+func writeExecReport(report *pexec.Report, logDir string) {
+	if err := report.WriteFiles(logDir, execReportBasename); err != nil {
+		logger.Fatal("Couldn't write report: %v", err)
+	}
+	logger.Info("Wrote report for %d module(s) (%d failed) to %s",
+		len(report.Results), report.Failures(), filepath.Join(logDir, execReportBasename+".{xml,json}"))
+}
 
-	inventory := map[string]string{
+func execModuleInventory(module p.Module) map[string]string {
+	return map[string]string{
 		"GPC_MODULE_NAME":          module.Name,
 		"GPC_MODULE_PATH":          module.Path,
 		"GPC_MODULE_PATH_BASENAME": filepath.Base(module.Path),
@@ -110,37 +136,105 @@ func execForPath(module p.Module, args []string) {
 		"GPC_MODULE_SERVER":        strconv.FormatBool(module.Server),
 		"GPC_MODULE_SKIP_RELEASE":  strconv.FormatBool(module.SkipRelease),
 	}
-	env := make([]string, 0)
+}
 
-	for k, v := range inventory {
-		env = append(env, k+"="+v)
+// Renders the given command line as Go template with the module inventory as data.
+func execRenderTemplate(cmdLine string, inventory map[string]string) string {
+	tmpl, err := template.New("cmd").Option("missingkey=error").Parse(cmdLine)
+	if err != nil {
+		logger.Fatal("Couldn't parse template: %v", err)
 	}
 
 	var cmdBuf bytes.Buffer
+	if err := tmpl.Execute(&cmdBuf, inventory); err != nil {
+		logger.Fatal("Couldn't execute template: %v", err)
+	}
+
+	return cmdBuf.String()
+}
+
+func execForPath(module p.Module, args []string, logDir string, report *pexec.Report) {
+	cmdLine := strings.Join(args, " ")
+	inventory := execModuleInventory(module)
 
 	if viper.GetBool("exec.template") {
-		if err := tmpl.Execute(&cmdBuf, inventory); err != nil {
-			logger.Fatal("Couldn't execute template: %v", err)
-		}
-	} else {
-		cmdBuf.WriteString(cmdLine)
+		cmdLine = execRenderTemplate(cmdLine, inventory)
+	}
+
+	execCommandLineForPath(module, cmdLine, inventory, viper.GetBool("exec.force"), logDir, report)
+}
+
+// Executes the given command line in the module path. The output is written to a log file in the given log
+// directory and the result is added to the report if the report isn't nil.
+func execCommandLineForPath(module p.Module, cmdLine string, inventory map[string]string, force bool, logDir string, report *pexec.Report) {
+	defer utils.Chdir(utils.GetCwd())
+
+	logger.ColorPrintln(color.FgMagenta, "[command output: %v]", filepath.Base(module.Path))
+
+	utils.Chdir(module.Path)
+
+	env := make([]string, 0)
+
+	for k, v := range inventory {
+		env = append(env, k+"="+v)
 	}
 
 	var command *exec.Cmd
 	if runtime.GOOS == "windows" {
-		command = exec.Command("cmd.exe", "/c", cmdBuf.String())
+		command = exec.Command("cmd.exe", "/c", cmdLine)
 	} else {
-		command = exec.Command("sh", "-c", cmdBuf.String())
+		command = exec.Command("sh", "-c", cmdLine)
 	}
 
 	command.Stdout = os.Stdout
 	command.Stderr = os.Stderr
 	command.Env = append(os.Environ(), env...)
 
-	if err := command.Run(); err != nil {
-		if viper.GetBool("exec.force") {
+	var output bytes.Buffer
+	var logFilename string
+	if report != nil {
+		logFilename = report.LogFilename(logDir, module.Name)
+		logFile, err := os.Create(logFilename)
+		if err != nil {
+			logger.Fatal("Couldn't create log file %s: %v", logFilename, err)
+		}
+		defer logFile.Close()
+
+		command.Stdout = io.MultiWriter(os.Stdout, logFile, &output)
+		command.Stderr = io.MultiWriter(os.Stderr, logFile, &output)
+	}
+
+	start := time.Now()
+	err := command.Run()
+
+	if report != nil {
+		exitCode := 0
+		if err != nil {
+			exitCode = -1
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			}
+		}
+		report.Add(pexec.ModuleResult{
+			Module:   module.Name,
+			Path:     module.Path,
+			Command:  cmdLine,
+			Start:    start,
+			Duration: time.Since(start),
+			ExitCode: exitCode,
+			LogFile:  logFilename,
+			Output:   output.String(),
+		})
+	}
+
+	if err != nil {
+		if force {
 			logger.Error("Command failed, continuing in other modules: %v", err)
 		} else {
+			if report != nil {
+				writeExecReport(report, logDir)
+			}
 			logger.Fatal("Command failed: %v", err)
 		}
 	}
@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/Graylog2/graylog-project-cli/apply"
+	c "github.com/Graylog2/graylog-project-cli/config"
 	"github.com/Graylog2/graylog-project-cli/gh"
 	"github.com/Graylog2/graylog-project-cli/git"
 	"github.com/Graylog2/graylog-project-cli/logger"
+	p "github.com/Graylog2/graylog-project-cli/project"
 	"github.com/Graylog2/graylog-project-cli/utils"
 	"github.com/mattn/go-isatty"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
@@ -22,9 +26,18 @@ var githubCmd = &cobra.Command{
 Examples:
     # Create app installation access token for a GitHub org
     graylog-project github generate-app-token -o GitHub-org-name -a 1234 -k path/to/app/private.key
+
+The GitHub access token is read from the GPC_GITHUB_TOKEN or the
+GITHUB_ACCESS_TOKEN environment variable. GPC_GITHUB_TOKEN takes precedence
+when both are set.
 `,
 }
 
+// The environment variables for the GitHub access token in order of precedence.
+const githubAccessTokenEnv = "GPC_GITHUB_TOKEN or GITHUB_ACCESS_TOKEN"
+
+var errMissingGitHubAccessToken = fmt.Errorf("missing GitHub access token (env: %s)", githubAccessTokenEnv)
+
 var githubAppAccessTokenGenerateCmd = &cobra.Command{
 	Use:     "generate-app-access-token",
 	Aliases: []string{"gaat"},
@@ -62,6 +75,32 @@ var githubBranchProtectionCmd = &cobra.Command{
 	},
 }
 
+var githubReleaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Manage GitHub releases",
+}
+
+var githubReleaseCreateCmd = &cobra.Command{
+	Use:   "create [flags] <apply-manifest>",
+	Short: "Create or update GitHub releases for the modules of an apply manifest",
+	Long: `Create or update a GitHub release for the release tag of every module in
+the given apply manifest. The release notes are rendered from the versioned
+changelog folder of the module. Versions with a pre-release part (e.g.,
+"6.2.0-rc.1") are marked as pre-release.
+
+The release tags must already exist on GitHub.
+
+Examples:
+    # Show the releases that would be created
+    graylog-project github release create --dry-run manifests/release-6.2.0.json
+
+    # Create the releases, pre-releases as draft
+    graylog-project github release create --draft prerelease manifests/release-6.2.0.json
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: githubReleaseCreate,
+}
+
 var githubParsePullDependenciesCmd = &cobra.Command{
 	Use:     "parse-pull-request-dependencies",
 	Aliases: []string{"parse-pr-deps"},
@@ -83,7 +122,9 @@ func init() {
 	viper.MustBindEnv("github.app-id", "GPC_GITHUB_APP_ID")
 	viper.MustBindEnv("github.app-key", "GPC_GITHUB_APP_KEY")
 	viper.MustBindEnv("github.org", "GPC_GITHUB_ORG")
+	// The first non-empty variable wins
 	viper.MustBindEnv("github.access-token", "GPC_GITHUB_TOKEN", "GITHUB_ACCESS_TOKEN")
+	viper.MustBindEnv("github.api-url", "GPC_GITHUB_API_URL")
 
 	githubRulesetsCmd.AddCommand(githubRulesetsEnableCmd)
 	githubRulesetsCmd.AddCommand(githubRulesetsDisableCmd)
@@ -92,7 +133,16 @@ func init() {
 	githubParsePullDependenciesCmd.Flags().StringP("file", "f", "-", "Read pull request body from file")
 	githubParsePullDependenciesCmd.Flags().StringP("join", "j", "", "Join output to one line by given join string")
 
+	githubReleaseCreateCmd.Flags().String("draft", string(apply.DraftNone), "Create releases as draft (\"none\", \"prerelease\" or \"all\")")
+	githubReleaseCreateCmd.Flags().Bool("dry-run", false, "Only show the releases that would be created")
+
+	viper.BindPFlag("github.release.draft", githubReleaseCreateCmd.Flags().Lookup("draft"))
+	viper.BindPFlag("github.release.dry-run", githubReleaseCreateCmd.Flags().Lookup("dry-run"))
+
+	githubReleaseCmd.AddCommand(githubReleaseCreateCmd)
+
 	githubCmd.AddCommand(githubAppAccessTokenGenerateCmd)
+	githubCmd.AddCommand(githubReleaseCmd)
 	githubCmd.AddCommand(githubRulesetsCmd)
 	githubCmd.AddCommand(githubBranchProtectionCmd)
 	githubCmd.AddCommand(githubParsePullDependenciesCmd)
@@ -190,7 +240,7 @@ func githubToggleRuleset(_ *cobra.Command, args []string, cb func(client *gh.Cli
 	}
 
 	if cfg.GitHub.AccessToken == "" {
-		return fmt.Errorf("missing GitHub access token (GITHUB_ACCESS_TOKEN)")
+		return errMissingGitHubAccessToken
 	}
 
 	client := gh.NewGitHubClient(cfg.GitHub.AccessToken)
@@ -215,6 +265,44 @@ func githubDisableRuleset(cmd *cobra.Command, args []string) error {
 	})
 }
 
+func githubReleaseCreate(_ *cobra.Command, args []string) error {
+	var cfg gitHubCmdConfig
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("couldn't deserialize config: %w", err)
+	}
+
+	options := apply.GitHubReleaseOptions{Draft: apply.DraftMode(viper.GetString("github.release.draft"))}
+	if !lo.Contains(apply.DraftModes, options.Draft) {
+		return fmt.Errorf("invalid draft mode %q (valid: %v)", options.Draft, apply.DraftModes)
+	}
+
+	var applier apply.Applier
+	if viper.GetBool("github.release.dry-run") {
+		applier = apply.NewNoopApplier(nil)
+	} else {
+		if cfg.GitHub.AccessToken == "" {
+			return errMissingGitHubAccessToken
+		}
+		applier = apply.NewExecuteApplier(nil, apply.WithGitHubClient(gh.NewGitHubClient(cfg.GitHub.AccessToken)))
+	}
+
+	proj := p.New(c.Get(), args)
+
+	var err error
+	apply.ForEachModule(proj, false, func(module p.Module) {
+		if err != nil || module.SkipRelease {
+			return
+		}
+		var release apply.GitHubRelease
+		if release, err = apply.NewGitHubRelease(module, options); err != nil {
+			return
+		}
+		err = applier.GitHubRelease(module, release)
+	})
+
+	return err
+}
+
 func githubParsePullDependencies(cmd *cobra.Command, args []string) error {
 	additional, err := cmd.Flags().GetStringSlice("add")
 	if err != nil {
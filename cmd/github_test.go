@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubAccessTokenEnvPrecedence(t *testing.T) {
+	t.Setenv("GITHUB_ACCESS_TOKEN", "access-token")
+	t.Setenv("GPC_GITHUB_TOKEN", "")
+	assert.Equal(t, "access-token", viper.GetString("github.access-token"))
+
+	t.Setenv("GPC_GITHUB_TOKEN", "gpc-token")
+	assert.Equal(t, "gpc-token", viper.GetString("github.access-token"))
+}
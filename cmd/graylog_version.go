@@ -20,6 +20,9 @@ var graylogVersionCmd = &cobra.Command{
 	Short:   "Sets the graylog version",
 	Long: `This command sets the given Graylog version in all pom.xml files.
 
+All changes are appended to a JSON lines audit log next to the checked out
+manifest. (e.g., manifests/master.audit.jsonl)
+
 Examples:
     # Set Graylog version for all modules
     graylog-project graylog-version --set 2.3.0
@@ -73,26 +76,43 @@ func graylogVersionCommand(cmd *cobra.Command, args []string) {
 		logger.ColorInfo(color.FgYellow, "===> %s", message)
 	}
 
-	applier := apply.NewExecuteApplier([]string{})
+	validatePackageJsonFiles(proj)
+
+	auditLog := openAuditLog(manifestFiles, "graylog-version")
+	defer auditLog.Close()
+
+	applier := apply.NewExecuteApplier([]string{}, apply.WithAuditLog(auditLog))
+
+	fail := func(err error) {
+		exitWithAuditSummary(auditLog, err)
+	}
 
 	msg("Setting version in all modules")
 	apply.ForEachModule(proj, false, func(module project.Module) {
 		applyManifestInDirectory(module.Path, func() {
-			applier.MavenVersionsSet(graylogVersion)
+			if err := applier.MavenSetVersion(module, graylogVersion); err != nil {
+				fail(err)
+			}
 		})
 
 		// Update all versions after each change!
-		applyManifestUpdateVersions(msg, proj, applier)
+		if err := applyManifestUpdateVersions(msg, proj, applier); err != nil {
+			fail(err)
+		}
 	})
 
 	msg("Setting version in all web modules")
 	apply.ForEachModule(proj, true, func(module project.Module) {
 		applyManifestInDirectory(module.Path, func() {
-			applier.NpmVersionSet(module, graylogVersion)
+			if err := applier.NpmVersionSet(module, graylogVersion); err != nil {
+				fail(err)
+			}
 		})
 	})
 
 	// Regenerate the graylog-project pom and assembly files to get the latest versions
 	msg("Regenerate pom and assembly templates")
 	projectstate.Sync(proj, cfg)
+
+	printAuditSummary(auditLog)
 }
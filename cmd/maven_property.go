@@ -32,18 +32,23 @@ Examples:
 
     # Sets the graylog.version property in the map-widget module to 2.1.1
     graylog-project -M map-widget maven-property --set graylog.version 2.1.1
+
+    # Sets the graylog.version property and adds it to modules that don't have it yet
+    graylog-project maven-property --set --add graylog.version 2.1.1
 `,
 	Run: mavenPropertyCommand,
 }
 
 var mavenPropertySet bool
 var mavenPropertyAll bool
+var mavenPropertyAdd bool
 
 func init() {
 	RootCmd.AddCommand(mavenPropertyCmd)
 
 	mavenPropertyCmd.Flags().BoolVarP(&mavenPropertySet, "set", "", false, "Set property. Requires a second argument that is the new value.")
 	mavenPropertyCmd.Flags().BoolVarP(&mavenPropertyAll, "all", "a", false, "Show all properties")
+	mavenPropertyCmd.Flags().BoolVarP(&mavenPropertyAdd, "add", "", false, "Add the property if it doesn't exist. (requires --set)")
 }
 
 func mavenPropertyCommand(cmd *cobra.Command, args []string) {
@@ -80,7 +85,9 @@ func mavenPropertyCommand(cmd *cobra.Command, args []string) {
 			return
 		}
 
-		if mavenPropertySet {
+		if mavenPropertySet && mavenPropertyAdd {
+			pom.AddProperty(module, args[0], args[1])
+		} else if mavenPropertySet {
 			pom.SetProperty(module, args[0], args[1])
 		} else {
 			logger.Info("%-"+strconv.Itoa(int(maxLength))+"s  %s=%v", module.Name, args[0], propertyMap[args[0]])
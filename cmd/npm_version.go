@@ -18,6 +18,11 @@ var npmVersionCmd = &cobra.Command{
 	Short:   "Set package.json version",
 	Long: `
 Sets version in all package.json files.
+
+The package.json files of a module can be configured with the
+"package_json_files" field of the module in the manifest. The default
+is the "package.json" file in the module directory. Server modules
+default to the package.json files of the web interface.
 `,
 	Run: npmVersionCommand,
 }
@@ -46,12 +51,32 @@ func npmVersionCommand(cmd *cobra.Command, args []string) {
 	project := p.New(config.Get(), manifestFiles)
 	applier := apply.NewExecuteApplier([]string{})
 
+	validatePackageJsonFiles(project)
+
 	apply.ForEachModule(project, true, func(module p.Module) {
 		utils.InDirectory(module.Path, func() {
-			applier.NpmVersionSet(module, version)
+			if err := applier.NpmVersionSet(module, version); err != nil {
+				logger.Fatal("ERROR: %s", err)
+			}
 			if shouldCommit {
-				applier.NpmVersionCommit(module, version)
+				if err := applier.NpmVersionCommit(module, version); err != nil {
+					logger.Fatal("ERROR: %s", err)
+				}
 			}
 		})
 	})
 }
+
+// Checks the package.json files of all modules before any file gets modified.
+func validatePackageJsonFiles(project p.Project) {
+	errors := 0
+	apply.ForEachModule(project, true, func(module p.Module) {
+		if err := apply.ValidatePackageJsonFiles(module); err != nil {
+			logger.Error("%s", err)
+			errors++
+		}
+	})
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/Graylog2/graylog-project-cli/changelog"
 	c "github.com/Graylog2/graylog-project-cli/config"
 	"github.com/Graylog2/graylog-project-cli/logger"
 	"github.com/Graylog2/graylog-project-cli/manifest"
@@ -23,21 +24,46 @@ var projectChangelogRenderCmd = &cobra.Command{
 	Short:   "Render changelog snippets.",
 	Long: `Render the changelog snippets for the project.
 
+The --group-by flag controls the sections of the changelog:
+
+  merged   All entries in the same type sections. (default)
+  module   A section for every module. The section title is the
+           "display_name" of the module in the manifest or the module name.
+  product  A section for every product. (the "assemblies" of the modules)
+           Modules without assemblies are listed in the "Other" section.
+
+Entries that reference the same issue or pull request as an entry from
+another repository are only rendered once. The first module in the manifest
+wins. The structured formats (json, yaml, template) don't render sections
+but contain the module of each entry.
+
+With --from and/or --to, the versioned changelog folders of all modules
+are rendered. (see "changelog render --help") The directory argument is the
+changelog directory of the modules in that case. (default: "changelog")
+
 Example:
     graylog-project project-changelog render changelog/unreleased
+    graylog-project project-changelog render --group-by module changelog/unreleased
+    graylog-project project-changelog render --from 5.2.0 --to 6.1.0
 `,
 	Run: projectChangelogRenderCommand,
 }
 
+var projectChangelogGroupBy string
+
 func init() {
 	projectChangelogCmd.AddCommand(projectChangelogRenderCmd)
 
 	applyChangelogRenderFlags(projectChangelogRenderCmd)
+	projectChangelogRenderCmd.Flags().StringVar(&projectChangelogGroupBy, "group-by", changelog.GroupMerged, "How to group the entries. (\"merged\", \"module\", or \"product\")")
 
 	RootCmd.AddCommand(projectChangelogCmd)
 }
 
 func projectChangelogRenderCommand(cmd *cobra.Command, args []string) {
+	if changelogRangeMode() && len(args) == 0 {
+		args = []string{changelogDefaultPath}
+	}
 	if len(args) == 0 {
 		logger.Error("Missing snippet directory")
 		if err := cmd.UsageFunc()(cmd); err != nil {
@@ -58,8 +84,18 @@ func projectChangelogRenderCommand(cmd *cobra.Command, args []string) {
 		logger.Debug("Generating changelog for module: %s", module.Path)
 		return filepath.Join(module.Path, snippetDirectory)
 	})
+	snippetsModules := lo.SliceToMap(modules, func(module p.Module) (string, string) {
+		return filepath.Join(module.Path, snippetDirectory), module.Name
+	})
+
+	groups, err := changelog.NewGroups(projectChangelogGroupBy, modules, func(module p.Module) string {
+		return filepath.Join(module.Path, snippetDirectory)
+	})
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
 
-	if err := execChangelogRenderCommand(snippetsPaths); err != nil {
+	if err := execChangelogRenderCommand(cmd, snippetsPaths, snippetsModules, groups); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
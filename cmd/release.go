@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Graylog2/graylog-project-cli/apply"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/project"
+	"github.com/google/renameio/v2"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Release helper commands",
+}
+
+var releaseVerifyCmd = &cobra.Command{
+	Use:   "verify [flags] <apply-manifest>",
+	Short: "Verify a finished release",
+	Long: `Verify that the release of the given apply manifest landed consistently.
+
+The checks run for every module that isn't skipped for releases. The modules
+must be checked out. The remote branches get fetched before the checks run.
+
+Checks:
+
+  tag                  The release tag exists locally and on the remote and both are identical
+  tag-version          The pom version at the release tag is the release version
+  development-version  The apply.from_revision and apply.new_branch branches on the remote have the apply.new_version version
+  server-version       The plugin parent and graylog.version properties at the release tag are the server release version
+  package-json         The package.json versions match the release version at the tag and the development version in the branches
+  changelog            The changelog folder for the release version exists (not for pre-releases without a new branch)
+
+Examples:
+
+  # Run all checks
+  $ graylog-project release verify manifests/release-2.2.0.json
+
+  # Print the results as JSON
+  $ graylog-project release verify --json manifests/release-2.2.0.json
+
+  # Print the results as table and also write them to a JSON file
+  $ graylog-project release verify --json-file verify.json manifests/release-2.2.0.json
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  releaseVerifyCommand,
+}
+
+func init() {
+	releaseVerifyCmd.Flags().String("remote", apply.DefaultRemote, "Git remote of the release branches and tags")
+	releaseVerifyCmd.Flags().StringSlice("skip-check", []string{}, "Verify checks to skip (comma separated)")
+	releaseVerifyCmd.Flags().Bool("json", false, "Print the check results as JSON")
+	releaseVerifyCmd.Flags().String("json-file", "", "Write the check results as JSON to the given file")
+
+	viper.BindPFlag("release.verify.remote", releaseVerifyCmd.Flags().Lookup("remote"))
+	viper.BindPFlag("release.verify.skip-check", releaseVerifyCmd.Flags().Lookup("skip-check"))
+	viper.BindPFlag("release.verify.json", releaseVerifyCmd.Flags().Lookup("json"))
+	viper.BindPFlag("release.verify.json-file", releaseVerifyCmd.Flags().Lookup("json-file"))
+
+	releaseCmd.AddCommand(releaseVerifyCmd)
+	RootCmd.AddCommand(releaseCmd)
+}
+
+func releaseVerifyCommand(cmd *cobra.Command, args []string) {
+	logger.SetPrefix("[graylog-project]")
+
+	_, _, proj := prepareCheckoutCommand(cmd, args)
+
+	proj.Modules = lo.Filter(proj.Modules, func(item project.Module, index int) bool {
+		return !item.SkipRelease
+	})
+
+	results, err := apply.RunVerify(proj, apply.VerifyOptions{
+		Remote: viper.GetString("release.verify.remote"),
+		Skip:   viper.GetStringSlice("release.verify.skip-check"),
+	})
+	if err != nil {
+		logger.Fatal("ERROR: %s", err)
+	}
+
+	buf, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		logger.Fatal("Couldn't serialize check results: %s", err)
+	}
+
+	if filename := viper.GetString("release.verify.json-file"); filename != "" {
+		if err := renameio.WriteFile(filename, append(buf, '\n'), 0644); err != nil {
+			logger.Fatal("Couldn't write check results to %s: %s", filename, err)
+		}
+	}
+
+	if viper.GetBool("release.verify.json") {
+		fmt.Println(string(buf))
+	} else {
+		printCheckResults(results, "verify")
+	}
+
+	if lo.SomeBy(results, apply.CheckResult.Failed) {
+		os.Exit(1)
+	}
+}
@@ -28,6 +28,8 @@ var loggerPrefix string
 var noUpdateCheck bool
 var forceHttpsRepos bool
 var releaseMode bool
+var affected bool
+var affectedSince string
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -59,6 +61,16 @@ Environment variables:
 - GPC_REPOSITORY_ROOT: can be used instead of the "repository-root" command line flag
 - GPC_RELEASE_MODE: can be used instead of the "release-mode" command line flag
 
+Affected modules:
+
+The --affected flag restricts every command that works on the selected modules
+to modules with commits or uncommitted changes relative to their manifest
+revision (or the --since ref) and all modules that depend on them.
+
+  $ graylog-project --affected exec "mvn verify"
+
+  $ graylog-project --affected --since 6.1.0 status
+
 Example usage:
 
   $ graylog-project checkout manifests/master.json
@@ -103,6 +115,8 @@ func init() {
 	RootCmd.PersistentFlags().BoolVarP(&noUpdateCheck, "disable-update-check", "U", false, "disable checking for graylog-project-cli updates")
 	RootCmd.PersistentFlags().BoolVarP(&forceHttpsRepos, "force-https-repos", "", false, "convert all git@github.com:... repository URLs to https://github.com/...")
 	RootCmd.PersistentFlags().BoolVar(&releaseMode, "release-mode", false, "Enable release mode. Only include modules in the project that can be released.")
+	RootCmd.PersistentFlags().BoolVar(&affected, "affected", false, "only select modules with changes (and modules depending on them) relative to the manifest revision or --since")
+	RootCmd.PersistentFlags().StringVar(&affectedSince, "since", "", "Git ref to detect changes for the --affected flag (default: manifest revision)")
 
 	viper.BindPFlag("repository-root", RootCmd.PersistentFlags().Lookup("repository-root"))
 	viper.BindPFlag("debug", RootCmd.PersistentFlags().Lookup("debug"))
@@ -114,6 +128,8 @@ func init() {
 	viper.BindPFlag("disable-update-check", RootCmd.PersistentFlags().Lookup("disable-update-check"))
 	viper.BindPFlag("force-https-repos", RootCmd.PersistentFlags().Lookup("force-https-repos"))
 	viper.BindPFlag("release-mode", RootCmd.PersistentFlags().Lookup("release-mode"))
+	viper.BindPFlag("affected", RootCmd.PersistentFlags().Lookup("affected"))
+	viper.BindPFlag("since", RootCmd.PersistentFlags().Lookup("since"))
 
 	viper.BindEnv("repository-root", "GPC_REPOSITORY_ROOT")
 	viper.BindEnv("release-mode", "GPC_RELEASE_MODE")
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/config"
+	pexec "github.com/Graylog2/graylog-project-cli/exec"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/manifest"
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/Graylog2/graylog-project-cli/task"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var taskCmd = &cobra.Command{
+	Use:   "task",
+	Short: "Run named project tasks",
+	Long: `Run named tasks that are defined in the graylog-project repository.
+
+Tasks are defined in a YAML file (default: .config/tasks.yml). Every task has a
+command that is executed in each selected module. The command is processed as
+Go template and has access to the same GPC_MODULE_* variables as the "exec"
+command. The "maven" and "npm" variants are used instead of the command for
+Maven and npm modules.
+
+Example task file:
+
+  tasks:
+    lint:
+      description: Run the web linter
+      command: "yarn lint"
+      filter:
+        type: npm
+    versions:
+      description: Show module versions
+      maven: "echo {{ .GPC_MODULE_NAME }} {{ .GPC_MODULE_VERSION }}"
+      npm: "node -p 'require(\"./package.json\").version'"
+      filter:
+        assemblies: ["server", "-datanode"]
+        modules: ["graylog"]
+`,
+}
+
+var taskListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List available tasks",
+	Run:     taskListCommand,
+}
+
+var taskRunCmd = &cobra.Command{
+	Use:     "run [flags] TASK",
+	Short:   "Run the given task",
+	Args:    cobra.ExactArgs(1),
+	Example: "  graylog-project task run lint\n  graylog-project -M enterprise task run --log-dir target/task-logs lint",
+	Run:     taskRunCommand,
+}
+
+func init() {
+	taskCmd.PersistentFlags().String("file", task.DefaultFile, "Task definition file")
+	taskRunCmd.Flags().BoolP("force", "f", false, "Continue to execute the task even when it returns a non-zero code")
+	taskRunCmd.Flags().StringP("log-dir", "l", "", "Write per-module logs and JUnit/JSON reports to the given directory")
+
+	viper.BindPFlag("task.file", taskCmd.PersistentFlags().Lookup("file"))
+	viper.BindPFlag("task.force", taskRunCmd.Flags().Lookup("force"))
+	viper.BindPFlag("task.log-dir", taskRunCmd.Flags().Lookup("log-dir"))
+
+	taskCmd.AddCommand(taskListCmd)
+	taskCmd.AddCommand(taskRunCmd)
+	RootCmd.AddCommand(taskCmd)
+}
+
+func readTaskConfig() *task.Config {
+	taskConfig, err := task.ReadConfig(viper.GetString("task.file"))
+	if err != nil {
+		logger.Fatal("%s", err)
+	}
+	return taskConfig
+}
+
+func taskListCommand(cmd *cobra.Command, args []string) {
+	taskConfig := readTaskConfig()
+
+	maxLength := 0
+	for _, name := range taskConfig.Names() {
+		maxLength = max(maxLength, len(name))
+	}
+
+	for _, name := range taskConfig.Names() {
+		fmt.Printf("%-*s  %s\n", maxLength, name, taskConfig.Tasks[name].Description)
+	}
+}
+
+func taskRunCommand(cmd *cobra.Command, args []string) {
+	t, err := readTaskConfig().Get(args[0])
+	if err != nil {
+		logger.Fatal("%s", err)
+	}
+
+	manifestFiles := manifest.ReadState().Files()
+	project := p.New(config.Get(), manifestFiles)
+
+	force := t.Force || viper.GetBool("task.force")
+	logDir := viper.GetString("task.log-dir")
+	var report *pexec.Report
+	if logDir != "" {
+		logDir = utils.GetAbsolutePath(logDir)
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			logger.Fatal("Couldn't create log directory %s: %v", logDir, err)
+		}
+		report = pexec.NewReport("graylog-project task " + t.Name)
+	}
+
+	logger.Info("Current manifests: %v", manifestFiles)
+	logger.Info("Running task %q", t.Name)
+
+	run := func(module p.Module) {
+		if !t.Matches(module) {
+			logger.Debug("Skipping module %s, task filter doesn't match", module.Name)
+			return
+		}
+		cmdLine := t.CommandFor(module)
+		if strings.TrimSpace(cmdLine) == "" {
+			logger.Debug("Skipping module %s, task has no command for the module type", module.Name)
+			return
+		}
+		inventory := execModuleInventory(module)
+		execCommandLineForPath(module, execRenderTemplate(cmdLine, inventory), inventory, force, logDir, report)
+	}
+
+	if t.IsWeb() {
+		p.ForEachSelectedModuleOrSubmodules(project, run)
+	} else {
+		p.ForEachSelectedModule(project, run)
+	}
+
+	if report != nil {
+		writeExecReport(report, logDir)
+		if report.Failures() > 0 {
+			os.Exit(1)
+		}
+	}
+}
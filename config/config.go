@@ -42,6 +42,8 @@ type Config struct {
 	ForceHttpsRepos    bool          `mapstructure:"force-https-repos"`
 	Update             Update        `mapstructure:"update"`
 	ReleaseMode        bool          `mapstructure:"release-mode"`
+	Affected           bool          `mapstructure:"affected"`
+	AffectedSince      string        `mapstructure:"since"`
 }
 
 // Returns true if running a CI environment. Detected environments: Jenkins, TravisCI
@@ -0,0 +1,228 @@
+package exec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Graylog2/graylog-project-cli/utils"
+)
+
+// Maximum number of bytes of captured output that gets included for a failed module in a report.
+const maxReportOutputBytes = 64 * 1024
+
+var logFilenameSanitizer = regexp.MustCompile(`[^\w.-]+`)
+
+// Matches ANSI escape sequences like the color codes in Maven and yarn output. (e.g., "\x1b[1;31m")
+var ansiEscapePattern = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// ModuleResult contains the outcome of executing a command in a single module.
+type ModuleResult struct {
+	Module   string        `json:"module"`
+	Path     string        `json:"path"`
+	Command  string        `json:"command"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"`
+	ExitCode int           `json:"exit_code"`
+	LogFile  string        `json:"log_file,omitempty"`
+	Output   string        `json:"output,omitempty"`
+}
+
+func (r ModuleResult) Failed() bool {
+	return r.ExitCode != 0
+}
+
+// Report collects the module results of a command execution across modules.
+type Report struct {
+	Name      string         `json:"name"`
+	Timestamp time.Time      `json:"timestamp"`
+	Results   []ModuleResult `json:"results"`
+
+	logFilenames map[string]bool
+}
+
+func NewReport(name string) *Report {
+	return &Report{
+		Name:         name,
+		Timestamp:    time.Now(),
+		Results:      make([]ModuleResult, 0),
+		logFilenames: make(map[string]bool),
+	}
+}
+
+// Add records the given result. The captured output is only kept for failed modules and gets truncated to keep
+// the report files at a reasonable size.
+func (r *Report) Add(result ModuleResult) {
+	if result.Failed() {
+		result.Output = truncateOutput(result.Output)
+	} else {
+		result.Output = ""
+	}
+	r.Results = append(r.Results, result)
+}
+
+func (r *Report) Failures() int {
+	failures := 0
+	for _, result := range r.Results {
+		if result.Failed() {
+			failures++
+		}
+	}
+	return failures
+}
+
+func (r *Report) Duration() time.Duration {
+	var duration time.Duration
+	for _, result := range r.Results {
+		duration += result.Duration
+	}
+	return duration
+}
+
+// LogFilename returns a log file path for the given module name in the given directory. Names that collide with
+// a previously returned path after sanitizing get a numeric suffix. (e.g., "foo/bar" and "foo_bar")
+func (r *Report) LogFilename(directory string, moduleName string) string {
+	basename := logFilenameSanitizer.ReplaceAllString(moduleName, "_")
+	filename := filepath.Join(directory, basename+".log")
+	for i := 2; r.logFilenames[filename]; i++ {
+		filename = filepath.Join(directory, fmt.Sprintf("%s-%d.log", basename, i))
+	}
+	r.logFilenames[filename] = true
+	return filename
+}
+
+func truncateOutput(output string) string {
+	if len(output) <= maxReportOutputBytes {
+		return output
+	}
+	return "[... truncated ...]\n" + utils.TailString(output, maxReportOutputBytes)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Output  string `xml:",cdata"`
+}
+
+// Returns the output without ANSI escape sequences and characters that aren't allowed in XML 1.0 documents. The
+// XML encoder writes CDATA sections unchanged, so JUnit consumers would fail to parse the report otherwise.
+func junitOutput(output string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return r
+		case r < 0x20, r >= 0xD800 && r <= 0xDFFF, r == 0xFFFE, r == 0xFFFF:
+			return -1
+		default:
+			return r
+		}
+	}, ansiEscapePattern.ReplaceAllString(output, ""))
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// MarshalJUnit returns the report as JUnit XML with one test case per module.
+func (r *Report) MarshalJUnit() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      r.Name,
+		Tests:     len(r.Results),
+		Failures:  r.Failures(),
+		Time:      junitSeconds(r.Duration()),
+		Timestamp: r.Timestamp.Format("2006-01-02T15:04:05"),
+		TestCases: make([]junitTestCase, 0, len(r.Results)),
+	}
+
+	for _, result := range r.Results {
+		testCase := junitTestCase{
+			Name:      result.Module,
+			ClassName: r.Name,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: fmt.Sprintf("command: %s\npath: %s\nexit code: %d", result.Command, result.Path, result.ExitCode),
+		}
+		if result.Failed() {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("command %q failed with exit code %d", result.Command, result.ExitCode),
+				Type:    "ExitCode",
+				Output:  junitOutput(result.Output),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Name:     r.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	buf, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't serialize JUnit report: %w", err)
+	}
+
+	return append([]byte(xml.Header), buf...), nil
+}
+
+// WriteFiles writes the JUnit XML and the JSON report into the given directory.
+func (r *Report) WriteFiles(directory string, basename string) error {
+	junitBuf, err := r.MarshalJUnit()
+	if err != nil {
+		return err
+	}
+	jsonBuf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't serialize JSON report: %w", err)
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return fmt.Errorf("couldn't create report directory %s: %w", directory, err)
+	}
+
+	junitFile := filepath.Join(directory, basename+".xml")
+	if err := os.WriteFile(junitFile, junitBuf, 0644); err != nil {
+		return fmt.Errorf("couldn't write JUnit report %s: %w", junitFile, err)
+	}
+	jsonFile := filepath.Join(directory, basename+".json")
+	if err := os.WriteFile(jsonFile, append(jsonBuf, '\n'), 0644); err != nil {
+		return fmt.Errorf("couldn't write JSON report %s: %w", jsonFile, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package exec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	report := NewReport("exec")
+	report.Add(ModuleResult{Module: "graylog2-server", Command: "make", Duration: 2 * time.Second, Output: "ok"})
+	report.Add(ModuleResult{Module: "graylog-plugin-enterprise", Command: "make", Duration: time.Second, ExitCode: 2, Output: "boom"})
+
+	assert.Equal(t, 1, report.Failures())
+	assert.Equal(t, 3*time.Second, report.Duration())
+	assert.Empty(t, report.Results[0].Output, "output should only be kept for failures")
+	assert.Equal(t, "boom", report.Results[1].Output)
+
+	buf, err := report.MarshalJUnit()
+	require.NoError(t, err)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf, &suites))
+	require.Len(t, suites.Suites, 1)
+	assert.Equal(t, 2, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, "3.000", suites.Time)
+
+	cases := suites.Suites[0].TestCases
+	require.Len(t, cases, 2)
+	assert.Equal(t, "graylog2-server", cases[0].Name)
+	assert.Nil(t, cases[0].Failure)
+	assert.Equal(t, "graylog-plugin-enterprise", cases[1].Name)
+	require.NotNil(t, cases[1].Failure)
+	assert.Contains(t, cases[1].Failure.Message, "exit code 2")
+	assert.Equal(t, "boom", cases[1].Failure.Output)
+}
+
+func TestReportJUnitColoredOutput(t *testing.T) {
+	report := NewReport("exec")
+	report.Add(ModuleResult{
+		Module:   "graylog2-server",
+		Command:  "mvn package",
+		ExitCode: 1,
+		Output:   "\x1b[1;31m[ERROR]\x1b[m BUILD FAILURE\n\x1b]0;title\x07\x1b[?25lprogress\b\x00\tdone ]]>\n",
+	})
+
+	buf, err := report.MarshalJUnit()
+	require.NoError(t, err)
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf, &suites))
+	require.Len(t, suites.Suites, 1)
+	require.Len(t, suites.Suites[0].TestCases, 1)
+	require.NotNil(t, suites.Suites[0].TestCases[0].Failure)
+	assert.Equal(t, "[ERROR] BUILD FAILURE\nprogress\tdone ]]>\n", suites.Suites[0].TestCases[0].Failure.Output)
+	// The JSON report keeps the original output
+	assert.Contains(t, report.Results[0].Output, "\x1b[1;31m")
+}
+
+func TestReportTruncatesOutput(t *testing.T) {
+	report := NewReport("exec")
+	report.Add(ModuleResult{Module: "a", ExitCode: 1, Output: strings.Repeat("x", maxReportOutputBytes+100)})
+
+	assert.True(t, strings.HasPrefix(report.Results[0].Output, "[... truncated ...]\n"))
+	assert.Len(t, report.Results[0].Output, maxReportOutputBytes+len("[... truncated ...]\n"))
+}
+
+func TestReportWriteFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+
+	report := NewReport("exec")
+	report.Add(ModuleResult{Module: "a", Command: "true"})
+	require.NoError(t, report.WriteFiles(dir, "exec-report"))
+
+	assert.FileExists(t, filepath.Join(dir, "exec-report.xml"))
+
+	buf, err := os.ReadFile(filepath.Join(dir, "exec-report.json"))
+	require.NoError(t, err)
+
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	require.Len(t, decoded.Results, 1)
+	assert.Equal(t, "a", decoded.Results[0].Module)
+}
+
+func TestReportTruncatesOutputOnRuneBoundary(t *testing.T) {
+	report := NewReport("exec")
+	report.Add(ModuleResult{Module: "a", ExitCode: 1, Output: "x" + strings.Repeat("ü", maxReportOutputBytes/2)})
+
+	output := strings.TrimPrefix(report.Results[0].Output, "[... truncated ...]\n")
+	assert.True(t, utf8.ValidString(output))
+	assert.Len(t, output, maxReportOutputBytes)
+}
+
+func TestLogFilename(t *testing.T) {
+	report := NewReport("exec")
+	assert.Equal(t, filepath.Join("logs", "graylog2-server.log"), report.LogFilename("logs", "graylog2-server"))
+	assert.Equal(t, filepath.Join("logs", "foo_bar.log"), report.LogFilename("logs", "foo/bar"))
+	assert.Equal(t, filepath.Join("logs", "foo_bar-2.log"), report.LogFilename("logs", "foo_bar"))
+	assert.Equal(t, filepath.Join("logs", "foo_bar-3.log"), report.LogFilename("logs", "foo/bar"))
+}
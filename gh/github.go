@@ -3,6 +3,7 @@ package gh
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"slices"
 	"strings"
 
@@ -92,6 +93,26 @@ func NewGitHubClient(accessToken string) *Client {
 	}
 }
 
+// NewGitHubClientWithBaseURL returns a client for the given API base URL. (e.g., "https://ghe.example.com/api/v3/")
+// The default GitHub API is used if the base URL is empty.
+func NewGitHubClientWithBaseURL(accessToken string, baseURL string) (*Client, error) {
+	client := NewGitHubClient(accessToken)
+	if baseURL == "" {
+		return client, nil
+	}
+
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API base URL %q: %w", baseURL, err)
+	}
+	if !strings.HasSuffix(parsedURL.Path, "/") {
+		parsedURL.Path += "/"
+	}
+	client.client.BaseURL = parsedURL
+
+	return client, nil
+}
+
 func SplitRepoString(repository string) (string, string, error) {
 	tokens := strings.Split(repository, "/")
 
@@ -0,0 +1,49 @@
+package gh
+
+import (
+	"fmt"
+
+	"github.com/google/go-github/v76/github"
+	"github.com/samber/lo"
+)
+
+type Issue struct {
+	Owner  string
+	Repo   string
+	Number int
+	Title  string
+	// True if the issue is a pull request.
+	PullRequest bool
+	// The issue state. ("open" or "closed")
+	State string
+	// True if the issue is a merged pull request.
+	Merged bool
+	Labels []string
+	URL    string
+}
+
+// GetIssue returns the issue or pull request with the given number. It returns nil without an error if the issue
+// doesn't exist.
+func (gh *Client) GetIssue(owner string, repo string, number int) (*Issue, error) {
+	issue, _, err := gh.client.Issues.Get(gh.ctx, owner, repo, number)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("couldn't get issue %s/%s#%d: %w", owner, repo, number, err)
+	}
+
+	return &Issue{
+		Owner:       owner,
+		Repo:        repo,
+		Number:      issue.GetNumber(),
+		Title:       issue.GetTitle(),
+		PullRequest: issue.IsPullRequest(),
+		State:       issue.GetState(),
+		Merged:      !issue.GetPullRequestLinks().GetMergedAt().IsZero(),
+		Labels: lo.Map(issue.Labels, func(label *github.Label, _ int) string {
+			return label.GetName()
+		}),
+		URL: issue.GetHTMLURL(),
+	}, nil
+}
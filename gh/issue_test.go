@@ -0,0 +1,48 @@
+package gh
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssue(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/issues/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 1, "title": "Fix it", "state": "closed", "html_url": "https://github.com/Graylog2/graylog2-server/pull/1",
+			"labels": [{"name": "bug"}], "pull_request": {"url": "x", "merged_at": "2024-05-02T10:00:00Z"}}`))
+	})
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/issues/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 2, "title": "Broken", "state": "open", "labels": []}`))
+	})
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/issues/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	client := newTestClient(t, mux)
+
+	issue, err := client.GetIssue("Graylog2", "graylog2-server", 1)
+	require.NoError(t, err)
+	assert.Equal(t, &Issue{
+		Owner:       "Graylog2",
+		Repo:        "graylog2-server",
+		Number:      1,
+		Title:       "Fix it",
+		PullRequest: true,
+		State:       "closed",
+		Merged:      true,
+		Labels:      []string{"bug"},
+		URL:         "https://github.com/Graylog2/graylog2-server/pull/1",
+	}, issue)
+
+	issue, err = client.GetIssue("Graylog2", "graylog2-server", 2)
+	require.NoError(t, err)
+	assert.False(t, issue.PullRequest)
+	assert.False(t, issue.Merged)
+
+	issue, err = client.GetIssue("Graylog2", "graylog2-server", 3)
+	require.NoError(t, err)
+	assert.Nil(t, issue)
+}
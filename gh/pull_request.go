@@ -0,0 +1,146 @@
+package gh
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/google/go-github/v76/github"
+	"github.com/samber/lo"
+)
+
+// Matches the GitHub closing keywords for linked issues. (e.g., "Fixes #123" or "Closes Graylog2/graylog2-server#123")
+var linkedIssuePattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+((?:[\w.-]+/[\w.-]+)?#\d+|https://github\.com/[\w.-]+/[\w.-]+/issues/\d+)\b`)
+
+// The GitHub API only exposes the closing issue references of a pull request in the GraphQL API.
+const closingIssuesQuery = `query($owner: String!, $repo: String!, $number: Int!) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      closingIssuesReferences(first: 50) {
+        nodes { number repository { nameWithOwner } }
+      }
+    }
+  }
+}`
+
+type closingIssue struct {
+	Number     int
+	Repository struct {
+		NameWithOwner string
+	}
+}
+
+type graphQLError struct {
+	Message string
+}
+
+type closingIssuesResponse struct {
+	Data struct {
+		Repository struct {
+			PullRequest struct {
+				ClosingIssuesReferences struct {
+					Nodes []closingIssue
+				}
+			}
+		}
+	}
+	Errors []graphQLError
+}
+
+type PullRequest struct {
+	Owner  string
+	Repo   string
+	Number int
+	Title  string
+	Body   string
+	// The login of the pull request author.
+	Author string
+	// The author's relationship to the repository. (e.g., "MEMBER" or "CONTRIBUTOR")
+	AuthorAssociation string
+	Labels            []string
+	URL               string
+	// The issues that get closed by the pull request according to GitHub. (e.g., "Graylog2/graylog2-server#123")
+	ClosingIssues []string
+}
+
+// LinkedIssues returns the issues that get closed by the pull request. These are the closing issues reported by
+// GitHub. Without those, (e.g., pull requests for a non-default branch) the closing keywords in the body are used and
+// the references are returned as written. (e.g., "#123", "Graylog2/graylog2-server#123", or an issue URL)
+func (pr PullRequest) LinkedIssues() []string {
+	if len(pr.ClosingIssues) > 0 {
+		return pr.ClosingIssues
+	}
+	return lo.Uniq(lo.Map(linkedIssuePattern.FindAllStringSubmatch(pr.Body, -1), func(match []string, _ int) string {
+		return match[1]
+	}))
+}
+
+func (gh *Client) GetPullRequest(owner string, repo string, number int) (*PullRequest, error) {
+	pr, _, err := gh.client.PullRequests.Get(gh.ctx, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get pull request %s/%s#%d: %w", owner, repo, number, err)
+	}
+
+	closingIssues, err := gh.getClosingIssues(owner, repo, number)
+	if err != nil {
+		// The linked issues fall back to the closing keywords in the body
+		logger.Debug("Couldn't get closing issues: %s", err)
+	}
+
+	return &PullRequest{
+		Owner:             owner,
+		Repo:              repo,
+		Number:            pr.GetNumber(),
+		Title:             pr.GetTitle(),
+		Body:              pr.GetBody(),
+		Author:            pr.GetUser().GetLogin(),
+		AuthorAssociation: pr.GetAuthorAssociation(),
+		Labels: lo.Map(pr.Labels, func(label *github.Label, _ int) string {
+			return label.GetName()
+		}),
+		URL:           pr.GetHTMLURL(),
+		ClosingIssues: closingIssues,
+	}, nil
+}
+
+// Returns the closing issue references of the pull request.
+func (gh *Client) getClosingIssues(owner string, repo string, number int) ([]string, error) {
+	body := map[string]any{
+		"query":     closingIssuesQuery,
+		"variables": map[string]any{"owner": owner, "repo": repo, "number": number},
+	}
+	// The GraphQL endpoint is next to the REST API base URL. (e.g., "/graphql" or "/api/graphql" for "/api/v3/")
+	req, err := gh.client.NewRequest("POST", "../graphql", body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create closing issues request for %s/%s#%d: %w", owner, repo, number, err)
+	}
+
+	var response closingIssuesResponse
+	if _, err := gh.client.Do(gh.ctx, req, &response); err != nil {
+		return nil, fmt.Errorf("couldn't get closing issues for %s/%s#%d: %w", owner, repo, number, err)
+	}
+	if len(response.Errors) > 0 {
+		messages := lo.Map(response.Errors, func(e graphQLError, _ int) string {
+			return e.Message
+		})
+		return nil, fmt.Errorf("couldn't get closing issues for %s/%s#%d: %s", owner, repo, number, strings.Join(messages, ", "))
+	}
+
+	return lo.Map(response.Data.Repository.PullRequest.ClosingIssuesReferences.Nodes, func(issue closingIssue, _ int) string {
+		return fmt.Sprintf("%s#%d", issue.Repository.NameWithOwner, issue.Number)
+	}), nil
+}
+
+// IsOrgMember returns true if the user is a member of the given organization. Users without an organization (and
+// private members for tokens without access to the organization members) aren't members.
+func (gh *Client) IsOrgMember(org string, user string) (bool, error) {
+	member, _, err := gh.client.Organizations.IsMember(gh.ctx, org, user)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("couldn't check membership of %s in %s: %w", user, org, err)
+	}
+	return member, nil
+}
@@ -0,0 +1,58 @@
+package gh
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPullRequestLinkedIssues(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": ` + r.PathValue("number") + `, "title": "Fix it", "body": "Fixes #10"}`))
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables struct {
+				Owner  string
+				Repo   string
+				Number int
+			}
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "Graylog2", request.Variables.Owner)
+		assert.Equal(t, "graylog2-server", request.Variables.Repo)
+
+		switch request.Variables.Number {
+		case 1:
+			w.Write([]byte(`{"data": {"repository": {"pullRequest": {"closingIssuesReferences": {"nodes": [
+				{"number": 11, "repository": {"nameWithOwner": "Graylog2/graylog2-server"}},
+				{"number": 12, "repository": {"nameWithOwner": "Graylog2/graylog-plugin-enterprise"}}
+			]}}}}}`))
+		case 2:
+			w.Write([]byte(`{"data": {"repository": {"pullRequest": {"closingIssuesReferences": {"nodes": []}}}}}`))
+		default:
+			w.Write([]byte(`{"data": null, "errors": [{"message": "Something went wrong"}]}`))
+		}
+	})
+	client := newTestClient(t, mux)
+
+	// The closing issues reported by GitHub take precedence over the body
+	pr, err := client.GetPullRequest("Graylog2", "graylog2-server", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Graylog2/graylog2-server#11", "Graylog2/graylog-plugin-enterprise#12"}, pr.LinkedIssues())
+
+	// The body is used without closing issues
+	pr, err = client.GetPullRequest("Graylog2", "graylog2-server", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#10"}, pr.LinkedIssues())
+
+	// The body is used if the closing issues query fails
+	pr, err = client.GetPullRequest("Graylog2", "graylog2-server", 3)
+	require.NoError(t, err)
+	assert.Empty(t, pr.ClosingIssues)
+	assert.Equal(t, []string{"#10"}, pr.LinkedIssues())
+}
@@ -0,0 +1,77 @@
+package gh
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v76/github"
+)
+
+type Release struct {
+	ID         int64
+	Owner      string
+	Repo       string
+	Tag        string
+	Name       string
+	Draft      bool
+	Prerelease bool
+	URL        string
+	// True if the release has been created, false if an existing release has been updated.
+	Created bool
+}
+
+type ReleaseOptions struct {
+	Tag        string
+	Name       string
+	Body       string
+	Draft      bool
+	Prerelease bool
+}
+
+// CreateOrUpdateRelease creates a release for the tag in the options. An existing release for the tag gets updated.
+// The tag must already exist in the repository.
+func (gh *Client) CreateOrUpdateRelease(owner string, repo string, options ReleaseOptions) (*Release, error) {
+	release := &github.RepositoryRelease{
+		TagName:    github.Ptr(options.Tag),
+		Name:       github.Ptr(options.Name),
+		Body:       github.Ptr(options.Body),
+		Draft:      github.Ptr(options.Draft),
+		Prerelease: github.Ptr(options.Prerelease),
+	}
+
+	existing, _, err := gh.client.Repositories.GetReleaseByTag(gh.ctx, owner, repo, options.Tag)
+	if err != nil && !isNotFound(err) {
+		return nil, fmt.Errorf("couldn't get release for tag %s in %s/%s: %w", options.Tag, owner, repo, err)
+	}
+
+	created := existing == nil
+	if created {
+		release, _, err = gh.client.Repositories.CreateRelease(gh.ctx, owner, repo, release)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create release for tag %s in %s/%s: %w", options.Tag, owner, repo, err)
+		}
+	} else {
+		release, _, err = gh.client.Repositories.EditRelease(gh.ctx, owner, repo, existing.GetID(), release)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't update release for tag %s in %s/%s: %w", options.Tag, owner, repo, err)
+		}
+	}
+
+	return &Release{
+		ID:         release.GetID(),
+		Owner:      owner,
+		Repo:       repo,
+		Tag:        release.GetTagName(),
+		Name:       release.GetName(),
+		Draft:      release.GetDraft(),
+		Prerelease: release.GetPrerelease(),
+		URL:        release.GetHTMLURL(),
+		Created:    created,
+	}, nil
+}
+
+func isNotFound(err error) bool {
+	var errorResponse *github.ErrorResponse
+	return errors.As(err, &errorResponse) && errorResponse.Response != nil && errorResponse.Response.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,94 @@
+package gh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v76/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	return &Client{client: client, ctx: context.Background()}
+}
+
+func TestCreateOrUpdateReleaseCreates(t *testing.T) {
+	var created github.RepositoryRelease
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/releases/tags/6.2.0-rc.1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	mux.HandleFunc("POST /repos/Graylog2/graylog2-server/releases", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		created.ID = github.Ptr(int64(42))
+		created.HTMLURL = github.Ptr("https://github.com/Graylog2/graylog2-server/releases/tag/6.2.0-rc.1")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+	})
+
+	release, err := newTestClient(t, mux).CreateOrUpdateRelease("Graylog2", "graylog2-server", ReleaseOptions{
+		Tag:        "6.2.0-rc.1",
+		Name:       "6.2.0-rc.1",
+		Body:       "## Changes",
+		Prerelease: true,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, release.Created)
+	assert.Equal(t, int64(42), release.ID)
+	assert.True(t, release.Prerelease)
+	assert.False(t, release.Draft)
+	assert.Equal(t, "https://github.com/Graylog2/graylog2-server/releases/tag/6.2.0-rc.1", release.URL)
+	assert.Equal(t, "## Changes", created.GetBody())
+}
+
+func TestCreateOrUpdateReleaseUpdates(t *testing.T) {
+	var updated github.RepositoryRelease
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/releases/tags/6.2.0", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(github.RepositoryRelease{ID: github.Ptr(int64(7)), TagName: github.Ptr("6.2.0")})
+	})
+	mux.HandleFunc("PATCH /repos/Graylog2/graylog2-server/releases/7", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+		updated.ID = github.Ptr(int64(7))
+		json.NewEncoder(w).Encode(updated)
+	})
+
+	release, err := newTestClient(t, mux).CreateOrUpdateRelease("Graylog2", "graylog2-server", ReleaseOptions{
+		Tag:  "6.2.0",
+		Name: "6.2.0",
+		Body: "updated",
+	})
+	require.NoError(t, err)
+
+	assert.False(t, release.Created)
+	assert.Equal(t, int64(7), release.ID)
+	assert.Equal(t, "updated", updated.GetBody())
+}
+
+func TestCreateOrUpdateReleaseError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/Graylog2/graylog2-server/releases/tags/6.2.0", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+	})
+
+	_, err := newTestClient(t, mux).CreateOrUpdateRelease("Graylog2", "graylog2-server", ReleaseOptions{Tag: "6.2.0"})
+	assert.ErrorContains(t, err, "couldn't get release for tag 6.2.0 in Graylog2/graylog2-server")
+}
@@ -105,6 +105,11 @@ func ToplevelPath() (string, error) {
 	return path, nil
 }
 
+// ToplevelPathOf returns the root directory of the repository that contains the given directory.
+func ToplevelPathOf(path string) (string, error) {
+	return GitValueE("-C", path, "rev-parse", "--show-toplevel")
+}
+
 func ExecInPath(path string, commands ...string) error {
 	return utils.InDirectoryE(path, func() error {
 		return Exec(commands...)
@@ -112,6 +117,25 @@ func ExecInPath(path string, commands ...string) error {
 }
 
 func Exec(commands ...string) error {
+	_, err := ExecOutput(commands...)
+	return err
+}
+
+// ExecInPathOutput is like ExecInPath but also returns the combined stdout and stderr output of the command.
+func ExecInPathOutput(path string, commands ...string) (string, error) {
+	var output string
+
+	err := utils.InDirectoryE(path, func() error {
+		var err error
+		output, err = ExecOutput(commands...)
+		return err
+	})
+
+	return output, err
+}
+
+// ExecOutput is like Exec but also returns the combined stdout and stderr output of the command.
+func ExecOutput(commands ...string) (string, error) {
 	var output bytes.Buffer
 
 	logger.ColorInfo(color.FgGreen, "    git %v", strings.Join(commands, " "))
@@ -121,7 +145,7 @@ func Exec(commands ...string) error {
 
 	if err := command.Run(); err != nil {
 		logOutputBufferWithColor(output.Bytes(), color.FgRed)
-		return logger.NewLoggableError(
+		return output.String(), logger.NewLoggableError(
 			err,
 			fmt.Sprintf(
 				`couldn't execute "%s" in "%s"`,
@@ -134,7 +158,7 @@ func Exec(commands ...string) error {
 
 	logOutputBuffer(output.Bytes())
 
-	return nil
+	return output.String(), nil
 }
 
 func logOutputBuffer(buf []byte) {
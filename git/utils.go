@@ -3,6 +3,12 @@ package git
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/utils"
 )
 
 func GetRemoteUrl(path string, remote string) (string, error) {
@@ -23,3 +29,227 @@ func GetRemoteUrl(path string, remote string) (string, error) {
 
 	return urlString, nil
 }
+
+// ResolveRef returns the commit ID for the first of the given refs that exists in the repository at the given path.
+func ResolveRef(path string, refs ...string) (string, string, error) {
+	var commit, resolvedRef string
+
+	err := utils.InDirectoryE(path, func() error {
+		for _, ref := range refs {
+			if ref == "" {
+				continue
+			}
+			value, err := GitValueE("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+			if err == nil && value != "" {
+				commit, resolvedRef = value, ref
+				return nil
+			}
+		}
+		return fmt.Errorf("couldn't resolve any of the refs %v in %s", refs, path)
+	})
+
+	return commit, resolvedRef, err
+}
+
+// CommitsSince returns the number of commits in HEAD that are not reachable from the given ref.
+func CommitsSince(path string, ref string) (int, error) {
+	var count int
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("rev-list", "--count", ref+"..HEAD")
+		if err != nil {
+			return fmt.Errorf("couldn't count commits since %s in %s: %w", ref, path, err)
+		}
+		count, err = strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("couldn't parse commit count %q: %w", value, err)
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// HasUncommittedChanges returns true if the repository at the given path has modified, staged or untracked files.
+func HasUncommittedChanges(path string) (bool, error) {
+	var changes bool
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("status", "--porcelain")
+		if err != nil {
+			return fmt.Errorf("couldn't get status in %s: %w", path, err)
+		}
+		changes = value != ""
+		return nil
+	})
+
+	return changes, err
+}
+
+// RemoteRef returns the commit ID of the given ref on the remote or an empty string if the ref doesn't exist there.
+func RemoteRef(path string, remote string, ref string) (string, error) {
+	var commit string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("ls-remote", remote, ref)
+		if err != nil {
+			return fmt.Errorf("couldn't list remote ref %s in %s: %w", ref, path, err)
+		}
+		for _, line := range strings.Split(value, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[1] == ref {
+				commit = fields[0]
+			}
+		}
+		return nil
+	})
+
+	return commit, err
+}
+
+// RemoteTags returns the names of the tags on the remote that match the given glob pattern.
+func RemoteTags(path string, remote string, pattern string) ([]string, error) {
+	var tags []string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("ls-remote", "--tags", "--refs", remote)
+		if err != nil {
+			return fmt.Errorf("couldn't list remote tags of %s in %s: %w", remote, path, err)
+		}
+		for _, line := range strings.Split(value, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			name := strings.TrimPrefix(fields[1], "refs/tags/")
+			if matched, err := filepath.Match(pattern, name); err != nil {
+				return fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+			} else if matched {
+				tags = append(tags, name)
+			}
+		}
+		return nil
+	})
+
+	return tags, err
+}
+
+// CurrentBranch returns the name of the checked out branch in the repository at the given path.
+func CurrentBranch(path string) (string, error) {
+	var branch string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("symbolic-ref", "--short", "HEAD")
+		if err != nil {
+			return fmt.Errorf("couldn't get current branch in %s (detached HEAD?): %w", path, err)
+		}
+		branch = value
+		return nil
+	})
+
+	return branch, err
+}
+
+// TagDate returns the date (YYYY-MM-DD) of the given tag in the repository at the given path or an empty string if
+// the tag doesn't exist. Annotated tags use the tagger date, lightweight tags the commit date.
+func TagDate(path string, tag string) (string, error) {
+	var date string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("for-each-ref", "--format=%(creatordate:short)", "refs/tags/"+tag)
+		if err != nil {
+			return fmt.Errorf("couldn't get date of tag %s in %s: %w", tag, path, err)
+		}
+		date = value
+		return nil
+	})
+
+	return date, err
+}
+
+// ChangedFiles returns the files that changed between the merge base of the given base ref and HEAD in the
+// repository at the given path. The file names are relative to the repository root. The optional filter uses the
+// "git diff --diff-filter" syntax. (e.g., "AM" for added and modified files)
+func ChangedFiles(path string, base string, filter string) ([]string, error) {
+	var files []string
+
+	err := utils.InDirectoryE(path, func() error {
+		args := []string{"diff", "--name-only", "--no-renames"}
+		if filter != "" {
+			args = append(args, "--diff-filter="+filter)
+		}
+		value, err := GitValueE(append(args, base+"...HEAD")...)
+		if err != nil {
+			return fmt.Errorf("couldn't get changed files since %s in %s: %w", base, path, err)
+		}
+		if value != "" {
+			files = strings.Split(value, "\n")
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// TrailerValues returns the values of the given commit trailer (e.g., "Changelog") in the commits of HEAD that are
+// not reachable from the given base ref.
+func TrailerValues(path string, base string, key string) ([]string, error) {
+	var values []string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("log", "--format=%(trailers:key="+key+",valueonly,separator=%x0A)", base+"..HEAD")
+		if err != nil {
+			return fmt.Errorf("couldn't get %s trailers since %s in %s: %w", key, base, path, err)
+		}
+		for _, line := range strings.Split(value, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				values = append(values, line)
+			}
+		}
+		return nil
+	})
+
+	return values, err
+}
+
+// Tags returns the tags matching the given pattern in the repository at the given path.
+func Tags(path string, pattern string) ([]string, error) {
+	var tags []string
+
+	err := utils.InDirectoryE(path, func() error {
+		value, err := GitValueE("tag", "--list", pattern)
+		if err != nil {
+			return fmt.Errorf("couldn't list tags in %s: %w", path, err)
+		}
+		if value != "" {
+			tags = strings.Split(value, "\n")
+		}
+		return nil
+	})
+
+	return tags, err
+}
+
+// ShowFile returns the content of the given file at the given ref in the repository at the given path.
+func ShowFile(path string, ref string, file string) ([]byte, error) {
+	var content []byte
+
+	err := utils.InDirectoryE(path, func() error {
+		out, err := exec.Command("git", "show", ref+":"+file).Output()
+		if err != nil {
+			return fmt.Errorf("couldn't read %s at %s in %s: %w", file, ref, path, err)
+		}
+		content = out
+		return nil
+	})
+
+	return content, err
+}
+
+// PathExists returns true if the given file or directory exists at the given ref in the repository at the given path.
+func PathExists(path string, ref string, file string) bool {
+	err := utils.InDirectoryE(path, func() error {
+		return exec.Command("git", "cat-file", "-e", ref+":"+file).Run()
+	})
+	return err == nil
+}
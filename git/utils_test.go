@@ -1,6 +1,9 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -23,3 +26,162 @@ func TestGetRemoteUrl(t *testing.T) {
 		assert.Equal(t, "git@github.com:test/test.git", url)
 	})
 }
+
+func TestChangeDetection(t *testing.T) {
+	repo := t.TempDir()
+
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"))
+
+	commit, ref, err := ResolveRef(repo, "origin/main", "main")
+	require.Nil(t, err)
+	assert.Equal(t, "main", ref)
+	assert.NotEmpty(t, commit)
+
+	_, _, err = ResolveRef(repo, "origin/main", "nope")
+	assert.NotNil(t, err)
+
+	count, err := CommitsSince(repo, "main")
+	require.Nil(t, err)
+	assert.Equal(t, 0, count)
+
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "second"))
+	count, err = CommitsSince(repo, commit)
+	require.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	changes, err := HasUncommittedChanges(repo)
+	require.Nil(t, err)
+	assert.False(t, changes)
+
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "file.txt"), []byte("hello"), 0644))
+	changes, err = HasUncommittedChanges(repo)
+	require.Nil(t, err)
+	assert.True(t, changes)
+}
+
+func TestRemoteRef(t *testing.T) {
+	remote := t.TempDir()
+	repo := t.TempDir()
+
+	require.Nil(t, Exec("init", "--bare", "--initial-branch=main", remote))
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"))
+	require.Nil(t, ExecInPath(repo, "remote", "add", "origin", remote))
+	require.Nil(t, ExecInPath(repo, "push", "origin", "main"))
+
+	commit, _, err := ResolveRef(repo, "main")
+	require.Nil(t, err)
+
+	value, err := RemoteRef(repo, "origin", "refs/heads/main")
+	require.Nil(t, err)
+	assert.Equal(t, commit, value)
+
+	value, err = RemoteRef(repo, "origin", "refs/tags/1.0.0")
+	require.Nil(t, err)
+	assert.Empty(t, value)
+}
+
+func TestRemoteTags(t *testing.T) {
+	remote := t.TempDir()
+	repo := t.TempDir()
+
+	require.Nil(t, Exec("init", "--bare", "--initial-branch=main", remote))
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"))
+	require.Nil(t, ExecInPath(repo, "remote", "add", "origin", remote))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "tag", "-a", "-m", "rc.1", "6.2.0-rc.1"))
+	require.Nil(t, ExecInPath(repo, "tag", "6.1.0"))
+	require.Nil(t, ExecInPath(repo, "push", "origin", "main", "--tags"))
+	// Local-only tags aren't returned
+	require.Nil(t, ExecInPath(repo, "tag", "6.2.0-rc.2"))
+
+	tags, err := RemoteTags(repo, "origin", "6.2.0-*")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"6.2.0-rc.1"}, tags)
+
+	tags, err = RemoteTags(repo, "origin", "7.*")
+	require.Nil(t, err)
+	assert.Empty(t, tags)
+}
+
+func TestCurrentBranchAndTags(t *testing.T) {
+	repo := t.TempDir()
+
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"))
+	require.Nil(t, ExecInPath(repo, "tag", "6.2.0-rc.1"))
+	require.Nil(t, ExecInPath(repo, "tag", "6.2.0-rc.2"))
+	require.Nil(t, ExecInPath(repo, "tag", "6.1.0"))
+
+	branch, err := CurrentBranch(repo)
+	require.Nil(t, err)
+	assert.Equal(t, "main", branch)
+
+	tags, err := Tags(repo, "6.2.0-*")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"6.2.0-rc.1", "6.2.0-rc.2"}, tags)
+
+	tags, err = Tags(repo, "7.*")
+	require.Nil(t, err)
+	assert.Empty(t, tags)
+}
+
+func TestTagDate(t *testing.T) {
+	repo := t.TempDir()
+	t.Setenv("GIT_COMMITTER_DATE", "2024-05-02T10:00:00Z")
+
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--allow-empty", "-m", "initial"))
+	require.Nil(t, ExecInPath(repo, "tag", "6.1.0"))
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-03T10:00:00Z")
+	require.Nil(t, ExecInPath(repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "tag", "--annotate", "--message", "6.2.0", "6.2.0"))
+
+	date, err := TagDate(repo, "6.1.0")
+	require.Nil(t, err)
+	assert.Equal(t, "2024-05-02", date)
+
+	date, err = TagDate(repo, "6.2.0")
+	require.Nil(t, err)
+	assert.Equal(t, "2024-06-03", date)
+
+	date, err = TagDate(repo, "7.0.0")
+	require.Nil(t, err)
+	assert.Empty(t, date)
+}
+
+func TestChangedFilesAndTrailerValues(t *testing.T) {
+	repo := t.TempDir()
+	commit := func(args ...string) {
+		require.Nil(t, ExecInPath(repo, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit"}, args...)...))
+	}
+
+	require.Nil(t, Exec("init", "--initial-branch=main", repo))
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "a.txt"), []byte("a"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "b.txt"), []byte("b"), 0644))
+	require.Nil(t, ExecInPath(repo, "add", "-A"))
+	commit("-m", "initial")
+	require.Nil(t, ExecInPath(repo, "checkout", "-b", "feature"))
+
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "a.txt"), []byte("changed"), 0644))
+	require.Nil(t, os.WriteFile(filepath.Join(repo, "c.txt"), []byte("c"), 0644))
+	require.Nil(t, os.Remove(filepath.Join(repo, "b.txt")))
+	require.Nil(t, ExecInPath(repo, "add", "-A"))
+	commit("-m", "change", "--trailer", "Changelog: skip")
+
+	files, err := ChangedFiles(repo, "main", "")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a.txt", "b.txt", "c.txt"}, files)
+
+	files, err = ChangedFiles(repo, "main", "AM")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"a.txt", "c.txt"}, files)
+
+	values, err := TrailerValues(repo, "main", "Changelog")
+	require.Nil(t, err)
+	assert.Equal(t, []string{"skip"}, values)
+
+	values, err = TrailerValues(repo, "main", "Other")
+	require.Nil(t, err)
+	assert.Empty(t, values)
+}
@@ -20,6 +20,10 @@ func (l LoggableError) Cause() error {
 	return l.err
 }
 
+func (l LoggableError) Unwrap() error {
+	return l.err
+}
+
 func (l LoggableError) Error() string {
 	return fmt.Sprintf("%s: %s", l.title, l.err)
 }
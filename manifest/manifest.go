@@ -24,6 +24,7 @@ type Manifest struct {
 
 type ManifestModule struct {
 	Name               string           `json:"name,omitempty"`
+	DisplayName        string           `json:"display_name,omitempty"`
 	Repository         string           `json:"repository,omitempty"`
 	Revision           string           `json:"revision,omitempty"`
 	Maven              string           `json:"maven,omitempty"`
@@ -35,6 +36,9 @@ type ManifestModule struct {
 	SubModules         []ManifestModule `json:"submodules,omitempty"`
 	Apply              ManifestApply    `json:"apply"`
 	SkipRelease        bool             `json:"skip_release,ommitempty"`
+	// The package.json files that contain the module version, relative to the module path. Defaults to the
+	// "package.json" file in the module path and to the web interface files for the server module.
+	PackageJsonFiles []string `json:"package_json_files,omitempty"`
 }
 
 func (mod ManifestModule) HasSubmodules() bool {
@@ -0,0 +1,509 @@
+package pom
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/renameio/v2"
+)
+
+// Document is a pom.xml file that can be modified without changing the formatting of the unmodified parts.
+//
+// The document keeps the original bytes and the byte offsets of all elements. Modifications only replace the
+// affected byte ranges and the offsets get recomputed afterward.
+type Document struct {
+	filename string
+	content  []byte
+	root     *element
+	changed  bool
+}
+
+type element struct {
+	name string
+	// Offset of the "<" of the start tag
+	start int
+	// Offset after the ">" of the start tag
+	contentStart int
+	// Offset of the "<" of the end tag
+	contentEnd int
+	// Offset after the ">" of the end tag
+	end      int
+	parent   *element
+	children []*element
+}
+
+func (e *element) selfClosing() bool {
+	return e.end == e.contentStart
+}
+
+func (e *element) child(name string) *element {
+	for _, child := range e.children {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Returns all elements that match the given path of element names, relative to this element.
+func (e *element) find(path ...string) []*element {
+	if len(path) == 0 {
+		return []*element{e}
+	}
+
+	result := make([]*element, 0)
+	for _, child := range e.children {
+		if child.name == path[0] {
+			result = append(result, child.find(path[1:]...)...)
+		}
+	}
+	return result
+}
+
+// ParseDocument parses the given pom.xml content.
+func ParseDocument(content []byte) (*Document, error) {
+	doc := &Document{content: content}
+	if err := doc.parse(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// LoadDocument reads and parses the given pom.xml file.
+func LoadDocument(filename string) (*Document, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", filename, err)
+	}
+
+	doc, err := ParseDocument(content)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %w", filename, err)
+	}
+	doc.filename = filename
+
+	return doc, nil
+}
+
+func (d *Document) parse() error {
+	decoder := xml.NewDecoder(bytes.NewReader(d.content))
+
+	var root *element
+	var current *element
+
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &element{
+				name:         t.Name.Local,
+				start:        offset,
+				contentStart: int(decoder.InputOffset()),
+				parent:       current,
+			}
+			if current == nil {
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+			current = el
+		case xml.EndElement:
+			current.contentEnd = offset
+			current.end = int(decoder.InputOffset())
+			current = current.parent
+		}
+	}
+
+	if root == nil || root.name != "project" {
+		return fmt.Errorf("missing <project> root element")
+	}
+
+	d.root = root
+	return nil
+}
+
+func (d *Document) Filename() string {
+	return d.filename
+}
+
+// Bytes returns the current content of the document.
+func (d *Document) Bytes() []byte {
+	return d.content
+}
+
+// Changed returns true if the document has been modified.
+func (d *Document) Changed() bool {
+	return d.changed
+}
+
+// Save writes the document back to its file if it has been modified. The file mode is preserved.
+func (d *Document) Save() error {
+	if !d.changed {
+		return nil
+	}
+	if d.filename == "" {
+		return fmt.Errorf("document doesn't have a filename")
+	}
+
+	info, err := os.Stat(d.filename)
+	if err != nil {
+		return fmt.Errorf("couldn't stat %s: %w", d.filename, err)
+	}
+	if err := renameio.WriteFile(d.filename, d.content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("couldn't write %s: %w", d.filename, err)
+	}
+
+	d.changed = false
+	return nil
+}
+
+func (d *Document) text(e *element) string {
+	if e == nil || len(e.children) > 0 {
+		return ""
+	}
+	return html.UnescapeString(strings.TrimSpace(string(d.content[e.contentStart:e.contentEnd])))
+}
+
+func (d *Document) replace(from int, to int, value string) error {
+	content := make([]byte, 0, len(d.content)+len(value))
+	content = append(content, d.content[:from]...)
+	content = append(content, value...)
+	content = append(content, d.content[to:]...)
+
+	d.content = content
+	d.changed = true
+
+	return d.parse()
+}
+
+func escape(value string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// Sets the text content of the given element. Returns false if the value didn't change.
+func (d *Document) setText(e *element, value string) (bool, error) {
+	if len(e.children) > 0 {
+		return false, fmt.Errorf("element <%s> has child elements", e.name)
+	}
+	if d.text(e) == value {
+		return false, nil
+	}
+
+	if e.selfClosing() {
+		startTag := strings.TrimRight(strings.TrimSuffix(string(d.content[e.start:e.end]), "/>"), " \t\r\n")
+		return true, d.replace(e.start, e.end, startTag+">"+escape(value)+"</"+e.name+">")
+	}
+
+	return true, d.replace(e.contentStart, e.contentEnd, escape(value))
+}
+
+// Returns the whitespace indentation of the line that contains the start of the given element.
+func (d *Document) indentOf(e *element) string {
+	lineStart := bytes.LastIndexByte(d.content[:e.start], '\n') + 1
+	indent := d.content[lineStart:e.start]
+	if len(bytes.Trim(indent, " \t")) > 0 {
+		return ""
+	}
+	return string(indent)
+}
+
+// Returns the indentation used for the children of the root element.
+func (d *Document) indentUnit() string {
+	for _, child := range d.root.children {
+		if indent := d.indentOf(child); indent != "" {
+			return indent
+		}
+	}
+	return "    "
+}
+
+// Adds a new child element with the given text value after the last child element of the given parent.
+func (d *Document) appendChild(parent *element, name string, value string) error {
+	newElement := "<" + name + ">" + escape(value) + "</" + name + ">"
+
+	if len(parent.children) > 0 {
+		last := parent.children[len(parent.children)-1]
+		return d.replace(last.end, last.end, "\n"+d.indentOf(last)+newElement)
+	}
+
+	parentIndent := d.indentOf(parent)
+	childIndent := parentIndent + d.indentUnit()
+
+	if parent.selfClosing() {
+		startTag := strings.TrimRight(strings.TrimSuffix(string(d.content[parent.start:parent.end]), "/>"), " \t\r\n")
+		return d.replace(parent.start, parent.end, startTag+">\n"+childIndent+newElement+"\n"+parentIndent+"</"+parent.name+">")
+	}
+
+	// Insert after existing non-whitespace content, e.g., comments.
+	content := d.content[parent.contentStart:parent.contentEnd]
+	insertAt := parent.contentStart + len(bytes.TrimRight(content, " \t\r\n"))
+	suffix := ""
+	if !bytes.Contains(d.content[insertAt:parent.contentEnd], []byte("\n")) {
+		suffix = "\n" + parentIndent
+	}
+
+	return d.replace(insertAt, insertAt, "\n"+childIndent+newElement+suffix)
+}
+
+// GroupId returns the groupId of the project. Falls back to the parent groupId.
+func (d *Document) GroupId() string {
+	if groupId := d.text(d.root.child("groupId")); groupId != "" {
+		return groupId
+	}
+	return d.ParentGroupId()
+}
+
+func (d *Document) ArtifactId() string {
+	return d.text(d.root.child("artifactId"))
+}
+
+// Version returns the version of the project. Falls back to the parent version.
+func (d *Document) Version() string {
+	if version := d.text(d.root.child("version")); version != "" {
+		return version
+	}
+	return d.ParentVersion()
+}
+
+// HasVersion returns true if the project defines its own version instead of inheriting the parent version.
+func (d *Document) HasVersion() bool {
+	return d.root.child("version") != nil
+}
+
+// Modules returns the modules of the project, including the modules of all profiles. Like Maven, the modules
+// of a profile are added after the modules of the project.
+func (d *Document) Modules() []string {
+	modules := make([]string, 0)
+	elements := append(d.root.find("modules", "module"), d.root.find("profiles", "profile", "modules", "module")...)
+	for _, module := range elements {
+		if name := d.text(module); name != "" && !slices.Contains(modules, name) {
+			modules = append(modules, name)
+		}
+	}
+	return modules
+}
+
+func (d *Document) parent() *element {
+	return d.root.child("parent")
+}
+
+func (d *Document) HasParent() bool {
+	return d.parent() != nil
+}
+
+func (d *Document) ParentGroupId() string {
+	if parent := d.parent(); parent != nil {
+		return d.text(parent.child("groupId"))
+	}
+	return ""
+}
+
+func (d *Document) ParentArtifactId() string {
+	if parent := d.parent(); parent != nil {
+		return d.text(parent.child("artifactId"))
+	}
+	return ""
+}
+
+func (d *Document) ParentVersion() string {
+	if parent := d.parent(); parent != nil {
+		return d.text(parent.child("version"))
+	}
+	return ""
+}
+
+// SetVersion sets the project version. Projects that inherit the version from the parent are not modified.
+func (d *Document) SetVersion(version string) (bool, error) {
+	if el := d.root.child("version"); el != nil {
+		return d.setText(el, version)
+	}
+	return false, nil
+}
+
+// SetParentVersion sets the version of the parent.
+func (d *Document) SetParentVersion(version string) (bool, error) {
+	parent := d.parent()
+	if parent == nil {
+		return false, fmt.Errorf("project doesn't have a parent")
+	}
+	return d.setChildText(parent, "version", version)
+}
+
+// SetParent sets the coordinates of the parent. The relativePath is only added if it's not empty because an empty
+// relativePath disables the parent lookup in the file system.
+func (d *Document) SetParent(groupId string, artifactId string, version string, relativePath string) (bool, error) {
+	if d.parent() == nil {
+		return false, fmt.Errorf("project doesn't have a parent")
+	}
+
+	changed := false
+	for _, field := range [][2]string{{"groupId", groupId}, {"artifactId", artifactId}, {"version", version}, {"relativePath", relativePath}} {
+		if field[1] == "" && d.parent().child(field[0]) == nil {
+			continue
+		}
+		fieldChanged, err := d.setChildText(d.parent(), field[0], field[1])
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || fieldChanged
+	}
+
+	return changed, nil
+}
+
+// Sets the text of the named child of the given element. The child is added if it doesn't exist.
+func (d *Document) setChildText(parent *element, name string, value string) (bool, error) {
+	if child := parent.child(name); child != nil {
+		return d.setText(child, value)
+	}
+	return true, d.appendChild(parent, name, value)
+}
+
+// Properties returns the properties of the project. Properties in profiles are ignored.
+func (d *Document) Properties() map[string]string {
+	properties := make(map[string]string)
+	if el := d.root.child("properties"); el != nil {
+		for _, property := range el.children {
+			properties[property.name] = d.text(property)
+		}
+	}
+	return properties
+}
+
+func (d *Document) property(name string) *element {
+	if properties := d.root.child("properties"); properties != nil {
+		return properties.child(name)
+	}
+	return nil
+}
+
+func (d *Document) HasProperty(name string) bool {
+	return d.property(name) != nil
+}
+
+// SetProperty sets the value of the given property. The property (and the <properties> element) is added if it
+// doesn't exist.
+func (d *Document) SetProperty(name string, value string) (bool, error) {
+	if el := d.property(name); el != nil {
+		return d.setText(el, value)
+	}
+
+	if properties := d.root.child("properties"); properties != nil {
+		return true, d.appendChild(properties, name, value)
+	}
+
+	// Add the properties after the project coordinates
+	var anchor *element
+	for _, child := range d.root.children {
+		switch child.name {
+		case "modelVersion", "parent", "groupId", "artifactId", "version", "packaging", "name", "description", "url", "modules":
+			anchor = child
+		}
+	}
+
+	if anchor == nil {
+		if err := d.appendChild(d.root, "properties", ""); err != nil {
+			return true, err
+		}
+		return true, d.appendChild(d.root.child("properties"), name, value)
+	}
+
+	unit := d.indentUnit()
+	block := "<properties>\n" + unit + unit + "<" + name + ">" + escape(value) + "</" + name + ">\n" + unit + "</properties>"
+
+	return true, d.replace(anchor.end, anchor.end, "\n\n"+d.indentOf(anchor)+block)
+}
+
+// Returns all dependency elements of the project, including managed dependencies and dependencies in profiles.
+func (d *Document) dependencies() []*element {
+	result := make([]*element, 0)
+	for _, base := range append([]*element{d.root}, d.root.find("profiles", "profile")...) {
+		result = append(result, base.find("dependencies", "dependency")...)
+		result = append(result, base.find("dependencyManagement", "dependencies", "dependency")...)
+	}
+	return result
+}
+
+// Resolves the project groupId placeholders that are commonly used in dependencies.
+func (d *Document) resolveGroupId(groupId string) string {
+	switch groupId {
+	case "${project.groupId}", "${pom.groupId}":
+		return d.GroupId()
+	case "${project.parent.groupId}":
+		return d.ParentGroupId()
+	}
+	return groupId
+}
+
+// SetDependencyVersion sets the version of all dependencies with the given groupId and artifactId. Dependencies
+// without a version are skipped. If the version is a property reference, the property gets updated instead. The
+// names of referenced properties that are not defined in this document are returned.
+func (d *Document) SetDependencyVersion(groupId string, artifactId string, version string) (bool, []string, error) {
+	return d.setDependencyVersion(groupId, artifactId, "", version)
+}
+
+// Like SetDependencyVersion but if oldVersion is not empty, only dependencies with that literal version are updated.
+func (d *Document) setDependencyVersion(groupId string, artifactId string, oldVersion string, version string) (bool, []string, error) {
+	changed := false
+	missingProperties := make([]string, 0)
+
+	// Each modification invalidates the elements, so we start over after every change.
+	for done := 0; ; done++ {
+		deps := d.dependencies()
+		if done >= len(deps) {
+			break
+		}
+		dep := deps[done]
+
+		if d.resolveGroupId(d.text(dep.child("groupId"))) != groupId || d.text(dep.child("artifactId")) != artifactId {
+			continue
+		}
+		versionElement := dep.child("version")
+		if versionElement == nil {
+			continue
+		}
+
+		current := d.text(versionElement)
+		if oldVersion != "" && current != oldVersion {
+			continue
+		}
+		var depChanged bool
+		var err error
+		if strings.HasPrefix(current, "${") && strings.HasSuffix(current, "}") {
+			property := strings.TrimSuffix(strings.TrimPrefix(current, "${"), "}")
+			if property == "project.version" || property == "project.parent.version" {
+				continue
+			}
+			if !d.HasProperty(property) {
+				missingProperties = append(missingProperties, property)
+				continue
+			}
+			depChanged, err = d.SetProperty(property, version)
+		} else {
+			depChanged, err = d.setText(versionElement, version)
+		}
+		if err != nil {
+			return changed, missingProperties, err
+		}
+		changed = changed || depChanged
+	}
+
+	return changed, missingProperties, nil
+}
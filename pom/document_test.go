@@ -0,0 +1,178 @@
+package pom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPom = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- License header -->
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+
+  <parent>
+    <groupId>org.graylog.plugins</groupId>
+    <artifactId>graylog-plugin-web-parent</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+    <relativePath>../graylog2-server/graylog-plugin-parent/graylog-plugin-web-parent</relativePath>
+  </parent>
+
+  <groupId>org.graylog.plugins</groupId>
+  <artifactId>graylog-plugin-enterprise</artifactId>
+  <version>6.2.0-SNAPSHOT</version>
+
+  <properties>
+    <graylog.version>6.2.0-SNAPSHOT</graylog.version>
+    <!-- Keep in sync -->
+    <forwarder.version>6.2.0-SNAPSHOT</forwarder.version>
+    <empty.value/>
+  </properties>
+
+  <dependencies>
+    <dependency>
+      <groupId>org.graylog2</groupId>
+      <artifactId>graylog2-server</artifactId>
+      <version>${graylog.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>graylog-plugin-forwarder</artifactId>
+      <version>6.2.0-SNAPSHOT</version>
+    </dependency>
+  </dependencies>
+
+  <build>
+    <plugins>
+      <plugin>
+        <artifactId>maven-assembly-plugin</artifactId>
+        <configuration>
+          <parent>do-not-touch</parent>
+        </configuration>
+      </plugin>
+    </plugins>
+  </build>
+</project>
+`
+
+func TestDocumentGetters(t *testing.T) {
+	doc, err := ParseDocument([]byte(testPom))
+	require.NoError(t, err)
+
+	assert.Equal(t, "org.graylog.plugins", doc.GroupId())
+	assert.Equal(t, "graylog-plugin-enterprise", doc.ArtifactId())
+	assert.Equal(t, "6.2.0-SNAPSHOT", doc.Version())
+	assert.Equal(t, "graylog-plugin-web-parent", doc.ParentArtifactId())
+	assert.Equal(t, map[string]string{
+		"graylog.version":   "6.2.0-SNAPSHOT",
+		"forwarder.version": "6.2.0-SNAPSHOT",
+		"empty.value":       "",
+	}, doc.Properties())
+	assert.False(t, doc.Changed())
+}
+
+func TestDocumentSetVersionPreservesFormatting(t *testing.T) {
+	doc, err := ParseDocument([]byte(testPom))
+	require.NoError(t, err)
+
+	changed, err := doc.SetVersion("6.2.0")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = doc.SetParentVersion("6.2.0")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	expected := strings.Replace(testPom, "<version>6.2.0-SNAPSHOT</version>\n    <relativePath>", "<version>6.2.0</version>\n    <relativePath>", 1)
+	expected = strings.Replace(expected, "<artifactId>graylog-plugin-enterprise</artifactId>\n  <version>6.2.0-SNAPSHOT</version>", "<artifactId>graylog-plugin-enterprise</artifactId>\n  <version>6.2.0</version>", 1)
+	assert.Equal(t, expected, string(doc.Bytes()))
+
+	changed, err = doc.SetVersion("6.2.0")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestDocumentSetParent(t *testing.T) {
+	doc, err := ParseDocument([]byte(testPom))
+	require.NoError(t, err)
+
+	_, err = doc.SetParent("org.graylog.plugins", "graylog-plugin-parent", "6.3.0-SNAPSHOT", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "graylog-plugin-parent", doc.ParentArtifactId())
+	assert.Equal(t, "6.3.0-SNAPSHOT", doc.ParentVersion())
+	// An existing relativePath is cleared, but other elements are not touched
+	assert.Contains(t, string(doc.Bytes()), "<relativePath></relativePath>")
+	assert.Contains(t, string(doc.Bytes()), "<parent>do-not-touch</parent>")
+}
+
+func TestDocumentSetProperty(t *testing.T) {
+	doc, err := ParseDocument([]byte(testPom))
+	require.NoError(t, err)
+
+	_, err = doc.SetProperty("forwarder.version", "6.3.0-SNAPSHOT")
+	require.NoError(t, err)
+	_, err = doc.SetProperty("empty.value", "set")
+	require.NoError(t, err)
+	_, err = doc.SetProperty("new.property", "a&b")
+	require.NoError(t, err)
+
+	assert.Contains(t, string(doc.Bytes()), `    <!-- Keep in sync -->
+    <forwarder.version>6.3.0-SNAPSHOT</forwarder.version>
+    <empty.value>set</empty.value>
+    <new.property>a&amp;b</new.property>
+  </properties>`)
+	assert.Equal(t, "a&b", doc.Properties()["new.property"])
+}
+
+func TestDocumentAddPropertiesElement(t *testing.T) {
+	doc, err := ParseDocument([]byte(`<project>
+    <artifactId>test</artifactId>
+    <version>1.0.0</version>
+</project>`))
+	require.NoError(t, err)
+
+	_, err = doc.SetProperty("graylog.version", "6.2.0")
+	require.NoError(t, err)
+
+	assert.Equal(t, `<project>
+    <artifactId>test</artifactId>
+    <version>1.0.0</version>
+
+    <properties>
+        <graylog.version>6.2.0</graylog.version>
+    </properties>
+</project>`, string(doc.Bytes()))
+}
+
+func TestDocumentSetDependencyVersion(t *testing.T) {
+	doc, err := ParseDocument([]byte(testPom))
+	require.NoError(t, err)
+
+	// Property references update the property
+	changed, missing, err := doc.SetDependencyVersion("org.graylog2", "graylog2-server", "6.2.0")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Empty(t, missing)
+	assert.Equal(t, "6.2.0", doc.Properties()["graylog.version"])
+
+	// The ${project.groupId} placeholder is resolved
+	changed, _, err = doc.SetDependencyVersion("org.graylog.plugins", "graylog-plugin-forwarder", "6.2.0")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Contains(t, string(doc.Bytes()), "<artifactId>graylog-plugin-forwarder</artifactId>\n      <version>6.2.0</version>")
+
+	changed, _, err = doc.SetDependencyVersion("org.graylog", "unknown", "6.2.0")
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestDocumentInvalid(t *testing.T) {
+	_, err := ParseDocument([]byte("<project><version></project>"))
+	assert.Error(t, err)
+
+	_, err = ParseDocument([]byte("<settings></settings>"))
+	assert.Error(t, err)
+}
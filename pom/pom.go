@@ -1,9 +1,9 @@
 package pom
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	c "github.com/Graylog2/graylog-project-cli/config"
@@ -14,42 +14,63 @@ import (
 	"github.com/Graylog2/graylog-project-cli/xmltemplate"
 )
 
+// SetProperty updates the given property in the pom.xml of the module. Missing properties are not added and
+// properties that reference another property are not modified.
 func SetProperty(module p.Module, name string, value string) {
+	if err := SetPropertyE(module, name, value); err != nil {
+		logger.Fatal(err.Error())
+	}
+}
+
+// SetPropertyE works like SetProperty but returns an error instead of exiting.
+func SetPropertyE(module p.Module, name string, value string) error {
+	return setProperty(module, name, value, false)
+}
+
+// AddProperty sets the given property in the pom.xml of the module and adds it if it doesn't exist.
+func AddProperty(module p.Module, name string, value string) {
+	if err := setProperty(module, name, value, true); err != nil {
+		logger.Fatal(err.Error())
+	}
+}
+
+func setProperty(module p.Module, name string, value string, add bool) error {
 	pomFile := filepath.Join(module.Path, "pom.xml")
-	properties := pomparse.ParsePom(pomFile).PropertiesMap()
+	doc, err := LoadDocument(pomFile)
+	if err != nil {
+		return fmt.Errorf("unable to load pom file: %w", err)
+	}
 
-	prevValue, hasName := properties[name]
+	prevValue, hasName := doc.Properties()[name]
 
 	if hasName && prevValue == value {
 		logger.Debug("Not updating property %v in %v, value does not change", name, module.Name)
-		return
+		return nil
 	}
 
 	if strings.HasPrefix(prevValue, "${") {
 		logger.Debug("Not updatig property %v in %v, existing value is a variable: %v", name, module.Name, prevValue)
-		return
+		return nil
 	}
 
-	buf, err := os.ReadFile(pomFile)
-	if err != nil {
-		logger.Fatal("Unable to read %v: %v", pomFile, err)
+	if !hasName && !add {
+		logger.Debug("There is no \"%v\" property in %v that can be set", name, pomFile)
+		return nil
 	}
 
 	if hasName {
 		logger.Info("Updating %s from %v to %v in %v", name, prevValue, value, module.Name)
-
-		// Ensure that newlines are matched (via "(?s)")
-		re := regexp.MustCompile("(?s)<" + regexp.QuoteMeta(name) + ">.+</" + regexp.QuoteMeta(name) + ">")
-
-		newContent := re.ReplaceAllString(string(buf), "<"+name+">"+value+"</"+name+">")
-
-		if err := os.WriteFile(pomFile, []byte(newContent), 0); err != nil {
-			logger.Fatal("Unable to set version in %v: %v", pomFile, err)
-		}
 	} else {
-		logger.Debug("There is no \"%v\" property in %v that can be set and adding new properties is currently not supported :-(", name, pomFile)
+		logger.Info("Adding %s with value %v in %v", name, value, module.Name)
 	}
 
+	if _, err := doc.SetProperty(name, value); err != nil {
+		return fmt.Errorf("unable to set property %v in %v: %w", name, pomFile, err)
+	}
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("unable to set property %v in %v: %w", name, pomFile, err)
+	}
+	return nil
 }
 
 func SetParent(module p.Module, groupId string, artifactId string, version string, relativePath string) {
@@ -59,33 +80,42 @@ func SetParent(module p.Module, groupId string, artifactId string, version strin
 }
 
 func SetParentIfMatches(module p.Module, groupId string, artifactId string, version string, relativePath string, ifMatches func(module p.Module, pom pomparse.MavenPom) bool) {
+	if err := SetParentIfMatchesE(module, groupId, artifactId, version, relativePath, ifMatches); err != nil {
+		logger.Fatal(err.Error())
+	}
+}
+
+// SetParentIfMatchesE works like SetParentIfMatches but returns an error instead of exiting.
+func SetParentIfMatchesE(module p.Module, groupId string, artifactId string, version string, relativePath string, ifMatches func(module p.Module, pom pomparse.MavenPom) bool) error {
 	if groupId == "" || artifactId == "" || version == "" {
-		logger.Fatal("One of groupId, artifactId or version is empty: groupId=%s artifactId=%s version=%s", groupId, artifactId, version)
+		return fmt.Errorf("one of groupId, artifactId or version is empty: groupId=%s artifactId=%s version=%s", groupId, artifactId, version)
 	}
 
 	pomFile := filepath.Join(module.Path, "pom.xml")
-	pom := pomparse.ParsePom(pomFile)
+	pom, err := pomparse.ParsePomE(pomFile)
+	if err != nil {
+		return err
+	}
 
-	if !ifMatches(module, pom) {
+	if !ifMatches(module, *pom) {
 		logger.Debug("Skip setting parent in %s because condition function was false", pomFile)
-		return
+		return nil
 	}
 
-	buf, err := os.ReadFile(pomFile)
+	doc, err := LoadDocument(pomFile)
 	if err != nil {
-		logger.Fatal("Unable to read %v: %v", pomFile, err)
+		return fmt.Errorf("unable to load pom file: %w", err)
 	}
 
 	logger.Debug("Setting parent to %s:%s:%s:%s in %v", groupId, artifactId, version, relativePath, module.Name)
 
-	// Ensure that newlines are matched (via "(?s)")
-	re := regexp.MustCompile("(?s)<parent>.+</parent>")
-
-	newContent := re.ReplaceAllString(string(buf), "<parent>\n        <groupId>"+groupId+"</groupId>\n        <artifactId>"+artifactId+"</artifactId>\n        <version>"+version+"</version>\n        <relativePath>"+relativePath+"</relativePath>\n    </parent>")
-
-	if err := os.WriteFile(pomFile, []byte(newContent), 0); err != nil {
-		logger.Fatal("Unable to set version in %v: %v", pomFile, err)
+	if _, err := doc.SetParent(groupId, artifactId, version, relativePath); err != nil {
+		return fmt.Errorf("unable to set parent in %v: %w", pomFile, err)
+	}
+	if err := doc.Save(); err != nil {
+		return fmt.Errorf("unable to set parent in %v: %w", pomFile, err)
 	}
+	return nil
 }
 
 var templateFileSuffixes = map[string]string{
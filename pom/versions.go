@@ -0,0 +1,196 @@
+package pom
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/utils"
+)
+
+// Loads all pom.xml files of the module in the given path, including the files of all submodules. Submodules
+// that are only listed in a profile are included as well, because the release builds activate profiles. (e.g.,
+// "-Prelease")
+func loadModuleDocuments(path string) ([]*Document, error) {
+	docs := make([]*Document, 0)
+	if err := loadDocumentTree(path, &docs, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("couldn't find pom.xml in %s", path)
+	}
+	return docs, nil
+}
+
+func loadDocumentTree(path string, docs *[]*Document, seen map[string]bool) error {
+	filename := filepath.Join(path, "pom.xml")
+	if strings.HasSuffix(path, ".xml") {
+		// Modules can reference a pom file instead of a directory
+		filename = path
+	}
+	if seen[filepath.Clean(filename)] || !utils.FileExists(filename) {
+		return nil
+	}
+	seen[filepath.Clean(filename)] = true
+
+	doc, err := LoadDocument(filename)
+	if err != nil {
+		return err
+	}
+	*docs = append(*docs, doc)
+
+	for _, module := range doc.Modules() {
+		if err := loadDocumentTree(filepath.Join(filepath.Dir(filename), module), docs, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the filenames of the changed documents.
+func changedFiles(docs []*Document) []string {
+	files := make([]string, 0)
+	for _, doc := range docs {
+		if doc.Changed() {
+			files = append(files, doc.Filename())
+		}
+	}
+	return files
+}
+
+func saveDocuments(docs []*Document) error {
+	for _, doc := range docs {
+		if !doc.Changed() {
+			continue
+		}
+		logger.Debug("Updating %s", doc.Filename())
+		if err := doc.Save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetVersion sets the version of the module in the given path to the new version. Like the "versions:set" goal of
+// the versions-maven-plugin, it updates all projects of the reactor that have the same version as the root project.
+// Parent and dependency references to these projects are updated as well. Returns the modified pom.xml files.
+func SetVersion(path string, newVersion string) ([]string, error) {
+	docs, oldVersion, err := setVersion(path, newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Set version %s -> %s in %s", oldVersion, newVersion, path)
+
+	return changedFiles(docs), saveDocuments(docs)
+}
+
+// SetVersionFiles returns the pom.xml files that SetVersion would modify without changing them.
+func SetVersionFiles(path string, newVersion string) ([]string, error) {
+	docs, _, err := setVersion(path, newVersion)
+	if err != nil {
+		return nil, err
+	}
+	return changedFiles(docs), nil
+}
+
+func setVersion(path string, newVersion string) ([]*Document, string, error) {
+	docs, err := loadModuleDocuments(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	oldVersion := docs[0].Version()
+	if oldVersion == "" {
+		return nil, "", fmt.Errorf("couldn't find project version in %s", filepath.Join(path, "pom.xml"))
+	}
+
+	// All reactor projects that get the new version
+	reactor := make(map[string]bool)
+	for _, doc := range docs {
+		if doc.Version() == oldVersion {
+			reactor[doc.GroupId()+":"+doc.ArtifactId()] = true
+		}
+	}
+
+	for _, doc := range docs {
+		if reactor[doc.GroupId()+":"+doc.ArtifactId()] {
+			if _, err := doc.SetVersion(newVersion); err != nil {
+				return nil, "", fmt.Errorf("couldn't set version in %s: %w", doc.Filename(), err)
+			}
+		}
+		if doc.HasParent() && doc.ParentVersion() == oldVersion && reactor[doc.ParentGroupId()+":"+doc.ParentArtifactId()] {
+			if _, err := doc.SetParentVersion(newVersion); err != nil {
+				return nil, "", fmt.Errorf("couldn't set parent version in %s: %w", doc.Filename(), err)
+			}
+		}
+		for key := range reactor {
+			groupId, artifactId, _ := strings.Cut(key, ":")
+			if _, _, err := doc.setDependencyVersion(groupId, artifactId, oldVersion, newVersion); err != nil {
+				return nil, "", fmt.Errorf("couldn't set dependency version in %s: %w", doc.Filename(), err)
+			}
+		}
+	}
+
+	return docs, oldVersion, nil
+}
+
+// SetDependencyVersion sets the version of the given dependency in all pom.xml files of the module in the given path.
+// Like the "versions:use-dep-version" goal of the versions-maven-plugin, properties that are used as dependency
+// version are updated instead of the dependency itself. Returns the modified pom.xml files.
+func SetDependencyVersion(path string, groupId string, artifactId string, version string) ([]string, error) {
+	docs, err := setDependencyVersion(path, groupId, artifactId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Set dependency version %s:%s -> %s in %s", groupId, artifactId, version, path)
+
+	return changedFiles(docs), saveDocuments(docs)
+}
+
+// SetDependencyVersionFiles returns the pom.xml files that SetDependencyVersion would modify without changing them.
+func SetDependencyVersionFiles(path string, groupId string, artifactId string, version string) ([]string, error) {
+	docs, err := setDependencyVersion(path, groupId, artifactId, version)
+	if err != nil {
+		return nil, err
+	}
+	return changedFiles(docs), nil
+}
+
+func setDependencyVersion(path string, groupId string, artifactId string, version string) ([]*Document, error) {
+	docs, err := loadModuleDocuments(path)
+	if err != nil {
+		return nil, err
+	}
+
+	missingProperties := make(map[string]bool)
+	for _, doc := range docs {
+		_, missing, err := doc.SetDependencyVersion(groupId, artifactId, version)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't set dependency version in %s: %w", doc.Filename(), err)
+		}
+		for _, property := range missing {
+			missingProperties[property] = true
+		}
+	}
+
+	// Properties might be defined in other pom.xml files of the module, e.g., the parent
+	for property := range missingProperties {
+		found := false
+		for _, doc := range docs {
+			if doc.HasProperty(property) {
+				found = true
+				if _, err := doc.SetProperty(property, version); err != nil {
+					return nil, fmt.Errorf("couldn't set property %s in %s: %w", property, doc.Filename(), err)
+				}
+			}
+		}
+		if !found {
+			logger.Info("Couldn't find property %s for dependency %s:%s in %s", property, groupId, artifactId, path)
+		}
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,194 @@
+package pom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestPom(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(path, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pom.xml"), []byte(content), 0o644))
+}
+
+func readTestPom(t *testing.T, path string) *Document {
+	t.Helper()
+	doc, err := LoadDocument(filepath.Join(path, "pom.xml"))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestSetVersion(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestPom(t, root, `<project>
+  <parent>
+    <groupId>org.graylog</groupId>
+    <artifactId>graylog-parent</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+  </parent>
+  <groupId>org.graylog2</groupId>
+  <artifactId>graylog-project</artifactId>
+  <version>6.2.0-SNAPSHOT</version>
+  <modules>
+    <module>server</module>
+    <module>storage</module>
+  </modules>
+</project>
+`)
+	writeTestPom(t, filepath.Join(root, "server"), `<project>
+  <parent>
+    <groupId>org.graylog2</groupId>
+    <artifactId>graylog-project</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>graylog2-server</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>org.graylog2</groupId>
+      <artifactId>graylog-storage</artifactId>
+      <version>6.2.0-SNAPSHOT</version>
+    </dependency>
+    <dependency>
+      <groupId>org.graylog2</groupId>
+      <artifactId>other</artifactId>
+      <version>6.2.0-SNAPSHOT</version>
+    </dependency>
+  </dependencies>
+</project>
+`)
+	writeTestPom(t, filepath.Join(root, "storage"), `<project>
+  <parent>
+    <groupId>org.graylog2</groupId>
+    <artifactId>graylog-project</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>graylog-storage</artifactId>
+  <version>6.2.0-SNAPSHOT</version>
+</project>
+`)
+
+	files, err := SetVersionFiles(root, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "pom.xml"),
+		filepath.Join(root, "server", "pom.xml"),
+		filepath.Join(root, "storage", "pom.xml"),
+	}, files)
+	assert.Equal(t, "6.2.0-SNAPSHOT", readTestPom(t, root).Version())
+
+	changed, err := SetVersion(root, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, files, changed)
+
+	rootDoc := readTestPom(t, root)
+	assert.Equal(t, "6.2.0", rootDoc.Version())
+	// The external parent is not part of the reactor
+	assert.Equal(t, "6.2.0-SNAPSHOT", rootDoc.ParentVersion())
+
+	serverDoc := readTestPom(t, filepath.Join(root, "server"))
+	assert.Equal(t, "6.2.0", serverDoc.Version())
+	assert.False(t, serverDoc.HasVersion())
+	assert.Contains(t, string(serverDoc.Bytes()), "<artifactId>graylog-storage</artifactId>\n      <version>6.2.0</version>")
+	assert.Contains(t, string(serverDoc.Bytes()), "<artifactId>other</artifactId>\n      <version>6.2.0-SNAPSHOT</version>")
+
+	storageDoc := readTestPom(t, filepath.Join(root, "storage"))
+	assert.Equal(t, "6.2.0", storageDoc.Version())
+	assert.Equal(t, "6.2.0", storageDoc.ParentVersion())
+}
+
+func TestSetVersionProfileModules(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestPom(t, root, `<project>
+  <groupId>org.graylog</groupId>
+  <artifactId>graylog-plugin-enterprise-parent</artifactId>
+  <version>6.2.0-SNAPSHOT</version>
+  <modules>
+    <module>enterprise</module>
+  </modules>
+  <profiles>
+    <profile>
+      <id>release</id>
+      <modules>
+        <module>enterprise</module>
+        <module>release-assembly</module>
+      </modules>
+    </profile>
+  </profiles>
+</project>
+`)
+	for _, module := range []string{"enterprise", "release-assembly"} {
+		writeTestPom(t, filepath.Join(root, module), `<project>
+  <parent>
+    <groupId>org.graylog</groupId>
+    <artifactId>graylog-plugin-enterprise-parent</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>`+module+`</artifactId>
+</project>
+`)
+	}
+
+	assert.Equal(t, []string{"enterprise", "release-assembly"}, readTestPom(t, root).Modules())
+
+	files, err := SetVersionFiles(root, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "pom.xml"),
+		filepath.Join(root, "enterprise", "pom.xml"),
+		filepath.Join(root, "release-assembly", "pom.xml"),
+	}, files)
+
+	_, err = SetVersion(root, "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0", readTestPom(t, filepath.Join(root, "release-assembly")).ParentVersion())
+}
+
+func TestSetDependencyVersion(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestPom(t, root, `<project>
+  <groupId>org.graylog.plugins</groupId>
+  <artifactId>graylog-plugin-enterprise-parent</artifactId>
+  <version>6.2.0-SNAPSHOT</version>
+  <modules>
+    <module>enterprise</module>
+  </modules>
+  <properties>
+    <forwarder.version>6.1.0</forwarder.version>
+  </properties>
+</project>
+`)
+	writeTestPom(t, filepath.Join(root, "enterprise"), `<project>
+  <parent>
+    <groupId>org.graylog.plugins</groupId>
+    <artifactId>graylog-plugin-enterprise-parent</artifactId>
+    <version>6.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>graylog-plugin-enterprise</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>org.graylog.plugins</groupId>
+      <artifactId>graylog-plugin-forwarder</artifactId>
+      <version>${forwarder.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`)
+
+	files, err := SetDependencyVersionFiles(root, "org.graylog.plugins", "graylog-plugin-forwarder", "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "pom.xml")}, files)
+
+	changed, err := SetDependencyVersion(root, "org.graylog.plugins", "graylog-plugin-forwarder", "6.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, files, changed)
+
+	assert.Equal(t, "6.2.0", readTestPom(t, root).Properties()["forwarder.version"])
+	assert.Contains(t, string(readTestPom(t, filepath.Join(root, "enterprise")).Bytes()), "<version>${forwarder.version}</version>")
+}
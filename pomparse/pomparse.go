@@ -23,6 +23,7 @@ type MavenPom struct {
 	GroupId              string            `xml:"groupId"`
 	ArtifactId           string            `xml:"artifactId"`
 	Version              string            `xml:"version"`
+	Name                 string            `xml:"name"`
 	ParentGroupId        string            `xml:"parent>groupId"`
 	ParentArtifactId     string            `xml:"parent>artifactId"`
 	ParentVersion        string            `xml:"parent>version"`
@@ -126,11 +127,21 @@ func ParsePomE(filename string) (*MavenPom, error) {
 		return nil, fmt.Errorf("couldn't read %q: %w", filename, err)
 	}
 
-	var mavenPom MavenPom
-	if err := xml.Unmarshal(pomBytes, &mavenPom); err != nil {
+	mavenPom, err := ParsePomContentE(pomBytes)
+	if err != nil {
 		return nil, fmt.Errorf("couldn't parse %q: %w", filename, err)
 	}
 
+	return mavenPom, nil
+}
+
+// ParsePomContentE parses the given pom.xml content. Useful for pom files that are not in the working tree.
+func ParsePomContentE(content []byte) (*MavenPom, error) {
+	var mavenPom MavenPom
+	if err := xml.Unmarshal(content, &mavenPom); err != nil {
+		return nil, err
+	}
+
 	return &mavenPom, nil
 }
 
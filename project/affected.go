@@ -0,0 +1,140 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Graylog2/graylog-project-cli/git"
+	"github.com/Graylog2/graylog-project-cli/logger"
+	"github.com/Graylog2/graylog-project-cli/pomparse"
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/samber/lo"
+)
+
+// AffectedModules returns the modules that have commits or uncommitted changes relative to the given ref, plus all
+// modules that transitively depend on them via Maven dependencies or parents. If the ref is empty, the manifest
+// base revision of each module is used. (preferring the remote branch)
+func AffectedModules(project Project, since string) ([]Module, error) {
+	changed := make(map[string]bool)
+
+	for _, module := range project.Modules {
+		if !utils.FileExists(module.Path) {
+			logger.Debug("Skipping affected check for module %s because it does not exist yet", module.Name)
+			continue
+		}
+
+		isChanged, err := moduleHasChanges(module, since)
+		if err != nil {
+			return nil, err
+		}
+		if isChanged {
+			changed[module.Name] = true
+		}
+	}
+
+	affected := resolveDependents(moduleDependencyGraph(project), changed)
+
+	return lo.Filter(project.Modules, func(module Module, _ int) bool {
+		return affected[module.Name]
+	}), nil
+}
+
+func moduleHasChanges(module Module, since string) (bool, error) {
+	refs := []string{since}
+	if since == "" {
+		refs = []string{"origin/" + module.BaseRevision, module.BaseRevision}
+	}
+
+	_, ref, err := git.ResolveRef(module.Path, refs...)
+	if err != nil {
+		// Without a base we can't tell what changed, so we consider the module as changed to be on the safe side.
+		logger.Info("Considering module %s as changed: %s", module.Name, err)
+		return true, nil
+	}
+
+	commits, err := git.CommitsSince(module.Path, ref)
+	if err != nil {
+		return false, err
+	}
+	if commits > 0 {
+		logger.Debug("Module %s has %d commit(s) since %s", module.Name, commits, ref)
+		return true, nil
+	}
+
+	uncommitted, err := git.HasUncommittedChanges(module.Path)
+	if err != nil {
+		return false, err
+	}
+	if uncommitted {
+		logger.Debug("Module %s has uncommitted changes", module.Name)
+	}
+
+	return uncommitted, nil
+}
+
+// Returns a map of module names to the names of the modules that depend on them.
+func moduleDependencyGraph(project Project) map[string][]string {
+	artifactModules := make(map[string]string)
+	moduleDependencies := make(map[string][]string)
+
+	for _, module := range project.Modules {
+		if !module.IsMavenModule() {
+			continue
+		}
+
+		for _, pomFile := range module.PomFiles(false) {
+			pom, err := pomparse.ParsePomE(pomFile)
+			if err != nil {
+				logger.Error("Skipping dependency information of %s: %s", module.Name, err)
+				continue
+			}
+
+			groupId, _ := utils.FirstNonEmpty(pom.GroupId, pom.ParentGroupId)
+			artifactModules[mavenKey(groupId, pom.ArtifactId)] = module.Name
+
+			if pom.ParentArtifactId != "" {
+				moduleDependencies[module.Name] = append(moduleDependencies[module.Name], mavenKey(pom.ParentGroupId, pom.ParentArtifactId))
+			}
+			for _, dep := range pom.Dependencies {
+				moduleDependencies[module.Name] = append(moduleDependencies[module.Name], mavenKey(dep.GroupId, dep.ArtifactId))
+			}
+		}
+	}
+
+	dependents := make(map[string][]string)
+	for moduleName, dependencies := range moduleDependencies {
+		for _, dependency := range dependencies {
+			dependencyModule, ok := artifactModules[dependency]
+			if !ok || dependencyModule == moduleName || slices.Contains(dependents[dependencyModule], moduleName) {
+				continue
+			}
+			dependents[dependencyModule] = append(dependents[dependencyModule], moduleName)
+		}
+	}
+
+	return dependents
+}
+
+// Returns the given changed modules plus all modules that transitively depend on them.
+func resolveDependents(dependents map[string][]string, changed map[string]bool) map[string]bool {
+	affected := make(map[string]bool)
+	queue := lo.Keys(changed)
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		if affected[name] {
+			continue
+		}
+		affected[name] = true
+		queue = append(queue, dependents[name]...)
+	}
+
+	return affected
+}
+
+func mavenKey(groupId string, artifactId string) string {
+	return fmt.Sprintf("%s:%s", strings.TrimSpace(groupId), strings.TrimSpace(artifactId))
+}
@@ -0,0 +1,62 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestPom(t *testing.T, dir string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pom.xml"), []byte(content), 0644))
+}
+
+func TestModuleDependencyGraph(t *testing.T) {
+	root := t.TempDir()
+
+	writeTestPom(t, filepath.Join(root, "server"), `<project>
+  <groupId>org.graylog</groupId><artifactId>server-parent</artifactId><version>1.0.0</version>
+  <modules><module>plugin-parent</module></modules>
+</project>`)
+	writeTestPom(t, filepath.Join(root, "server", "plugin-parent"), `<project>
+  <parent><groupId>org.graylog</groupId><artifactId>server-parent</artifactId><version>1.0.0</version></parent>
+  <groupId>org.graylog.plugins</groupId><artifactId>graylog-plugin-parent</artifactId>
+</project>`)
+	writeTestPom(t, filepath.Join(root, "enterprise"), `<project>
+  <parent><groupId>org.graylog.plugins</groupId><artifactId>graylog-plugin-parent</artifactId><version>1.0.0</version></parent>
+  <artifactId>enterprise</artifactId>
+</project>`)
+	writeTestPom(t, filepath.Join(root, "integrations"), `<project>
+  <groupId>org.graylog</groupId><artifactId>integrations</artifactId><version>1.0.0</version>
+  <dependencies>
+    <dependency><groupId>org.graylog.plugins</groupId><artifactId>enterprise</artifactId></dependency>
+  </dependencies>
+</project>`)
+	writeTestPom(t, filepath.Join(root, "standalone"), `<project>
+  <groupId>org.example</groupId><artifactId>standalone</artifactId><version>1.0.0</version>
+</project>`)
+
+	project := Project{Modules: []Module{
+		{Name: "server", Path: filepath.Join(root, "server")},
+		{Name: "enterprise", Path: filepath.Join(root, "enterprise")},
+		{Name: "integrations", Path: filepath.Join(root, "integrations")},
+		{Name: "standalone", Path: filepath.Join(root, "standalone")},
+	}}
+
+	graph := moduleDependencyGraph(project)
+
+	assert.Equal(t, []string{"enterprise"}, graph["server"])
+	assert.Equal(t, []string{"integrations"}, graph["enterprise"])
+	assert.Empty(t, graph["standalone"])
+
+	assert.Equal(t, map[string]bool{"server": true, "enterprise": true, "integrations": true},
+		resolveDependents(graph, map[string]bool{"server": true}))
+	assert.Equal(t, map[string]bool{"enterprise": true, "integrations": true},
+		resolveDependents(graph, map[string]bool{"enterprise": true}))
+	assert.Equal(t, map[string]bool{"standalone": true},
+		resolveDependents(graph, map[string]bool{"standalone": true}))
+}
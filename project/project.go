@@ -22,6 +22,8 @@ type Project struct {
 	Modules           []Module
 	AssemblyPlatforms []string
 	JVMVersion        int
+	// Names of the affected modules if the affected module selection is enabled, nil otherwise.
+	affected map[string]bool
 }
 
 type Apply struct {
@@ -31,7 +33,9 @@ type Apply struct {
 }
 
 type Module struct {
-	Name               string
+	Name string
+	// The human-readable module name for changelogs. (from the manifest, see Title)
+	DisplayName        string
 	Path               string
 	Repository         string
 	Revision           string
@@ -44,6 +48,8 @@ type Module struct {
 	apply              Apply
 	ApplyExecute       bool
 	SkipRelease        bool
+	// The package.json files that contain the module version, relative to the module path. (from the manifest)
+	PackageJsonFiles []string
 }
 
 func (module *Module) IsMavenModule() bool {
@@ -54,6 +60,37 @@ func (module *Module) IsNpmModule() bool {
 	return utils.FileExists(filepath.Join(module.Path, "package.json"))
 }
 
+// Used for server modules if the manifest doesn't list the package.json files, to support older manifests.
+var legacyServerPackageJsonFiles = []string{
+	"graylog2-web-interface/package.json",
+	"graylog2-web-interface/manifests/package.json",
+	"graylog2-web-interface/packages/graylog-web-plugin/package.json",
+}
+
+// VersionPackageJsonFiles returns the package.json files that contain the module version, relative to the module
+// path. Without a "package_json_files" manifest entry, server modules return the web interface files and other modules
+// return the default "package.json" file if it exists.
+func (module *Module) VersionPackageJsonFiles() []string {
+	if len(module.PackageJsonFiles) > 0 {
+		return module.PackageJsonFiles
+	}
+	if module.Server {
+		return legacyServerPackageJsonFiles
+	}
+	if module.IsNpmModule() {
+		return []string{"package.json"}
+	}
+	return nil
+}
+
+// Title returns the display name of the module or the module name if the manifest doesn't set a display name.
+func (module *Module) Title() string {
+	if module.DisplayName != "" {
+		return module.DisplayName
+	}
+	return module.Name
+}
+
 func (module *Module) HasSubmodules() bool {
 	return len(module.Submodules) > 0
 }
@@ -215,12 +252,14 @@ func New(config config.Config, manifestFiles []string, options ...projectOption)
 
 				submodules = append(submodules, Module{
 					Name:               name,
+					DisplayName:        submodule.DisplayName,
 					Path:               path,
 					Repository:         moduleRepository,
 					Revision:           module.Revision,
 					Assemblies:         submodule.Assemblies,
 					AssemblyAttachment: submodule.AssemblyAttachment,
 					SkipRelease:        submodule.SkipRelease,
+					PackageJsonFiles:   submodule.PackageJsonFiles,
 					apply:              submoduleApply,
 				})
 			}
@@ -235,6 +274,7 @@ func New(config config.Config, manifestFiles []string, options ...projectOption)
 
 		newModule := Module{
 			Name:               name,
+			DisplayName:        module.DisplayName,
 			Path:               path,
 			Repository:         moduleRepository,
 			Revision:           module.Revision,
@@ -244,6 +284,7 @@ func New(config config.Config, manifestFiles []string, options ...projectOption)
 			SkipRelease:        module.SkipRelease,
 			Server:             module.Server,
 			Submodules:         submodules,
+			PackageJsonFiles:   module.PackageJsonFiles,
 			apply:              moduleApply,
 		}
 
@@ -284,6 +325,20 @@ func New(config config.Config, manifestFiles []string, options ...projectOption)
 		JVMVersion:        readManifest.JVMVersion,
 	}
 
+	if config.Affected {
+		affectedModules, err := AffectedModules(project, config.AffectedSince)
+		if err != nil {
+			logger.Fatal("Couldn't detect affected modules: %s", err)
+		}
+		project.affected = make(map[string]bool)
+		for _, module := range affectedModules {
+			project.affected[module.Name] = true
+		}
+		logger.Info("Affected modules: %v", lo.Map(affectedModules, func(module Module, _ int) string {
+			return module.Name
+		}))
+	}
+
 	return project
 }
 
@@ -464,7 +519,21 @@ func ForEachSelectedModuleAndSubmodules(project Project, callback func(Module))
 	forEachModuleAndSubmodules(SelectedModules(project), callback)
 }
 
+// SelectedModules returns the modules that match the module and assembly selection. If the affected module
+// selection is enabled, only affected modules are returned.
 func SelectedModules(project Project) []Module {
+	modules := selectedModulesByNameOrAssembly(project)
+
+	if project.affected != nil {
+		modules = lo.Filter(modules, func(module Module, _ int) bool {
+			return project.affected[module.Name]
+		})
+	}
+
+	return modules
+}
+
+func selectedModulesByNameOrAssembly(project Project) []Module {
 	var selectedModules []Module
 
 	if project.config.SelectedModules == "" && project.config.SelectedAssemblies == "" {
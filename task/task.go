@@ -0,0 +1,155 @@
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the task definition file location relative to the graylog-project repository root.
+var DefaultFile = filepath.Join(".config", "tasks.yml")
+
+const (
+	ModuleTypeMaven = "maven"
+	ModuleTypeNpm   = "npm"
+)
+
+type Config struct {
+	Tasks map[string]Task `yaml:"tasks"`
+}
+
+type Task struct {
+	Name        string `yaml:"-"`
+	Description string `yaml:"description"`
+	Command     string `yaml:"command"`
+	Maven       string `yaml:"maven"`
+	Npm         string `yaml:"npm"`
+	Force       bool   `yaml:"force"`
+	Filter      Filter `yaml:"filter"`
+}
+
+// Filter selects the modules a task should run in. Empty fields don't restrict the selection.
+type Filter struct {
+	// Module name substrings, like the --selected-modules flag.
+	Modules []string `yaml:"modules"`
+	// Module assemblies, like the --selected-assemblies flag. Use a "-" prefix to exclude an assembly.
+	Assemblies []string `yaml:"assemblies"`
+	// Module type, either "maven" or "npm". Web tasks are also executed in submodules.
+	Type string `yaml:"type"`
+}
+
+func ReadConfig(filename string) (*Config, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read task file %q: %w", filename, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(buf, &config); err != nil {
+		return nil, fmt.Errorf("couldn't parse task file %q: %w", filename, err)
+	}
+
+	for name, task := range config.Tasks {
+		task.Name = name
+		if err := task.validate(); err != nil {
+			return nil, fmt.Errorf("invalid task %q in %q: %w", name, filename, err)
+		}
+		config.Tasks[name] = task
+	}
+
+	return &config, nil
+}
+
+// Names returns the sorted task names.
+func (c *Config) Names() []string {
+	names := lo.Keys(c.Tasks)
+	slices.Sort(names)
+	return names
+}
+
+func (c *Config) Get(name string) (Task, error) {
+	task, ok := c.Tasks[name]
+	if !ok {
+		return Task{}, fmt.Errorf("couldn't find task %q (available: %s)", name, strings.Join(c.Names(), ", "))
+	}
+	return task, nil
+}
+
+func (t Task) validate() error {
+	if strings.TrimSpace(t.Command) == "" && strings.TrimSpace(t.Maven) == "" && strings.TrimSpace(t.Npm) == "" {
+		return fmt.Errorf("at least one of command, maven or npm must be set")
+	}
+	if t.Filter.Type != "" && t.Filter.Type != ModuleTypeMaven && t.Filter.Type != ModuleTypeNpm {
+		return fmt.Errorf("invalid filter type %q (valid: %s, %s)", t.Filter.Type, ModuleTypeMaven, ModuleTypeNpm)
+	}
+	return nil
+}
+
+// IsWeb returns true if the task should be executed in web (sub)modules.
+func (t Task) IsWeb() bool {
+	return t.Filter.Type == ModuleTypeNpm
+}
+
+// Matches checks if the task filter selects the given module.
+func (t Task) Matches(module p.Module) bool {
+	filter := t.Filter
+
+	if len(filter.Modules) > 0 && !lo.SomeBy(filter.Modules, func(name string) bool {
+		return strings.Contains(module.Name, name)
+	}) {
+		return false
+	}
+
+	if len(filter.Assemblies) > 0 {
+		moduleAssemblies := lo.Union(module.Assemblies, lo.FlatMap(module.Submodules, func(submodule p.Module, _ int) []string {
+			return submodule.Assemblies
+		}))
+		included := make([]string, 0)
+		for _, assembly := range filter.Assemblies {
+			if after, ok := strings.CutPrefix(assembly, "-"); ok {
+				if lo.Contains(moduleAssemblies, after) {
+					return false
+				}
+			} else {
+				included = append(included, assembly)
+			}
+		}
+		if len(included) > 0 && len(lo.Intersect(included, moduleAssemblies)) == 0 {
+			return false
+		}
+	}
+
+	switch filter.Type {
+	case ModuleTypeMaven:
+		return module.IsMavenModule()
+	case ModuleTypeNpm:
+		return module.IsNpmModule()
+	}
+
+	return true
+}
+
+// CommandFor returns the command that should be executed for the given module. The module type variants are
+// preferred over the generic command. For modules that are both, Maven and npm modules, the variant of the filter
+// type wins. An empty string is returned if the task has no command for the module.
+func (t Task) CommandFor(module p.Module) string {
+	switch {
+	case t.Filter.Type == ModuleTypeMaven && t.Maven != "" && module.IsMavenModule():
+		return t.Maven
+	case t.Filter.Type == ModuleTypeNpm && t.Npm != "" && module.IsNpmModule():
+		return t.Npm
+	}
+	if t.Maven != "" && module.IsMavenModule() {
+		return t.Maven
+	}
+	if t.Npm != "" && module.IsNpmModule() {
+		return t.Npm
+	}
+	return t.Command
+}
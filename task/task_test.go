@@ -0,0 +1,115 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	p "github.com/Graylog2/graylog-project-cli/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTaskFile = `
+tasks:
+  lint:
+    description: Run linter
+    command: "yarn lint"
+    filter:
+      type: npm
+  versions:
+    command: "echo {{ .GPC_MODULE_NAME }}"
+    maven: "mvn -v"
+    filter:
+      modules: ["server", "enterprise"]
+      assemblies: ["server", "-datanode"]
+`
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestReadConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.yml")
+	writeFile(t, filename, testTaskFile)
+
+	config, err := ReadConfig(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"lint", "versions"}, config.Names())
+
+	lint, err := config.Get("lint")
+	require.NoError(t, err)
+	assert.Equal(t, "lint", lint.Name)
+	assert.Equal(t, "Run linter", lint.Description)
+	assert.True(t, lint.IsWeb())
+
+	_, err = config.Get("nope")
+	assert.Error(t, err)
+}
+
+func TestReadConfigValidation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.yml")
+
+	writeFile(t, filename, "tasks:\n  empty:\n    description: nothing\n")
+	_, err := ReadConfig(filename)
+	assert.ErrorContains(t, err, "at least one of command")
+
+	writeFile(t, filename, "tasks:\n  bad:\n    command: ls\n    filter:\n      type: gradle\n")
+	_, err = ReadConfig(filename)
+	assert.ErrorContains(t, err, "invalid filter type")
+}
+
+func TestMatchesAndCommandFor(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "server", "pom.xml"), "<project/>")
+	writeFile(t, filepath.Join(dir, "server", "package.json"), "{}")
+	writeFile(t, filepath.Join(dir, "enterprise", "pom.xml"), "<project/>")
+	writeFile(t, filepath.Join(dir, "datanode", "pom.xml"), "<project/>")
+	writeFile(t, filepath.Join(dir, "web", "package.json"), "{}")
+
+	server := p.Module{Name: "graylog-server", Path: filepath.Join(dir, "server"), Assemblies: []string{"server"}}
+	enterprise := p.Module{Name: "graylog-enterprise", Path: filepath.Join(dir, "enterprise"), Assemblies: []string{"server", "datanode"}}
+	datanode := p.Module{Name: "graylog-datanode", Path: filepath.Join(dir, "datanode"), Assemblies: []string{"datanode"}}
+	web := p.Module{Name: "graylog-web", Path: filepath.Join(dir, "web")}
+
+	task := Task{
+		Command: "generic",
+		Maven:   "maven",
+		Filter:  Filter{Assemblies: []string{"server", "-datanode"}},
+	}
+
+	assert.True(t, task.Matches(server))
+	assert.False(t, task.Matches(enterprise), "excluded assembly should win")
+	assert.False(t, task.Matches(datanode))
+	assert.False(t, task.Matches(web))
+
+	assert.Equal(t, "maven", task.CommandFor(server))
+	assert.Equal(t, "generic", task.CommandFor(web))
+
+	npmTask := Task{Npm: "yarn build", Filter: Filter{Type: ModuleTypeNpm, Modules: []string{"web", "server"}}}
+	assert.True(t, npmTask.Matches(server))
+	assert.True(t, npmTask.Matches(web))
+	assert.False(t, npmTask.Matches(enterprise))
+	assert.Equal(t, "yarn build", npmTask.CommandFor(web))
+	assert.Equal(t, "", npmTask.CommandFor(datanode))
+}
+
+func TestCommandForMixedModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "server", "pom.xml"), "<project/>")
+	writeFile(t, filepath.Join(dir, "server", "package.json"), "{}")
+
+	server := p.Module{Name: "graylog-server", Path: filepath.Join(dir, "server")}
+
+	task := Task{Command: "generic", Maven: "mvn verify", Npm: "yarn test"}
+	assert.Equal(t, "mvn verify", task.CommandFor(server), "maven variant should win without filter type")
+
+	task.Filter.Type = ModuleTypeNpm
+	assert.Equal(t, "yarn test", task.CommandFor(server))
+
+	task.Filter.Type = ModuleTypeMaven
+	assert.Equal(t, "mvn verify", task.CommandFor(server))
+}
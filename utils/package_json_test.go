@@ -0,0 +1,52 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Graylog2/graylog-project-cli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageJsonVersion(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "package.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{
+  "name": "graylog-web-interface",
+  "version": "6.2.0-SNAPSHOT",
+  "dependencies": {
+    "foo": {
+      "version": "1.0.0",
+    }
+  }
+}
+`), 0644))
+
+	version, err := utils.PackageJsonVersion(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0-SNAPSHOT", version)
+
+	require.NoError(t, utils.SetPackageJsonVersion(filename, "6.2.0"))
+	version, err = utils.PackageJsonVersion(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "6.2.0", version)
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), `"version": "1.0.0"`)
+}
+
+func TestPackageJsonVersionMissing(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "package.json")
+	require.NoError(t, os.WriteFile(filename, []byte(`{
+  "name": "graylog-web-interface"
+}
+`), 0644))
+
+	_, err := utils.PackageJsonVersion(filename)
+	assert.ErrorContains(t, err, "couldn't find top-level version field")
+
+	_, err = utils.PackageJsonVersion(filepath.Join(t.TempDir(), "nope.json"))
+	assert.Error(t, err)
+}
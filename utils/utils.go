@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
 func GetCwd() string {
@@ -138,6 +139,19 @@ func FirstNonEmpty(values ...string) (string, error) {
 	return "", errors.New("all values are empty")
 }
 
+// TailString returns the last bytes of the given string up to the given limit. The cut never splits a UTF-8
+// encoded rune, so the result can be shorter than the limit.
+func TailString(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	start := len(value) - limit
+	for start < len(value) && !utf8.RuneStart(value[start]) {
+		start++
+	}
+	return value[start:]
+}
+
 func FileExists(path string) bool {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return false
@@ -184,11 +198,37 @@ func FilesIdentical(file1, file2 string) bool {
 	return bytes.Equal(buf1, buf2)
 }
 
-func SetPackageJsonVersion(filename, version string) error {
-	// Make sure to match the correct version string in package.json
-	re := regexp.MustCompile(`^\s{2}"version": "\d+\.\d+\.\d+-?.*?",`)
+// Make sure to match the correct version string in package.json
+var packageJsonVersionPattern = regexp.MustCompile(`^\s{2}"version": "(\d+\.\d+\.\d+-?.*?)",`)
+
+// PackageJsonVersion returns the version of the given package.json file. It returns an error if the file doesn't
+// contain a version field that can be updated by SetPackageJsonVersion.
+func PackageJsonVersion(filename string) (string, error) {
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+
+	if version, ok := ParsePackageJsonVersion(buf); ok {
+		return version, nil
+	}
+
+	return "", fmt.Errorf("couldn't find top-level version field in %s", filename)
+}
 
-	err := ReplaceInFile(filename, re, fmt.Sprintf(`  "version": "%s",`, version))
+// ParsePackageJsonVersion returns the version of the given package.json content.
+func ParsePackageJsonVersion(content []byte) (string, bool) {
+	for line := range strings.SplitSeq(string(content), "\n") {
+		if match := packageJsonVersionPattern.FindStringSubmatch(line); match != nil {
+			return match[1], true
+		}
+	}
+
+	return "", false
+}
+
+func SetPackageJsonVersion(filename, version string) error {
+	err := ReplaceInFile(filename, packageJsonVersionPattern, fmt.Sprintf(`  "version": "%s",`, version))
 	if err != nil {
 		return err
 	}
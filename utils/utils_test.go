@@ -53,3 +53,21 @@ func TestConvertGithubGitToHTTPS(t *testing.T) {
 		t.Errorf("Repository %s was converted to %s - that should not happen", httpsRepo, toHTTPS)
 	}
 }
+
+func TestTailString(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		limit    int
+		expected string
+	}{
+		{"abc", 10, "abc"},
+		{"abc", 2, "bc"},
+		{"aüx", 3, "üx"},
+		// Doesn't split the two-byte "ü"
+		{"aüx", 2, "x"},
+	} {
+		if actual := utils.TailString(tc.value, tc.limit); actual != tc.expected {
+			t.Errorf("TailString(%q, %d) = %q, expected %q", tc.value, tc.limit, actual, tc.expected)
+		}
+	}
+}